@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/config"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/frontend"
+	"github.com/fromenjn/recipe-manager/internal/handlers"
+	"github.com/fromenjn/recipe-manager/internal/metrics"
+	"github.com/fromenjn/recipe-manager/internal/ratelimit"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// app holds the repository and use cases shared by the HTTP server and the
+// offline subcommands.
+type app struct {
+	cfg      *config.Config
+	repo     repository.RecipeRepository
+	images   repository.ImageStore
+	accounts repository.AccountRepository
+	metrics  *metrics.Metrics
+
+	recipeService domain.RecipeService
+
+	getRecipeUC            usecase.GetRecipeUseCase
+	getAllRecipesUC        usecase.GetAllRecipesUseCase
+	listRecipeSummariesUC  usecase.ListRecipeSummariesUseCase
+	getAllIngredientsUC    usecase.GetAllIngredientsUseCase
+	getRecipeVersionUC     usecase.GetRecipeVersionUseCase
+	getCollectionVersionUC usecase.GetCollectionVersionUseCase
+	saveRecipeUC           usecase.SaveRecipeUseCase
+	resolveRecipeUC        usecase.ResolveRecipeUseCase
+	listRevisionsUC        usecase.ListRevisionsUseCase
+	getRevisionUC          usecase.GetRevisionUseCase
+	diffRevisionsUC        usecase.DiffRevisionsUseCase
+	revertRecipeUC         usecase.RevertRecipeUseCase
+	uploadIllustrationUC   usecase.UploadIllustrationUseCase
+	getImageUC             usecase.GetImageUseCase
+	findImageRecipesUC     usecase.FindImageRecipesUseCase
+	getValidationReportUC  usecase.GetValidationReportUseCase
+	getShoppingListUC      usecase.GetShoppingListUseCase
+	reloadRecipesUC        usecase.ReloadRecipesUseCase
+	authorizeRecipeUC      usecase.AuthorizeRecipeUseCase
+	setRecipeVisibilityUC  usecase.SetRecipeVisibilityUseCase
+	registerUserUC         usecase.RegisterUserUseCase
+	loginUC                usecase.LoginUseCase
+	logoutUC               usecase.LogoutUseCase
+	authenticateSessionUC  usecase.AuthenticateSessionUseCase
+	getProfileUC           usecase.GetProfileUseCase
+	createHouseholdUC      usecase.CreateHouseholdUseCase
+	addHouseholdMemberUC   usecase.AddHouseholdMemberUseCase
+	listFavoritesUC        usecase.ListFavoritesUseCase
+	setFavoriteUC          usecase.SetFavoriteUseCase
+	listCollectionsUC      usecase.ListCollectionsUseCase
+	getCollectionUC        usecase.GetCollectionUseCase
+	saveCollectionUC       usecase.SaveCollectionUseCase
+	deleteCollectionUC     usecase.DeleteCollectionUseCase
+	listReviewsUC          usecase.ListReviewsUseCase
+	getReviewUC            usecase.GetReviewUseCase
+	addReviewUC            usecase.AddReviewUseCase
+	updateReviewUC         usecase.UpdateReviewUseCase
+	deleteReviewUC         usecase.DeleteReviewUseCase
+	getCookbookUC          usecase.GetCookbookUseCase
+	exportLibraryUC        usecase.ExportLibraryUseCase
+	importLibraryUC        usecase.ImportLibraryUseCase
+}
+
+// cliViewer is who the offline subcommands act as: they have direct access
+// to the data files, so they see and change every recipe.
+var cliViewer = domain.Viewer{Admin: true}
+
+// recipeLayout describes which files of the recipes directory hold recipes.
+func recipeLayout(cfg *config.Config) repository.Layout {
+	return repository.Layout{
+		MaxDepth:         cfg.RecipesMaxDepth,
+		Ignore:           cfg.RecipesIgnore,
+		FolderCategories: cfg.RecipesFolderCategories,
+		NamespaceIDs:     cfg.RecipesNamespaceIDs,
+	}
+}
+
+// newApp loads the repository described by cfg and wires the use cases. When
+// strict is false, invalid recipe files are skipped instead of failing.
+func newApp(cfg *config.Config, strict bool) (*app, error) {
+	// Initialize repository based on config
+	recipeValidator := domain.NewRecipeValidator()
+	repo, err := repository.NewJSONRepository(cfg.RecipesPath,
+		repository.WithValidator(recipeValidator, strict), repository.WithLayout(recipeLayout(cfg)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to init JSON repository: %w", err)
+	}
+
+	images, err := repository.NewLocalImageStore(cfg.ImagesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init image store: %w", err)
+	}
+
+	accounts, err := openAccounts(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init accounts store: %w", err)
+	}
+
+	// Instrument the repository and domain services
+	m := metrics.New(repo)
+	repo = tracing.InstrumentRepository(m.InstrumentRepository(repo))
+	images = tracing.InstrumentImageStore(images)
+	recipeService := m.InstrumentRecipeService(domain.NewRecipeService())
+
+	// Initialize use cases
+	return &app{
+		cfg:           cfg,
+		repo:          repo,
+		images:        images,
+		accounts:      accounts,
+		metrics:       m,
+		recipeService: recipeService,
+
+		getRecipeUC:            usecase.NewGetRecipeUseCase(repo, recipeService),
+		getAllRecipesUC:        usecase.NewGetAllRecipesUseCase(repo, accounts),
+		listRecipeSummariesUC:  usecase.NewListRecipeSummariesUseCase(repo, accounts),
+		getAllIngredientsUC:    usecase.NewGetAllIngredientsUseCase(repo, accounts),
+		getRecipeVersionUC:     usecase.NewGetRecipeVersionUseCase(repo),
+		getCollectionVersionUC: usecase.NewGetCollectionVersionUseCase(repo, accounts),
+		saveRecipeUC:           usecase.NewSaveRecipeUseCase(repo, accounts),
+		resolveRecipeUC:        usecase.NewResolveRecipeUseCase(repo),
+		listRevisionsUC:        usecase.NewListRevisionsUseCase(repo),
+		getRevisionUC:          usecase.NewGetRevisionUseCase(repo),
+		diffRevisionsUC:        usecase.NewDiffRevisionsUseCase(repo),
+		revertRecipeUC:         usecase.NewRevertRecipeUseCase(repo),
+		uploadIllustrationUC:   usecase.NewUploadIllustrationUseCase(repo, images),
+		getImageUC:             usecase.NewGetImageUseCase(images),
+		findImageRecipesUC:     usecase.NewFindImageRecipesUseCase(repo),
+		getValidationReportUC:  usecase.NewGetValidationReportUseCase(repo),
+		getShoppingListUC:      usecase.NewGetShoppingListUseCase(repo, recipeService),
+		reloadRecipesUC:        usecase.NewReloadRecipesUseCase(repo),
+		authorizeRecipeUC:      usecase.NewAuthorizeRecipeUseCase(accounts),
+		setRecipeVisibilityUC:  usecase.NewSetRecipeVisibilityUseCase(repo, accounts),
+		registerUserUC:         usecase.NewRegisterUserUseCase(accounts),
+		loginUC:                usecase.NewLoginUseCase(accounts, time.Duration(cfg.SessionTTL)),
+		logoutUC:               usecase.NewLogoutUseCase(accounts),
+		authenticateSessionUC:  usecase.NewAuthenticateSessionUseCase(accounts),
+		getProfileUC:           usecase.NewGetProfileUseCase(accounts),
+		createHouseholdUC:      usecase.NewCreateHouseholdUseCase(accounts),
+		addHouseholdMemberUC:   usecase.NewAddHouseholdMemberUseCase(accounts),
+		listFavoritesUC:        usecase.NewListFavoritesUseCase(repo, accounts),
+		setFavoriteUC:          usecase.NewSetFavoriteUseCase(repo, accounts),
+		listCollectionsUC:      usecase.NewListCollectionsUseCase(accounts),
+		getCollectionUC:        usecase.NewGetCollectionUseCase(accounts),
+		saveCollectionUC:       usecase.NewSaveCollectionUseCase(repo, accounts),
+		deleteCollectionUC:     usecase.NewDeleteCollectionUseCase(accounts),
+		listReviewsUC:          usecase.NewListReviewsUseCase(repo, accounts),
+		getReviewUC:            usecase.NewGetReviewUseCase(accounts),
+		addReviewUC:            usecase.NewAddReviewUseCase(repo, accounts),
+		updateReviewUC:         usecase.NewUpdateReviewUseCase(accounts),
+		deleteReviewUC:         usecase.NewDeleteReviewUseCase(accounts),
+		getCookbookUC:          usecase.NewGetCookbookUseCase(repo, accounts),
+		exportLibraryUC:        usecase.NewExportLibraryUseCase(repo, accounts, images),
+		importLibraryUC:        usecase.NewImportLibraryUseCase(repo, accounts, images, recipeValidator),
+	}, nil
+}
+
+// openAccounts opens the accounts store selected by the configuration.
+func openAccounts(cfg *config.Config) (repository.AccountRepository, error) {
+	if cfg.AccountsStore == config.AccountsStoreSQLite {
+		return repository.NewSQLiteAccountRepository(cfg.AccountsPath)
+	}
+	return repository.NewJSONAccountRepository(cfg.AccountsPath)
+}
+
+// resolveRecipeIDs returns the IDs of the recipes given on the command line
+// by ID, slug or alias.
+func (a *app) resolveRecipeIDs(ctx context.Context, keys []string) ([]string, error) {
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ref, err := a.resolveRecipeUC.Execute(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("recipe %q: %w", key, err)
+		}
+		ids[i] = ref.ID
+	}
+	return ids, nil
+}
+
+// frontend returns the built frontend to serve under /, or nil to serve none.
+func (a *app) frontend() (fs.FS, error) {
+	embedded := frontend.Embedded()
+	switch a.cfg.FrontendSource {
+	case config.FrontendNone:
+		return nil, nil
+	case config.FrontendEmbedded:
+		if embedded == nil {
+			return nil, errors.New("frontend_source is embedded but the binary was built without -tags embedfrontend")
+		}
+		return embedded, nil
+	case config.FrontendAuto:
+		if embedded != nil {
+			return embedded, nil
+		}
+	}
+	if a.cfg.StaticPath == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(filepath.Join(a.cfg.StaticPath, "index.html")); err != nil {
+		slog.Warn(fmt.Sprintf("Static directory %s has no index.html, the frontend will not be served", a.cfg.StaticPath))
+		return nil, nil
+	}
+	return os.DirFS(a.cfg.StaticPath), nil
+}
+
+// router builds the HTTP handlers on top of the use cases.
+func (a *app) router(logger *slog.Logger, healthHandler *handlers.HealthHandler) (http.Handler, error) {
+	recipeHandler := handlers.NewRecipeHandler(
+		a.getRecipeUC, a.listRecipeSummariesUC, a.getAllIngredientsUC,
+		a.getRecipeVersionUC, a.getCollectionVersionUC, a.saveRecipeUC,
+		a.authorizeRecipeUC, a.resolveRecipeUC, a.getImageUC,
+	)
+	revisionHandler := handlers.NewRevisionHandler(a.listRevisionsUC, a.getRevisionUC, a.diffRevisionsUC, a.revertRecipeUC)
+	imageHandler := handlers.NewImageHandler(a.uploadIllustrationUC, a.getImageUC, a.findImageRecipesUC, a.cfg.MaxImageSize)
+	adminHandler := handlers.NewAdminHandler(a.getValidationReportUC, a.reloadRecipesUC)
+	accountHandler := handlers.NewAccountHandler(
+		a.registerUserUC, a.loginUC, a.logoutUC, a.getProfileUC,
+		a.createHouseholdUC, a.addHouseholdMemberUC, a.setRecipeVisibilityUC,
+	)
+	reviewHandler := handlers.NewReviewHandler(a.listReviewsUC, a.getReviewUC, a.addReviewUC, a.updateReviewUC, a.deleteReviewUC)
+	collectionHandler := handlers.NewCollectionHandler(
+		a.listFavoritesUC, a.setFavoriteUC, a.listCollectionsUC,
+		a.getCollectionUC, a.saveCollectionUC, a.deleteCollectionUC,
+	)
+	cookbookHandler := handlers.NewCookbookHandler(a.getCookbookUC, a.getImageUC)
+	libraryHandler := handlers.NewLibraryHandler(a.exportLibraryUC, a.importLibraryUC, a.cfg.MaxImportSize)
+
+	// Clients are always authenticated, so that logged in users see their
+	// own recipes; auth_enabled decides whether credentials are required.
+	authenticator, err := a.authenticator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to init authentication: %w", err)
+	}
+	frontendFS, err := a.frontend()
+	if err != nil {
+		return nil, err
+	}
+	routerConfig := handlers.RouterConfig{
+		Frontend:         frontendFS,
+		RequestTimeout:   time.Duration(a.cfg.RequestTimeout),
+		Logger:           logger,
+		Metrics:          a.metrics,
+		Auth:             authenticator,
+		RequireAuth:      a.cfg.AuthEnabled,
+		AnonymousRead:    a.cfg.AuthAnonymousRead,
+		RegistrationOpen: a.cfg.RegistrationOpen,
+		CORS: handlers.CORSPolicy{
+			AllowedOrigins:   a.cfg.CORSAllowedOrigins,
+			AllowedMethods:   a.cfg.CORSAllowedMethods,
+			AllowedHeaders:   a.cfg.CORSAllowedHeaders,
+			ExposedHeaders:   a.cfg.CORSExposedHeaders,
+			AllowCredentials: a.cfg.CORSAllowCredentials,
+			MaxAge:           time.Duration(a.cfg.CORSMaxAge),
+		},
+		RateLimits:  make(map[string]ratelimit.Limit),
+		MaxBodySize: a.cfg.MaxBodySize,
+	}
+	for _, limit := range a.cfg.RateLimits {
+		routerConfig.RateLimits[limit.Group] = ratelimit.Limit{RequestsPerMinute: limit.RequestsPerMinute, Burst: limit.Burst}
+	}
+	return handlers.NewRouter(
+		routerConfig, healthHandler, recipeHandler, revisionHandler, imageHandler, adminHandler,
+		accountHandler, collectionHandler, reviewHandler, cookbookHandler, libraryHandler,
+	), nil
+}
+
+// authenticator accepts the user sessions, and the API keys and JWTs
+// described by the configuration.
+func (a *app) authenticator() (*auth.Authenticator, error) {
+	apiKeys := make([]auth.APIKey, len(a.cfg.AuthAPIKeys))
+	for i, key := range a.cfg.AuthAPIKeys {
+		apiKeys[i] = auth.APIKey{Name: key.Name, Key: string(key.Key), Scopes: key.Scopes}
+	}
+	return auth.New(auth.Options{
+		APIKeys:   apiKeys,
+		JWTSecret: []byte(a.cfg.AuthJWTSecret),
+		JWKSFile:  a.cfg.AuthJWKSFile,
+		Issuer:    a.cfg.AuthJWTIssuer,
+		Audience:  a.cfg.AuthJWTAudience,
+		Sessions:  sessionResolver{a.authenticateSessionUC},
+	})
+}
+
+// sessionResolver resolves session tokens to the principal of their user.
+type sessionResolver struct {
+	authenticateSessionUC usecase.AuthenticateSessionUseCase
+}
+
+func (s sessionResolver) ResolveSession(ctx context.Context, token string) (*auth.Principal, error) {
+	user, err := s.authenticateSessionUC.Execute(ctx, token)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil, fmt.Errorf("%w: unknown or expired session", auth.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Principal{
+		Subject:     user.Username,
+		Scopes:      []string{auth.ScopeRead, auth.ScopeWrite},
+		UserID:      user.ID,
+		HouseholdID: user.HouseholdID,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/config"
+	"github.com/fromenjn/recipe-manager/internal/handlers"
+)
+
+// newTestRouter serves a copy of the sample recipes.
+func newTestRouter(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	recipes := t.TempDir()
+	files, err := filepath.Glob("../../data/recipes/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("failed to list sample recipes: %v", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(recipes, filepath.Base(file)), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Default()
+	cfg.RecipesPath = recipes
+	cfg.ImagesPath = t.TempDir()
+	cfg.AccountsPath = filepath.Join(t.TempDir(), "accounts.json")
+	cfg.FrontendSource = config.FrontendNone
+	a, err := newApp(cfg, true)
+	if err != nil {
+		t.Fatalf("failed to create app: %v", err)
+	}
+	router, err := a.router(slog.New(slog.NewTextHandler(io.Discard, nil)), handlers.NewHealthHandler())
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	return router, recipes
+}
+
+func TestRouter_RecipeRoutesResolveSlugs(t *testing.T) {
+	router, recipes := newTestRouter(t)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A PUT by slug updates the recipe instead of creating another one.
+	rec := send(http.MethodPut, "/recipe/chocolate-cake", `{"name": "Chocolate Cake", "servings": 12, "ingredients": [], "steps": []}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"2"`) {
+		t.Fatalf("expected recipe 2 to be updated, got %d: %s", rec.Code, rec.Body)
+	}
+	if files, _ := filepath.Glob(filepath.Join(recipes, "*.json")); len(files) != 2 {
+		t.Errorf("expected no recipe to be created, got %v", files)
+	}
+	if rec := send(http.MethodGet, "/recipe/chocolate-cake", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"servings":12`) {
+		t.Errorf("expected the slug to still reach recipe 2, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec := send(http.MethodGet, "/recipes/chocolate-cake/revisions", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected the revisions to be found by slug, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration and where each value comes from",
+		Long: "Print the effective configuration. Values are taken from the defaults, then the config file,\n" +
+			"then RECIPE_MANAGER_* environment variables, then command-line flags.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.loadConfig()
+			if err != nil {
+				return err
+			}
+			if opts.output == formatJSON {
+				return writeJSON(cmd.OutOrStdout(), cfg)
+			}
+			fields := cfg.Fields()
+			rows := make([][]string, len(fields))
+			for i, field := range fields {
+				rows[i] = []string{field.Key, field.Value, field.Origin, field.Env}
+			}
+			return writeTable(cmd.OutOrStdout(), opts.output, []string{"KEY", "VALUE", "ORIGIN", "ENV"}, rows)
+		},
+	})
+	return cmd
+}
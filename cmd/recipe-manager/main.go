@@ -1,55 +1,119 @@
 package main
 
 import (
-	"flag"
-	"fmt"
-	"log"
 	"log/slog"
-	"net/http"
 	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/fromenjn/recipe-manager/internal/config"
-	"github.com/fromenjn/recipe-manager/internal/domain"
-	"github.com/fromenjn/recipe-manager/internal/handlers"
-	"github.com/fromenjn/recipe-manager/internal/repository"
-	"github.com/fromenjn/recipe-manager/internal/usecase"
 )
 
-func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
-	slog.SetDefault(logger)
-	configPath := flag.String("config", "config/config.json", "Path to configuration JSON file")
-	flag.Parse()
-	// Load config
-	cfg, err := config.LoadConfig(*configPath)
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+// configEnv names the environment variable selecting the config file when
+// --config is not given.
+const configEnv = config.EnvPrefix + "CONFIG"
+
+// globalOptions holds the flags shared by every subcommand.
+type globalOptions struct {
+	configPath string
+	output     outputFormat
+
+	// flags holds the persistent flags overriding configuration keys.
+	flags *pflag.FlagSet
+}
+
+// loadConfig layers the config file, the environment and the command-line
+// flags over the defaults. The default config file is optional, while one
+// given with --config or RECIPE_MANAGER_CONFIG must exist.
+func (o *globalOptions) loadConfig() (*config.Config, error) {
+	sources := config.Sources{
+		File:      o.configPath,
+		LookupEnv: os.LookupEnv,
+		Flags:     make(map[string]string),
+	}
+	if o.flags.Changed("config") {
+		sources.FileRequired = true
+	} else if path, ok := os.LookupEnv(configEnv); ok {
+		sources.File, sources.FileRequired = path, true
 	}
+	for _, field := range config.Default().Fields() {
+		if flag := o.flags.Lookup(configFlagName(field.Key)); flag.Changed {
+			sources.Flags[field.Key] = flag.Value.String()
+		}
+	}
+	return config.Load(sources)
+}
 
-	// Initialize repository based on config
-	repo, err := repository.NewJSONRepository(cfg.RecipesPath)
-	if err != nil {
-		log.Fatalf("Failed to init JSON repository: %v", err)
+func configFlagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+func main() {
+	root := newRootCommand()
+	root.SetArgs(normalizeArgs(os.Args[1:]))
+	if err := root.Execute(); err != nil {
+		os.Exit(1)
 	}
-	// Initialize domain service
-	recipeService := domain.NewRecipeService()
+}
 
-	// Initialize use cases
-	getRecipeUC := usecase.NewGetRecipeUseCase(repo, recipeService)
-	getAllRecipesUC := usecase.NewGetAllRecipesUseCase(repo)
-	getAllIngredientsUC := usecase.NewGetAllIngredientsUseCase(repo)
+func newRootCommand() *cobra.Command {
+	opts := &globalOptions{output: formatTable}
 
-	// Initialize handlers
-	recipeHandler := handlers.NewRecipeHandler(getRecipeUC, getAllRecipesUC, getAllIngredientsUC)
+	serve := newServeCommand(opts)
+	root := &cobra.Command{
+		Use:   "recipe-manager",
+		Short: "Serve and manage a directory of JSON recipes",
+		Long: "recipe-manager serves a directory of JSON recipes over HTTP. The subcommands work on the same\n" +
+			"directory offline, for scripting in CI or at the shell. Without a subcommand, the server is started.",
+		SilenceUsage: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// The server configures its own logger from the configuration.
+			if cmd != cmd.Root() && cmd.Name() != serve.Name() {
+				setupOfflineLogging()
+			}
+		},
+		RunE: serve.RunE,
+	}
+	opts.flags = root.PersistentFlags()
+	opts.flags.StringVar(&opts.configPath, "config", "config/config.json", "Path to a JSON or YAML configuration file (env "+configEnv+")")
+	opts.flags.VarP(&opts.output, "output", "o", "Output format: table, json, markdown or text")
+	for _, field := range config.Default().Fields() {
+		opts.flags.String(configFlagName(field.Key), field.Value, "Override the "+field.Key+" setting (env "+field.Env+")")
+	}
+
+	root.AddCommand(
+		serve,
+		newValidateCommand(opts),
+		newMigrateCommand(opts),
+		newListCommand(opts),
+		newShowCommand(opts),
+		newScaleCommand(opts),
+		newImportCommand(opts),
+		newExportCommand(opts),
+		newPDFCommand(opts),
+		newShoppingListCommand(opts),
+		newConfigCommand(opts),
+	)
+	return root
+}
 
-	// Create router
-	router := handlers.NewRouter(recipeHandler)
+// setupOfflineLogging only reports warnings on stderr, so that the output of
+// offline commands can be piped.
+func setupOfflineLogging() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+}
 
-	// Start server on the configured port
-	slog.Info(fmt.Sprintf("Starting server on %s", cfg.ServerPort))
-	if err := http.ListenAndServe(cfg.ServerPort, router); err != nil {
-		slog.Error(fmt.Sprintf("server error: %v", err))
+// normalizeArgs keeps accepting the single-dash "-config" flag of earlier
+// releases, which the POSIX-style flag parser would read as shorthands.
+func normalizeArgs(args []string) []string {
+	normalized := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "-config" || strings.HasPrefix(arg, "-config=") {
+			arg = "-" + arg
+		}
+		normalized[i] = arg
 	}
+	return normalized
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeArgs(t *testing.T) {
+	got := normalizeArgs([]string{"-config", "a.json", "-config=b.json", "--config", "c.json", "-o", "json"})
+	want := []string{"--config", "a.json", "--config=b.json", "--config", "c.json", "-o", "json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestParseShoppingListEntry(t *testing.T) {
+	entry, err := parseShoppingListEntry("2:1.5")
+	if err != nil || entry.RecipeID != "2" || entry.Factor != 1.5 {
+		t.Errorf("unexpected entry %+v (err %v)", entry, err)
+	}
+	entry, err = parseShoppingListEntry("ns:cake:2")
+	if err != nil || entry.RecipeID != "ns:cake" || entry.Factor != 2 {
+		t.Errorf("unexpected entry %+v (err %v)", entry, err)
+	}
+	if _, err := parseShoppingListEntry("2:-1"); err == nil {
+		t.Error("expected error for a negative factor, got none")
+	}
+}
+
+func TestWriteTable_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeTable(&buf, formatMarkdown, []string{"A", "B"}, [][]string{{"x|y", "1"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "| A | B |\n| --- | --- |\n| x\\|y | 1 |\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestListCommand(t *testing.T) {
+	recipes, err := filepath.Abs("../../data/recipes")
+	if err != nil {
+		t.Fatalf("failed to resolve recipes path: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := fmt.Sprintf(`{"recipes_path": %q, "images_path": %q}`, recipes, t.TempDir())
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	root := newRootCommand()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"--config", configPath, "list", "-o", "json", "--ingredient", "Flour"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Chocolate Cake") || strings.Contains(out.String(), "Spaghetti") {
+		t.Errorf("expected only Chocolate Cake to be listed, got %s", out.String())
+	}
+}
+
+func TestScaleCommand_Slug(t *testing.T) {
+	recipes, err := filepath.Abs("../../data/recipes")
+	if err != nil {
+		t.Fatalf("failed to resolve recipes path: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := fmt.Sprintf(`{"recipes_path": %q, "images_path": %q}`, recipes, t.TempDir())
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	root := newRootCommand()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"--config", configPath, "scale", "chocolate-cake", "--factor", "2", "-o", "json"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"id": "2"`) {
+		t.Errorf("expected the recipe to be found by its slug, got %s", out.String())
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fromenjn/recipe-manager/internal/repository"
+)
+
+func newMigrateCommand(opts *globalOptions) *cobra.Command {
+	var migrateOpts repository.MigrateOptions
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Rewrite recipe files in older formats to the current schema version",
+		Long: "Rewrite the recipe files and their history in older formats to the current schema version.\n" +
+			"Older files are also read by the server, which upgrades them in memory; this command makes the\n" +
+			"upgrade permanent. Each file is copied to the backup directory before it is rewritten. Stop the\n" +
+			"server first.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			migrateOpts.Layout = recipeLayout(cfg)
+			report, err := repository.MigrateFiles(cmd.Context(), cfg.RecipesPath, migrateOpts)
+			if err != nil {
+				return fmt.Errorf("failed to migrate recipes: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			if opts.output == formatJSON {
+				return writeJSON(out, report)
+			}
+			if len(report.Files) == 0 {
+				fmt.Fprintf(out, "All %d files are at schema version %d\n", report.UpToDate, repository.SchemaVersion)
+				return nil
+			}
+			rows := make([][]string, len(report.Files))
+			for i, file := range report.Files {
+				path, err := filepath.Rel(cfg.RecipesPath, file.Path)
+				if err != nil {
+					path = file.Path
+				}
+				rows[i] = []string{path, strconv.Itoa(file.From), strconv.Itoa(file.To)}
+			}
+			if err := writeTable(out, opts.output, []string{"FILE", "FROM", "TO"}, rows); err != nil {
+				return err
+			}
+			fmt.Fprintln(out)
+			if report.DryRun {
+				fmt.Fprintf(out, "%d files would be migrated to schema version %d\n", len(report.Files), repository.SchemaVersion)
+				return nil
+			}
+			fmt.Fprintf(out, "Migrated %d files to schema version %d, originals saved in %s\n",
+				len(report.Files), repository.SchemaVersion, report.BackupDir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&migrateOpts.BackupDir, "backup-dir", "",
+		"Directory to copy the original files to (default <recipes_path>/.backup/<time>)")
+	cmd.Flags().BoolVar(&migrateOpts.DryRun, "dry-run", false, "List the files to migrate without changing anything")
+	return cmd
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/render"
+)
+
+// outputFormat selects how subcommands print their results.
+type outputFormat string
+
+const (
+	formatTable    outputFormat = "table"
+	formatJSON     outputFormat = "json"
+	formatMarkdown outputFormat = "markdown"
+	// formatText prints recipes as plain text for sharing; other results
+	// are printed as tables.
+	formatText outputFormat = "text"
+)
+
+func (f *outputFormat) String() string {
+	return string(*f)
+}
+
+func (f *outputFormat) Set(value string) error {
+	switch outputFormat(value) {
+	case formatTable, formatJSON, formatMarkdown, formatText:
+		*f = outputFormat(value)
+		return nil
+	case "md":
+		*f = formatMarkdown
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, expected table, json, markdown or text", value)
+}
+
+func (f *outputFormat) Type() string {
+	return "format"
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeTable prints rows as an aligned text table, or as a Markdown table.
+func writeTable(w io.Writer, format outputFormat, headers []string, rows [][]string) error {
+	if format == formatMarkdown {
+		fmt.Fprintf(w, "| %s |\n", strings.Join(headers, " | "))
+		separators := make([]string, len(headers))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | "))
+		for _, row := range rows {
+			escaped := make([]string, len(row))
+			for i, cell := range row {
+				escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeRecipe prints a full recipe: its ingredients, then its numbered steps.
+// Markdown and text are rendered as by the API; scaling, when set, is noted.
+func writeRecipe(w io.Writer, format outputFormat, recipe *domain.Recipe, scaling *render.Scaling) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, recipe)
+	case formatMarkdown:
+		return render.Recipe(w, render.FormatMarkdown, *recipe, scaling)
+	case formatText:
+		return render.Recipe(w, render.FormatText, *recipe, scaling)
+	}
+
+	rows := make([][]string, len(recipe.Ingredients))
+	for i, ingredient := range recipe.Ingredients {
+		rows[i] = []string{ingredient.Name, render.Quantity(ingredient.Quantity, ingredient.Unit), ingredient.Unit}
+	}
+	fmt.Fprintf(w, "%s (ID %s)\n", recipe.Name, recipe.ID)
+	if scaling != nil {
+		fmt.Fprintln(w, scaling)
+	}
+	fmt.Fprint(w, "\nIngredients:\n")
+	if err := writeTable(w, format, []string{"INGREDIENT", "QUANTITY", "UNIT"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprint(w, "\nSteps:\n")
+	for i, step := range recipe.Steps {
+		fmt.Fprintf(w, "%2d. %s: %s\n", i+1, step.Name, step.Instructions)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fromenjn/recipe-manager/internal/render"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func newPDFCommand(opts *globalOptions) *cobra.Command {
+	var (
+		file     string
+		title    string
+		cookbook bool
+	)
+	cmd := &cobra.Command{
+		Use:   "pdf [recipe-id-or-slug]...",
+		Short: "Write a recipe card or a cookbook as PDF",
+		Long: "Write the printable card of a recipe as PDF, or bind the given recipes, or all of them sorted by\n" +
+			"name, into a cookbook with a table of contents and an index of ingredients.",
+		Example: "  recipe-manager pdf 2 --file chocolate_cake.pdf\n" +
+			"  recipe-manager pdf 1 2 --title \"Sunday dinners\" --file sunday.pdf",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+			ids, err := a.resolveRecipeIDs(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			images := func(path string) ([]byte, error) {
+				return usecase.ReadIllustration(cmd.Context(), a.getImageUC, path)
+			}
+
+			var body bytes.Buffer
+			if len(ids) == 1 && !cookbook {
+				recipe, err := a.getRecipeUC.Execute(cmd.Context(), ids[0], "", 0)
+				if err != nil {
+					return err
+				}
+				if err := render.RecipeCard(&body, *recipe, nil, images); err != nil {
+					return err
+				}
+			} else {
+				book, err := a.getCookbookUC.Execute(cmd.Context(), cliViewer, usecase.CookbookSelection{RecipeIDs: ids})
+				if err != nil {
+					return err
+				}
+				if title != "" {
+					book.Title = title
+				}
+				if err := render.Cookbook(&body, book.Title, book.Recipes, images); err != nil {
+					return err
+				}
+			}
+
+			if file == "" || file == "-" {
+				_, err := cmd.OutOrStdout().Write(body.Bytes())
+				return err
+			}
+			if err := os.WriteFile(file, body.Bytes(), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s\n", file)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "File to write the PDF to instead of standard output")
+	cmd.Flags().StringVar(&title, "title", "", "Title of the cookbook (default \""+usecase.DefaultCookbookTitle+"\")")
+	cmd.Flags().BoolVar(&cookbook, "cookbook", false, "Bind a single recipe as a cookbook rather than a recipe card")
+	return cmd
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fromenjn/recipe-manager/internal/config"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/render"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// loadApp loads the configuration and the repository for an offline command.
+func loadApp(opts *globalOptions) (*app, error) {
+	cfg, err := opts.loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return newApp(cfg, cfg.ValidationMode == config.ValidationStrict)
+}
+
+func newListCommand(opts *globalOptions) *cobra.Command {
+	var ingredient, sortBy string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recipes, optionally only those using an ingredient",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			order, err := usecase.ParseRecipeSort(sortBy)
+			if err != nil {
+				return err
+			}
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+			recipes, err := a.listRecipeSummariesUC.Execute(cmd.Context(), cliViewer, ingredient, order)
+			if err != nil {
+				return err
+			}
+			if order == usecase.SortByDefault {
+				sort.Slice(recipes, func(i, j int) bool { return recipes[i].ID < recipes[j].ID })
+			}
+
+			if opts.output == formatJSON {
+				return writeJSON(cmd.OutOrStdout(), recipes)
+			}
+			rows := make([][]string, len(recipes))
+			for i, recipe := range recipes {
+				rating := "-"
+				if recipe.RatingCount > 0 {
+					rating = strconv.FormatFloat(recipe.AverageRating, 'f', 1, 64)
+				}
+				rows[i] = []string{
+					recipe.ID, recipe.Name,
+					strconv.Itoa(len(recipe.Ingredients)), strconv.Itoa(len(recipe.Steps)),
+					rating, strconv.Itoa(recipe.TimesCooked),
+				}
+			}
+			return writeTable(cmd.OutOrStdout(), opts.output, []string{"ID", "NAME", "INGREDIENTS", "STEPS", "RATING", "COOKED"}, rows)
+		},
+	}
+	cmd.Flags().StringVar(&ingredient, "ingredient", "", "Only list recipes using this ingredient")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Order recipes by name, rating or last_cooked instead of ID")
+	return cmd
+}
+
+func newShowCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <recipe-id-or-slug>",
+		Short: "Print a recipe",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+			ids, err := a.resolveRecipeIDs(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+			recipe, err := a.getRecipeUC.Execute(cmd.Context(), ids[0], "", 0)
+			if err != nil {
+				return err
+			}
+			return writeRecipe(cmd.OutOrStdout(), opts.output, recipe, nil)
+		},
+	}
+}
+
+func newScaleCommand(opts *globalOptions) *cobra.Command {
+	var (
+		ingredient string
+		quantity   float64
+		factor     float64
+	)
+	cmd := &cobra.Command{
+		Use:   "scale <recipe-id-or-slug>",
+		Short: "Print a recipe with scaled quantities",
+		Long: "Print a recipe with all quantities scaled, either so that one ingredient reaches a given\n" +
+			"quantity (--ingredient and --quantity) or by a fixed factor (--factor).",
+		Example: "  recipe-manager scale 2 --ingredient Flour --quantity 300\n  recipe-manager scale 2 --factor 1.5",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (ingredient == "") == (factor == 0) {
+				return errors.New("either --ingredient and --quantity, or --factor, is required")
+			}
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+			ids, err := a.resolveRecipeIDs(cmd.Context(), args)
+			if err != nil {
+				return err
+			}
+
+			var recipe *domain.Recipe
+			if factor != 0 {
+				recipe, err = a.getRecipeUC.Execute(cmd.Context(), ids[0], "", 0)
+				if err == nil {
+					err = a.recipeService.Scale(recipe, factor)
+				}
+			} else {
+				if quantity <= 0 {
+					return errors.New("--quantity must be positive")
+				}
+				recipe, err = a.getRecipeUC.Execute(cmd.Context(), ids[0], ingredient, quantity)
+			}
+			if err != nil {
+				return err
+			}
+			scaling := &render.Scaling{Factor: factor}
+			if factor == 0 {
+				scaling = render.ScaledTo(*recipe, ingredient, quantity)
+			}
+			return writeRecipe(cmd.OutOrStdout(), opts.output, recipe, scaling)
+		},
+	}
+	cmd.Flags().StringVar(&ingredient, "ingredient", "", "Ingredient to scale to --quantity")
+	cmd.Flags().Float64Var(&quantity, "quantity", 0, "Target quantity of --ingredient")
+	cmd.Flags().Float64Var(&factor, "factor", 0, "Factor to multiply all quantities by")
+	cmd.MarkFlagsRequiredTogether("ingredient", "quantity")
+	cmd.MarkFlagsMutuallyExclusive("ingredient", "factor")
+	return cmd
+}
+
+func newValidateCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check every recipe file and report problems",
+		Long:  "Check every recipe file and report problems. Exits with a non-zero status if any file is invalid.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := opts.loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// Load leniently so that all problems are reported at once.
+			a, err := newApp(cfg, false)
+			if err != nil {
+				return err
+			}
+			report, err := a.getValidationReportUC.Execute(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if opts.output == formatJSON {
+				if err := writeJSON(out, report); err != nil {
+					return err
+				}
+			} else {
+				var rows [][]string
+				for _, file := range report.Files {
+					for _, fieldErr := range file.Errors {
+						rows = append(rows, []string{file.Path, file.RecipeID, fieldErr.Path, fieldErr.Message})
+					}
+				}
+				if len(rows) > 0 {
+					if err := writeTable(out, opts.output, []string{"FILE", "RECIPE", "FIELD", "PROBLEM"}, rows); err != nil {
+						return err
+					}
+					fmt.Fprintln(out)
+				}
+				fmt.Fprintf(out, "%d recipes valid, %d files invalid\n", report.Loaded, len(report.Files))
+				if report.Outdated > 0 {
+					fmt.Fprintf(out, "%d files in an older format, run migrate to upgrade them\n", report.Outdated)
+				}
+			}
+
+			if len(report.Files) > 0 {
+				return fmt.Errorf("%d invalid recipe files", len(report.Files))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fromenjn/recipe-manager/internal/config"
+	"github.com/fromenjn/recipe-manager/internal/handlers"
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+func newServeCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server",
+		Long: "Start the HTTP server. On SIGINT or SIGTERM the server stops accepting connections, reports\n" +
+			"itself not ready and waits up to shutdown_timeout for in-flight requests to complete.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Load config
+			cfg, err := opts.loadConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+			if err != nil {
+				return err
+			}
+			slog.SetDefault(logger)
+
+			shutdownTracing, err := tracing.Setup(cmd.Context(), cfg.TracingExporter, cfg.TracingEndpoint)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				// Flush the last spans; the exporter may be unreachable by now.
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := shutdownTracing(ctx); err != nil {
+					slog.Warn(fmt.Sprintf("failed to flush traces: %v", err))
+				}
+			}()
+
+			a, err := newApp(cfg, cfg.ValidationMode == config.ValidationStrict)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return serve(ctx, cfg, a, logger)
+		},
+	}
+}
+
+// serve runs the HTTP server until ctx is done, then drains in-flight
+// requests for at most cfg.ShutdownTimeout.
+func serve(ctx context.Context, cfg *config.Config, a *app, logger *slog.Logger) error {
+	healthHandler := handlers.NewHealthHandler()
+	router, err := a.router(logger, healthHandler)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:         cfg.ServerPort,
+		Handler:      router,
+		ReadTimeout:  time.Duration(cfg.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.IdleTimeout),
+	}
+
+	// Listen first so that an unavailable port is reported before going ready.
+	listener, err := net.Listen("tcp", cfg.ServerPort)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.ServerPort, err)
+	}
+
+	// Start server on the configured port
+	slog.Info(fmt.Sprintf("Starting server on %s", listener.Addr()))
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	// The recipes loaded with the app, so the server is ready to handle requests.
+	healthHandler.SetReady(true)
+
+	// SIGHUP reloads the recipe files, e.g. after a git pull.
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for running := true; running; {
+		select {
+		case err := <-serveErr:
+			slog.Error(fmt.Sprintf("server error: %v", err))
+			return err
+		case <-hangup:
+			if _, err := a.reloadRecipesUC.Execute(ctx); err != nil {
+				slog.Error(fmt.Sprintf("reload failed, keeping the loaded recipes: %v", err))
+			}
+		case <-ctx.Done():
+			running = false
+		}
+	}
+
+	slog.Info(fmt.Sprintf("Shutting down, draining requests for up to %s", cfg.ShutdownTimeout))
+	healthHandler.SetReady(false)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("requests still in flight after %s, closed their connections", cfg.ShutdownTimeout)
+		}
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("Server stopped")
+	return nil
+}
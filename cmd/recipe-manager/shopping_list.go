@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fromenjn/recipe-manager/internal/render"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func newShoppingListCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "shopping-list <recipe-id-or-slug>[:factor]...",
+		Short: "Add up the ingredients of several recipes",
+		Long: "Add up the ingredients of several recipes. Append :factor to a recipe ID to scale it, e.g. 2:1.5\n" +
+			"for one and a half times recipe 2. Quantities are only added up for identical units.",
+		Example: "  recipe-manager shopping-list 1 2:2 -o markdown",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries := make([]usecase.ShoppingListEntry, len(args))
+			for i, arg := range args {
+				entry, err := parseShoppingListEntry(arg)
+				if err != nil {
+					return err
+				}
+				entries[i] = entry
+			}
+
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+			keys := make([]string, len(entries))
+			for i, entry := range entries {
+				keys[i] = entry.RecipeID
+			}
+			ids, err := a.resolveRecipeIDs(cmd.Context(), keys)
+			if err != nil {
+				return err
+			}
+			for i := range entries {
+				entries[i].RecipeID = ids[i]
+			}
+			items, err := a.getShoppingListUC.Execute(cmd.Context(), entries)
+			if err != nil {
+				return err
+			}
+
+			if opts.output == formatJSON {
+				return writeJSON(cmd.OutOrStdout(), items)
+			}
+			rows := make([][]string, len(items))
+			for i, item := range items {
+				rows[i] = []string{item.Name, render.Quantity(item.Quantity, item.Unit), item.Unit, strings.Join(item.Recipes, ", ")}
+			}
+			return writeTable(cmd.OutOrStdout(), opts.output, []string{"INGREDIENT", "QUANTITY", "UNIT", "RECIPES"}, rows)
+		},
+	}
+}
+
+// parseShoppingListEntry parses "id" or "id:factor". The factor is split at
+// the last colon so that recipe IDs may contain colons.
+func parseShoppingListEntry(arg string) (usecase.ShoppingListEntry, error) {
+	i := strings.LastIndex(arg, ":")
+	if i < 0 {
+		return usecase.ShoppingListEntry{RecipeID: arg}, nil
+	}
+	factor, err := strconv.ParseFloat(arg[i+1:], 64)
+	if err != nil || factor <= 0 {
+		return usecase.ShoppingListEntry{}, fmt.Errorf("invalid factor in %q", arg)
+	}
+	return usecase.ShoppingListEntry{RecipeID: arg[:i], Factor: factor}, nil
+}
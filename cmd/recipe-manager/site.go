@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/site"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func newExportSiteCommand(opts *globalOptions) *cobra.Command {
+	var dir, title, baseURL string
+	cmd := &cobra.Command{
+		Use:   "site",
+		Short: "Render the recipes as a static website",
+		Long: "Render the recipes as static HTML pages in --dir, to publish them read-only without the server:\n" +
+			"an index of the recipes, a page per recipe with its schema.org JSON-LD, index pages of ingredients\n" +
+			"and tags, the illustrations, and a search index used by the index page. Only recipes anonymous\n" +
+			"clients may see are published.",
+		Example: "  recipe-manager export site --dir public --title \"Family recipes\" --base-url https://recipes.example.com/",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if baseURL != "" {
+				if u, err := url.Parse(baseURL); err != nil || !u.IsAbs() {
+					return fmt.Errorf("--base-url must be an absolute URL, got %q", baseURL)
+				}
+			}
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+
+			recipes, err := a.getAllRecipesUC.Execute(cmd.Context(), domain.Viewer{}, "")
+			if err != nil {
+				return err
+			}
+			stats, err := site.Build(dir, recipes, site.Options{
+				Title:   title,
+				BaseURL: baseURL,
+				Images: func(path string) ([]byte, error) {
+					return usecase.ReadIllustration(cmd.Context(), a.getImageUC, path)
+				},
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d recipes and %d images to %s\n", stats.Recipes, stats.Images, dir)
+			if stats.MissingImages > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Left out %d illustrations that could not be read\n", stats.MissingImages)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "site", "Directory to write the site to")
+	cmd.Flags().StringVar(&title, "title", "Recipes", "Title of the site")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "Absolute URL the site is published at, for the links of the JSON-LD")
+	return cmd
+}
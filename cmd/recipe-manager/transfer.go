@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/jsonld"
+)
+
+func newImportCommand(opts *globalOptions) *cobra.Command {
+	var author string
+	cmd := &cobra.Command{
+		Use:   "import <file>...",
+		Short: "Add or replace recipes from JSON or JSON-LD files",
+		Long: "Add or replace recipes from JSON files (use - for standard input). Each file holds a recipe\n" +
+			"object or an array of recipes; every imported recipe is validated and recorded as a new revision.\n" +
+			"Files with schema.org Recipes in JSON-LD, as exported or published by recipe websites, are imported\n" +
+			"too. Recipes without an ID get a new one.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+
+			var recipes []domain.Recipe
+			for _, path := range args {
+				fileRecipes, err := readRecipes(path, cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				recipes = append(recipes, fileRecipes...)
+			}
+
+			var rows [][]string
+			var revisions []domain.Revision
+			for _, recipe := range recipes {
+				revision, err := a.saveRecipeUC.Execute(cmd.Context(), cliViewer, recipe, author)
+				if err != nil {
+					return fmt.Errorf("failed to import recipe %q: %w", cmp.Or(recipe.ID, recipe.Name), err)
+				}
+				revisions = append(revisions, *revision)
+				rows = append(rows, []string{revision.Recipe.ID, revision.Recipe.Slug, recipe.Name, strconv.Itoa(revision.Number)})
+			}
+
+			if opts.output == formatJSON {
+				return writeJSON(cmd.OutOrStdout(), revisions)
+			}
+			return writeTable(cmd.OutOrStdout(), opts.output, []string{"ID", "SLUG", "NAME", "REVISION"}, rows)
+		},
+	}
+	cmd.Flags().StringVar(&author, "author", currentUser(), "Name recorded as the author of the revisions")
+	return cmd
+}
+
+func newExportCommand(opts *globalOptions) *cobra.Command {
+	var dir string
+	cmd := &cobra.Command{
+		Use:   "export [recipe-id-or-slug]...",
+		Short: "Write recipes as canonical JSON",
+		Long: "Write the given recipes, or all of them, as canonical JSON: to standard output, or to one\n" +
+			"<id>.json file per recipe with --dir.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := loadApp(opts)
+			if err != nil {
+				return err
+			}
+
+			var recipes []domain.Recipe
+			if len(args) == 0 {
+				if recipes, err = a.getAllRecipesUC.Execute(cmd.Context(), cliViewer, ""); err != nil {
+					return err
+				}
+				sort.Slice(recipes, func(i, j int) bool { return recipes[i].ID < recipes[j].ID })
+			}
+			ids, err := a.resolveRecipeIDs(cmd.Context(), args)
+			if err != nil {
+				return fmt.Errorf("failed to export: %w", err)
+			}
+			for _, id := range ids {
+				recipe, err := a.getRecipeUC.Execute(cmd.Context(), id, "", 0)
+				if err != nil {
+					return fmt.Errorf("failed to export recipe %q: %w", id, err)
+				}
+				recipes = append(recipes, *recipe)
+			}
+
+			if dir == "" {
+				if len(args) == 1 {
+					return writeJSON(cmd.OutOrStdout(), recipes[0])
+				}
+				return writeJSON(cmd.OutOrStdout(), recipes)
+			}
+
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+			for _, recipe := range recipes {
+				path := filepath.Join(dir, safeFileName(recipe.ID)+".json")
+				file, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				err = writeJSON(file, recipe)
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					return fmt.Errorf("failed to write %s: %w", path, err)
+				}
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d recipes to %s\n", len(recipes), dir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory to write one JSON file per recipe to")
+	cmd.AddCommand(newExportSiteCommand(opts))
+	return cmd
+}
+
+// readRecipes decodes a file holding either a single recipe or an array of
+// recipes, or schema.org Recipes in JSON-LD.
+func readRecipes(path string, stdin io.Reader) ([]domain.Recipe, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if filepath.Ext(path) == ".jsonld" || bytes.Contains(data, []byte(`"@type"`)) {
+		recipes, err := jsonld.Parse(data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JSON-LD in %s: %w", path, err)
+		}
+		return recipes, nil
+	}
+
+	var recipes []domain.Recipe
+	if err := json.Unmarshal(data, &recipes); err == nil {
+		return recipes, nil
+	}
+	var recipe domain.Recipe
+	if err := json.Unmarshal(data, &recipe); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON in %s: %w", path, err)
+	}
+	return []domain.Recipe{recipe}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// safeFileName turns a recipe ID into a portable file name.
+func safeFileName(id string) string {
+	return unsafeFileChars.ReplaceAllString(id, "_")
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "cli"
+}
@@ -15,28 +15,37 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/ingredients": {
-            "get": {
-                "description": "Returns all ingredients from all the recipes",
+        "/admin/reload": {
+            "post": {
+                "description": "Reads the recipe directory again, e.g. after files were edited on disk. If loading fails, the\npreviously loaded recipes are kept. The server also reloads on SIGHUP.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "admin"
                 ],
-                "summary": "List all ingredients",
+                "summary": "Reload the recipe files",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/repository.ValidationReport"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to write response",
+                        "description": "failed to reload recipes",
                         "schema": {
                             "type": "string"
                         }
@@ -44,52 +53,83 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipe/{recipeID}": {
+        "/admin/validation": {
             "get": {
-                "description": "Get a recipe by its ID. Optionally, scale ingredient quantities by specifying ` + "`" + `ingredient` + "`" + ` and ` + "`" + `quantity` + "`" + `.",
+                "description": "Lists the recipe files that failed validation when the repository was loaded. In lenient mode these\nfiles were skipped; in strict mode the server would not have started.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "admin"
                 ],
-                "summary": "Retrieve a single recipe",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Recipe ID (e.g. '123')",
-                        "name": "recipeID",
-                        "in": "path",
-                        "required": true
+                "summary": "Report invalid recipe files",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repository.ValidationReport"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Ingredient to scale (e.g. 'Flour')",
-                        "name": "ingredient",
-                        "in": "query"
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Checks the password of a user and returns a session token to send as ` + "`" + `Authorization: Bearer \u003ctoken\u003e` + "`" + `.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Log in",
+                "parameters": [
                     {
-                        "type": "number",
-                        "description": "Quantity to scale the ingredient to (e.g. '300')",
-                        "name": "quantity",
-                        "in": "query"
+                        "description": "Username and password",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Credentials"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Recipe"
+                            "$ref": "#/definitions/usecase.LoginResult"
                         }
                     },
                     "400": {
-                        "description": "invalid 'quantity' query parameter",
+                        "description": "invalid request body",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "recipe not found",
+                    "401": {
+                        "description": "invalid username or password",
                         "schema": {
                             "type": "string"
                         }
@@ -103,21 +143,63 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipes": {
+        "/auth/logout": {
+            "post": {
+                "description": "Ends the session of the bearer token of the request.",
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Log out",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/cookbook.pdf": {
             "get": {
-                "description": "Returns all recipes in the system",
+                "description": "Binds recipes into a printable book with a table of contents and an index of ingredients. The\nrecipes are those of a collection of the logged in user, or the given ones in order, or else every\nrecipe the client may see, sorted by name.",
                 "produces": [
-                    "application/json"
+                    "application/pdf"
                 ],
                 "tags": [
                     "recipes"
                 ],
-                "summary": "List all recipes",
+                "summary": "Export recipes as a PDF cookbook",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Ingredient to scale (e.g. 'Flour')",
-                        "name": "ingredient",
+                        "description": "Collection whose recipes to bind, also giving the title",
+                        "name": "collection",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Recipes to bind, in order",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Title of the cookbook",
+                        "name": "title",
                         "in": "query"
                     }
                 ],
@@ -125,91 +207,2796 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Recipe"
-                            }
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "collection and id are exclusive",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "collections need a user session",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe or collection not found",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
-                        "description": "failed to write response",
+                        "description": "internal server error",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Ingredient": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "quantity": {
-                    "type": "number"
-                },
-                "unit": {
-                    "type": "string"
+        },
+        "/export": {
+            "get": {
+                "description": "Packs the recipes the client may see in canonical JSON, their illustration files, a catalog of the\nrecipes with their owner and visibility, and a manifest with the format version and the SHA-256\nchecksum of every file. The archive can be imported back with POST /import.",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export the library as a zip archive",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "domain.Recipe": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "ingredients": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.Ingredient"
-                    }
-                },
-                "name": {
-                    "type": "string"
-                },
-                "steps": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.RecipeStep"
+        "/healthz": {
+            "get": {
+                "description": "Answers 200 as long as the process is serving requests.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "domain.RecipeIllustration": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "filepath": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+        "/households": {
+            "post": {
+                "description": "Creates a household and moves the logged in user into it, along with the recipes they own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Create a household",
+                "parameters": [
+                    {
+                        "description": "Household name",
+                        "name": "household",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HouseholdRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Household"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid household name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "domain.RecipeStep": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string"
-                },
-                "illustration": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.RecipeIllustration"
+        "/households/{householdID}/members": {
+            "post": {
+                "description": "Moves a user into the household of the logged in user, along with the recipes they own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Add a member to a household",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID",
+                        "name": "householdID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Username of the new member",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "household or user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/{name}": {
+            "get": {
+                "description": "Serves illustrations and their variants with caching headers, to clients who may see a recipe\nthey illustrate.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/webp"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Retrieve a stored image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image path (e.g. 'chocolate_cake/step1.jpg')",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "image not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/import": {
+            "post": {
+                "description": "Adds the recipes and illustrations of an archive made by GET /export, after checking its format\nversion and checksums. Recipes whose ID is taken are skipped, overwritten as a new revision, or\nrenamed to a free ID such as ` + "`" + `2-2` + "`" + `, as chosen by ` + "`" + `conflict` + "`" + `. Invalid recipes fail without stopping\nthe import. Illustrations are written to the directory of their recipe; those pointing elsewhere\nmove there with a warning. With ` + "`" + `dry_run` + "`" + `, nothing is changed and the report tells what would be.",
+                "consumes": [
+                    "application/zip"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Import a library archive",
+                "parameters": [
+                    {
+                        "enum": [
+                            "skip",
+                            "overwrite",
+                            "rename"
+                        ],
+                        "type": "string",
+                        "default": "skip",
+                        "description": "What to do with recipes whose ID is taken",
+                        "name": "conflict",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report what would change without changing anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name recorded as the author of the revisions, unless the client is authenticated",
+                        "name": "X-Author",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Zip archive made by GET /export",
+                        "name": "archive",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid library archive or conflict policy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingredients": {
+            "get": {
+                "description": "Returns all ingredients from the recipes the client may see",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "List all ingredients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the cached representation",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the cached representation",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "description": "Returns the user of the session with their household and its members.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Retrieve the logged in user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.Profile"
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/collections": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "List the collections of the logged in user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Collection"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Create a collection for the logged in user",
+                "parameters": [
+                    {
+                        "description": "Name and recipes of the collection",
+                        "name": "collection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CollectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Collection"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid collection",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/collections/{collectionID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Retrieve a collection of the logged in user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "collectionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Collection"
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "collection not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Replace the name and recipes of a collection of the logged in user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "collectionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Name and recipes of the collection",
+                        "name": "collection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CollectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Collection"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid collection",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "collection or recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Delete a collection of the logged in user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "collectionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "collection not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/favorites": {
+            "get": {
+                "description": "Returns the favorite recipes in the order they were added, leaving out recipes no longer visible.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "List the favorite recipes of the logged in user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Recipe"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/favorites/{recipeID}": {
+            "put": {
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Add a recipe to the favorites of the logged in user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Remove a recipe from the favorites of the logged in user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "user session required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Answers 200 once the recipes have loaded successfully, and 503 while starting or shutting down.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "ready",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "not ready",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipe/{recipeID}": {
+            "get": {
+                "description": "Get a recipe by its ID or its slug. A previous slug of a renamed recipe redirects to the current one.\nOptionally, scale ingredient quantities by specifying ` + "`" + `ingredient` + "`" + ` and ` + "`" + `quantity` + "`" + `.\nThe recipe is sent as JSON, rendered as Markdown or plain text for sharing, as a printable PDF\nrecipe card, or as a schema.org Recipe in JSON-LD with absolute links to the recipe and its\nillustrations, as asked by the Accept header or the ` + "`" + `format` + "`" + ` query parameter, which takes precedence.\n` + "`" + `/recipe/{recipeID}.pdf` + "`" + ` always answers the PDF recipe card.\nResponses carry ETag and Last-Modified headers; conditional requests answer 304 when unchanged.",
+                "produces": [
+                    "application/json",
+                    "text/markdown",
+                    "text/plain",
+                    "application/pdf",
+                    "application/ld+json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Retrieve a single recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ingredient to scale (e.g. 'Flour')",
+                        "name": "ingredient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Quantity to scale the ingredient to (e.g. '300')",
+                        "name": "quantity",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "markdown",
+                            "text",
+                            "pdf",
+                            "jsonld"
+                        ],
+                        "type": "string",
+                        "description": "Representation, overriding Accept",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the cached representation",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the cached representation",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Recipe"
+                        }
+                    },
+                    "301": {
+                        "description": "moved to the current slug of the recipe",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid 'quantity' query parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "none of the accepted media types is available",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Stores the recipe and records a new revision in its history. Send ` + "`" + `If-Match` + "`" + ` with the ETag\nof the recipe you edited to make sure nobody changed it in the meantime. A schema.org Recipe in\nJSON-LD, sent as ` + "`" + `application/ld+json` + "`" + `, is imported under the ID of the URL: its ingredients are\nsplit into quantity, unit and name, and links to illustrations on this server are kept.",
+                "consumes": [
+                    "application/json",
+                    "application/ld+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Create or replace a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias; an unused ID creates the recipe",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name recorded as the author of the revision, unless the client is authenticated",
+                        "name": "X-Author",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Recipe content",
+                        "name": "recipe",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Recipe"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Recipe"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Recipe"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid recipe",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope or not allowed to change the recipe",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "precondition failed: recipe has been modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "validation errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/domain.FieldError"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipe/{recipeID}.pdf": {
+            "get": {
+                "description": "Get a recipe by its ID or its slug. A previous slug of a renamed recipe redirects to the current one.\nOptionally, scale ingredient quantities by specifying ` + "`" + `ingredient` + "`" + ` and ` + "`" + `quantity` + "`" + `.\nThe recipe is sent as JSON, rendered as Markdown or plain text for sharing, as a printable PDF\nrecipe card, or as a schema.org Recipe in JSON-LD with absolute links to the recipe and its\nillustrations, as asked by the Accept header or the ` + "`" + `format` + "`" + ` query parameter, which takes precedence.\n` + "`" + `/recipe/{recipeID}.pdf` + "`" + ` always answers the PDF recipe card.\nResponses carry ETag and Last-Modified headers; conditional requests answer 304 when unchanged.",
+                "produces": [
+                    "application/json",
+                    "text/markdown",
+                    "text/plain",
+                    "application/pdf",
+                    "application/ld+json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Retrieve a single recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ingredient to scale (e.g. 'Flour')",
+                        "name": "ingredient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Quantity to scale the ingredient to (e.g. '300')",
+                        "name": "quantity",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "markdown",
+                            "text",
+                            "pdf",
+                            "jsonld"
+                        ],
+                        "type": "string",
+                        "description": "Representation, overriding Accept",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the cached representation",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the cached representation",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Recipe"
+                        }
+                    },
+                    "301": {
+                        "description": "moved to the current slug of the recipe",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid 'quantity' query parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "none of the accepted media types is available",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes": {
+            "get": {
+                "description": "Returns the recipes the client may see: public recipes and recipes nobody owns, and for logged in\nusers their own recipes and those their household may see. Each recipe carries the average rating\nand the number of times it was cooked, from its reviews.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "List all recipes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only list recipes using this ingredient (e.g. 'Flour')",
+                        "name": "ingredient",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "rating",
+                            "last_cooked"
+                        ],
+                        "type": "string",
+                        "description": "Order of the recipes",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity tag of the cached representation",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the cached representation",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RecipeSummary"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid sort order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stores a new recipe under a generated ULID, which sorts by creation time, and records its first\nrevision. The recipe gets a unique slug derived from its name unless it has one. The recipe must\nnot have an ID; use PUT /recipe/{recipeID} to choose it. A schema.org Recipe in JSON-LD, sent as\n` + "`" + `application/ld+json` + "`" + `, is imported.",
+                "consumes": [
+                    "application/json",
+                    "application/ld+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Create a recipe with a new ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name recorded as the author of the revision, unless the client is authenticated",
+                        "name": "X-Author",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Recipe content",
+                        "name": "recipe",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Recipe"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Recipe"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the new recipe"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid recipe",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "request body too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "validation errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/domain.FieldError"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/reviews": {
+            "get": {
+                "description": "Returns the ratings, comments and cook-log entries of a recipe, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "List the reviews of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Review"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Records a rating, notes, the date the recipe was cooked, the servings made and the adjustments,\ne.g. \"cooked this on Tuesday, needed 5 more minutes\". A review needs a rating, notes or a date.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Review a recipe or log cooking it",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name recorded as the author of the review, unless the client is authenticated",
+                        "name": "X-Author",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Review content",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "validation errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/domain.FieldError"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/reviews/{reviewID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Retrieve a review of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "reviewID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Review"
+                        }
+                    },
+                    "404": {
+                        "description": "review not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the rating, notes, date, servings and adjustments of a review. Only its author and admins may.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Change a review of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "reviewID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review content",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not the author of the review",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "review not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "validation errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/domain.FieldError"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Only the author of the review and admins may.",
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Delete a review of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Review ID",
+                        "name": "reviewID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not the author of the review",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "review not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/revisions": {
+            "get": {
+                "description": "Returns the append-only history of a recipe, oldest first, with a full snapshot per revision.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "List the revisions of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Revision"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/revisions/diff": {
+            "get": {
+                "description": "Returns the ingredients added, removed or changed and the steps added, removed, changed or reordered\nbetween two revisions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Compare two revisions of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Older revision number",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Newer revision number",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RecipeDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid 'from' or 'to' query parameter",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "revision not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/revisions/{revision}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Retrieve a single revision of a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number, starting at 1",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Revision"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid revision number",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "revision not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/revisions/{revision}/revert": {
+            "post": {
+                "description": "Restores the content of the given revision. The revert is recorded as a new revision.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Revert a recipe to an earlier revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number to restore",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name recorded as the author of the revision, unless the client is authenticated",
+                        "name": "X-Author",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Revision"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid revision number",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "revision not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "validation errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/domain.FieldError"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/steps/{stepID}/illustrations": {
+            "post": {
+                "description": "Accepts a JPEG, PNG, GIF or WebP picture as multipart form data. The content type is detected from\nthe file itself. A thumbnail and WebP variants are generated and the illustration is added to the step.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Upload an illustration for a recipe step",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Step ID (e.g. 'step1')",
+                        "name": "stepID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Picture to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Description of the picture",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name recorded as the author of the revision, unless the client is authenticated",
+                        "name": "X-Author",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RecipeIllustration"
+                        }
+                    },
+                    "400": {
+                        "description": "missing 'file' form field",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "upload too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported image format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{recipeID}/visibility": {
+            "put": {
+                "description": "Sets a recipe private, household or public. Only its owner and admins may. Admins may also hand\nthe recipe to another user with owner_id; recipes nobody owns need one to change their visibility.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Change who may see a recipe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recipe ID, slug or alias (e.g. 'creme-brulee')",
+                        "name": "recipeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New visibility",
+                        "name": "visibility",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.VisibilityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RecipeAccess"
+                        }
+                    },
+                    "400": {
+                        "description": "unknown visibility or owner, or recipe without owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not the owner of the recipe or not an admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "recipe not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "description": "Creates a user with a username of 3 to 32 lowercase letters, digits, '.', '_' or '-' and a password\nof at least 8 characters. Needs the admin scope unless registration is open.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "description": "Username and password",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid username or password",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "authentication required",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "missing scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "username already taken",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Collection": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "recipe_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Household": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Ingredient": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.IngredientChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/domain.Ingredient"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "to": {
+                    "$ref": "#/definitions/domain.Ingredient"
+                }
+            }
+        },
+        "domain.Nutrition": {
+            "type": "object",
+            "properties": {
+                "calories": {
+                    "type": "number"
+                },
+                "carbohydrates": {
+                    "type": "number"
+                },
+                "cholesterol": {
+                    "type": "number"
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "saturated_fat": {
+                    "type": "number"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "sugar": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Recipe": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Aliases are the previous slugs of a renamed recipe, which redirect to\nthe current one.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "category": {
+                    "description": "Category groups recipes, e.g. \"desserts/cakes\". It may be set from the\nfolder the recipe file is in.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Ingredient"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nutrition": {
+                    "$ref": "#/definitions/domain.Nutrition"
+                },
+                "servings": {
+                    "description": "Servings is how many people the recipe feeds, if known.",
+                    "type": "number"
+                },
+                "slug": {
+                    "description": "Slug identifies the recipe in URLs, e.g. \"creme-brulee\". It is unique\nand derived from the name unless set.",
+                    "type": "string"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecipeStep"
+                    }
+                },
+                "tags": {
+                    "description": "Tags classify the recipe, e.g. \"dessert\" or \"vegetarian\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "times": {
+                    "description": "Times and Nutrition are left out when unknown.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Times"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.RecipeAccess": {
+            "type": "object",
+            "properties": {
+                "household_id": {
+                    "description": "HouseholdID is the household the owner belongs to now. It is not\nstored: repositories look it up when reading, so that household-visible\nrecipes follow their owner from one household to the next.",
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "recipe_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/domain.Visibility"
+                }
+            }
+        },
+        "domain.RecipeDiff": {
+            "type": "object",
+            "properties": {
+                "from_revision": {
+                    "type": "integer"
+                },
+                "ingredients_added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Ingredient"
+                    }
+                },
+                "ingredients_changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.IngredientChange"
+                    }
+                },
+                "ingredients_removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Ingredient"
+                    }
+                },
+                "name": {
+                    "$ref": "#/definitions/domain.ValueChange"
+                },
+                "steps_added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecipeStep"
+                    }
+                },
+                "steps_changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.StepChange"
+                    }
+                },
+                "steps_removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecipeStep"
+                    }
+                },
+                "steps_reordered": {
+                    "type": "boolean"
+                },
+                "to_revision": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.RecipeIllustration": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "filepath": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "variants": {
+                    "description": "Variants maps a variant name (e.g. VariantThumbnail) to its path.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.RecipeStep": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "illustration": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecipeIllustration"
+                    }
+                },
+                "instructions": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RecipeSummary": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Aliases are the previous slugs of a renamed recipe, which redirect to\nthe current one.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 },
-                "instructions": {
+                "average_rating": {
+                    "description": "AverageRating is the mean of the rated reviews, or zero without any.",
+                    "type": "number"
+                },
+                "category": {
+                    "description": "Category groups recipes, e.g. \"desserts/cakes\". It may be set from the\nfolder the recipe file is in.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Ingredient"
+                    }
+                },
+                "last_cooked": {
+                    "description": "LastCooked is the latest CookedOn date, as YYYY-MM-DD.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nutrition": {
+                    "$ref": "#/definitions/domain.Nutrition"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "servings": {
+                    "description": "Servings is how many people the recipe feeds, if known.",
+                    "type": "number"
+                },
+                "slug": {
+                    "description": "Slug identifies the recipe in URLs, e.g. \"creme-brulee\". It is unique\nand derived from the name unless set.",
+                    "type": "string"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecipeStep"
+                    }
+                },
+                "tags": {
+                    "description": "Tags classify the recipe, e.g. \"dessert\" or \"vegetarian\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "times": {
+                    "description": "Times and Nutrition are left out when unknown.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Times"
+                        }
+                    ]
+                },
+                "times_cooked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Review": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "description": "Adjustments lists the changes made to the recipe, e.g. \"bake 5 minutes longer\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "author": {
+                    "type": "string"
+                },
+                "cooked_on": {
+                    "description": "CookedOn is the date the recipe was cooked, as YYYY-MM-DD.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "recipe_id": {
+                    "type": "string"
+                },
+                "servings": {
+                    "description": "Servings is how many servings were made.",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "UserID is the user who wrote the review, empty for clients that are\nnot logged in as a user.",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Revision": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "recipe": {
+                    "$ref": "#/definitions/domain.Recipe"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.StepChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/domain.RecipeStep"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "to": {
+                    "$ref": "#/definitions/domain.RecipeStep"
+                }
+            }
+        },
+        "domain.Times": {
+            "type": "object",
+            "properties": {
+                "cook": {
+                    "type": "integer"
+                },
+                "prep": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "household_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ValueChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Visibility": {
+            "type": "string",
+            "enum": [
+                "private",
+                "household",
+                "public"
+            ],
+            "x-enum-varnames": [
+                "VisibilityPrivate",
+                "VisibilityHousehold",
+                "VisibilityPublic"
+            ]
+        },
+        "handlers.CollectionRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "recipe_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.Credentials": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.HouseholdRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MemberRequest": {
+            "type": "object",
+            "properties": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ReviewRequest": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cooked_on": {
+                    "description": "CookedOn is the date the recipe was cooked, as YYYY-MM-DD.",
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "rating": {
+                    "description": "Rating from 1 to 5, or 0 for a review that is not rated.",
+                    "type": "integer"
+                },
+                "servings": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.VisibilityRequest": {
+            "type": "object",
+            "properties": {
+                "owner_id": {
+                    "description": "OwnerID hands the recipe to another user; only admins may set it.",
+                    "type": "string"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/domain.Visibility"
+                }
+            }
+        },
+        "repository.FileReport": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "recipe_id": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "repository.ValidationReport": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.FileReport"
+                    }
+                },
+                "loaded": {
+                    "type": "integer"
+                },
+                "outdated": {
+                    "description": "Outdated counts the loaded files in an older schema version, which\nwere migrated in memory and are rewritten by the migrate command.",
+                    "type": "integer"
+                },
+                "strict": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "usecase.ConflictPolicy": {
+            "type": "string",
+            "enum": [
+                "skip",
+                "overwrite",
+                "rename"
+            ],
+            "x-enum-varnames": [
+                "ConflictSkip",
+                "ConflictOverwrite",
+                "ConflictRename"
+            ]
+        },
+        "usecase.ImportReport": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "$ref": "#/definitions/usecase.ConflictPolicy"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "format_version": {
+                    "type": "integer"
+                },
+                "images": {
+                    "type": "integer"
+                },
+                "overwritten": {
+                    "type": "integer"
+                },
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/usecase.ImportedRecipe"
+                    }
+                },
+                "renamed": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "usecase.ImportedRecipe": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Errors lists why an invalid recipe failed; Error why another failed.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "id": {
                     "type": "string"
                 },
+                "images": {
+                    "description": "Images counts the illustration files written.",
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
+                },
+                "new_id": {
+                    "description": "NewID is the ID a renamed recipe was imported under.",
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "usecase.LoginResult": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.User"
+                }
+            }
+        },
+        "usecase.Profile": {
+            "type": "object",
+            "properties": {
+                "household": {
+                    "$ref": "#/definitions/domain.Household"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.User"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.User"
                 }
             }
         }
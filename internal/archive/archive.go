@@ -0,0 +1,368 @@
+// Package archive reads and writes the whole library as a zip archive, to
+// back it up or move it to another installation. An archive holds:
+//
+//	manifest.json        format version, counts and checksums of every other file
+//	catalog.json         an entry per recipe: its file, illustrations and access
+//	recipes/<id>.json    each recipe in canonical JSON
+//	images/<path>        the illustration files and their variants
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// Format identifies library archives in their manifest.
+const Format = "recipe-manager-library"
+
+// Version is the version of the archive format written. Readers accept
+// archives up to this version.
+const Version = 1
+
+// Names of the files of an archive.
+const (
+	ManifestName = "manifest.json"
+	CatalogName  = "catalog.json"
+	recipesDir   = "recipes/"
+	imagesDir    = "images/"
+)
+
+// imagePrefix is the path of illustrations in recipes.
+const imagePrefix = "/images/"
+
+// MaxUncompressedSize bounds the size of the files of an archive once
+// uncompressed, so that small archives cannot fill the memory.
+const MaxUncompressedSize = 1 << 30
+
+var (
+	ErrInvalidArchive     = errors.New("invalid library archive")
+	ErrUnsupportedVersion = errors.New("unsupported library archive version")
+	ErrChecksum           = errors.New("library archive checksum mismatch")
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Recipes   int       `json:"recipes"`
+	Images    int       `json:"images"`
+	// Checksums maps the name of every other file of the archive to its
+	// SHA-256 digest, in hexadecimal.
+	Checksums map[string]string `json:"checksums"`
+}
+
+// Catalog lists the recipes of an archive.
+type Catalog struct {
+	Recipes []CatalogEntry `json:"recipes"`
+}
+
+// CatalogEntry describes a recipe of an archive.
+type CatalogEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// File is the name of the recipe in the archive, e.g. recipes/2.json.
+	File string   `json:"file"`
+	Tags []string `json:"tags,omitempty"`
+	// Images lists the paths of the illustrations of the recipe included
+	// in the archive, e.g. /images/chocolate_cake/step1.jpg.
+	Images []string `json:"images,omitempty"`
+	// Access is who owns the recipe and who may see it, or nil if nobody
+	// owns it.
+	Access *domain.RecipeAccess `json:"access,omitempty"`
+}
+
+// Library is what an archive holds.
+type Library struct {
+	Recipes []domain.Recipe
+	// Access maps the IDs of owned recipes to their access.
+	Access map[string]domain.RecipeAccess
+	// Images loads illustrations by their path in recipes, e.g.
+	// /images/chocolate_cake/step1.jpg. Nil leaves them out.
+	Images func(path string) ([]byte, error)
+}
+
+// Stats counts what was written.
+type Stats struct {
+	Recipes int
+	Images  int
+	// MissingImages counts the illustrations that could not be loaded,
+	// and are left out of the archive.
+	MissingImages int
+}
+
+// Write writes library as an archive created at now. Recipes are written in
+// the order given.
+func Write(w io.Writer, library Library, now time.Time) (Stats, error) {
+	var stats Stats
+	zw := zip.NewWriter(w)
+	manifest := Manifest{Format: Format, Version: Version, CreatedAt: now.UTC(), Checksums: make(map[string]string)}
+	add := func(name string, data []byte) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		manifest.Checksums[name] = hex.EncodeToString(sum[:])
+		return nil
+	}
+
+	catalog := Catalog{Recipes: make([]CatalogEntry, 0, len(library.Recipes))}
+	files := fileNames(library.Recipes)
+	written := make(map[string]bool)
+	for _, recipe := range library.Recipes {
+		entry := CatalogEntry{ID: recipe.ID, Name: recipe.Name, File: files[recipe.ID], Tags: recipe.Tags}
+		if access, ok := library.Access[recipe.ID]; ok {
+			entry.Access = &access
+		}
+		data, err := canonicalJSON(recipe)
+		if err != nil {
+			return stats, fmt.Errorf("failed to encode recipe %s: %w", recipe.ID, err)
+		}
+		if err := add(entry.File, data); err != nil {
+			return stats, err
+		}
+		stats.Recipes++
+
+		for _, imagePath := range Illustrations(recipe) {
+			name, ok := imageName(imagePath)
+			if !ok || library.Images == nil {
+				stats.MissingImages++
+				continue
+			}
+			if !written[name] {
+				data, err := library.Images(imagePath)
+				if err != nil {
+					stats.MissingImages++
+					continue
+				}
+				if err := add(name, data); err != nil {
+					return stats, err
+				}
+				written[name] = true
+				stats.Images++
+			}
+			entry.Images = append(entry.Images, imagePath)
+		}
+		catalog.Recipes = append(catalog.Recipes, entry)
+	}
+
+	data, err := canonicalJSON(catalog)
+	if err != nil {
+		return stats, err
+	}
+	if err := add(CatalogName, data); err != nil {
+		return stats, err
+	}
+	manifest.Recipes, manifest.Images = stats.Recipes, stats.Images
+	if data, err = canonicalJSON(manifest); err != nil {
+		return stats, err
+	}
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: ManifestName, Method: zip.Deflate, Modified: now})
+	if err != nil {
+		return stats, err
+	}
+	if _, err := f.Write(data); err != nil {
+		return stats, err
+	}
+	if err := zw.Close(); err != nil {
+		return stats, fmt.Errorf("failed to write library archive: %w", err)
+	}
+	return stats, nil
+}
+
+// Read reads an archive written by Write, checking its version and the
+// checksums of its files. The images of the library are read from memory.
+func Read(data []byte) (Manifest, Library, error) {
+	var manifest Manifest
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return manifest, Library{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	files := make(map[string][]byte, len(zr.File))
+	var total uint64
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		if total += f.UncompressedSize64; total > MaxUncompressedSize {
+			return manifest, Library{}, fmt.Errorf("%w: larger than %d bytes uncompressed", ErrInvalidArchive, MaxUncompressedSize)
+		}
+		if _, ok := files[f.Name]; ok {
+			return manifest, Library{}, fmt.Errorf("%w: %s is duplicated", ErrInvalidArchive, f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return manifest, Library{}, fmt.Errorf("%w: %s: %w", ErrInvalidArchive, f.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, int64(f.UncompressedSize64)+1))
+		rc.Close()
+		if err != nil {
+			return manifest, Library{}, fmt.Errorf("%w: %s: %w", ErrInvalidArchive, f.Name, err)
+		}
+		if uint64(len(content)) > f.UncompressedSize64 {
+			return manifest, Library{}, fmt.Errorf("%w: %s is larger than declared", ErrInvalidArchive, f.Name)
+		}
+		files[f.Name] = content
+	}
+
+	if err := decode(files, ManifestName, &manifest); err != nil {
+		return manifest, Library{}, err
+	}
+	if manifest.Format != Format {
+		return manifest, Library{}, fmt.Errorf("%w: not a %s archive", ErrInvalidArchive, Format)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return manifest, Library{}, fmt.Errorf("%w %d, this version reads up to %d", ErrUnsupportedVersion, manifest.Version, Version)
+	}
+	for name, content := range files {
+		if name == ManifestName {
+			continue
+		}
+		want, ok := manifest.Checksums[name]
+		if !ok {
+			return manifest, Library{}, fmt.Errorf("%w: %s is not in the manifest", ErrInvalidArchive, name)
+		}
+		if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != want {
+			return manifest, Library{}, fmt.Errorf("%w: %s", ErrChecksum, name)
+		}
+	}
+	for _, name := range sortedKeys(manifest.Checksums) {
+		if _, ok := files[name]; !ok {
+			return manifest, Library{}, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, name)
+		}
+	}
+
+	var catalog Catalog
+	if err := decode(files, CatalogName, &catalog); err != nil {
+		return manifest, Library{}, err
+	}
+	library := Library{Access: make(map[string]domain.RecipeAccess)}
+	seen := make(map[string]bool, len(catalog.Recipes))
+	for _, entry := range catalog.Recipes {
+		var recipe domain.Recipe
+		if err := decode(files, entry.File, &recipe); err != nil {
+			return manifest, Library{}, err
+		}
+		if recipe.ID != entry.ID {
+			return manifest, Library{}, fmt.Errorf("%w: %s holds recipe %q, not %q", ErrInvalidArchive, entry.File, recipe.ID, entry.ID)
+		}
+		if seen[recipe.ID] {
+			return manifest, Library{}, fmt.Errorf("%w: recipe %q is duplicated", ErrInvalidArchive, recipe.ID)
+		}
+		seen[recipe.ID] = true
+		library.Recipes = append(library.Recipes, recipe)
+		if entry.Access != nil {
+			access := *entry.Access
+			access.RecipeID = recipe.ID
+			library.Access[recipe.ID] = access
+		}
+	}
+	library.Images = func(imagePath string) ([]byte, error) {
+		name, ok := imageName(imagePath)
+		if !ok {
+			return nil, fmt.Errorf("invalid image path %q", imagePath)
+		}
+		content, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", imagePath, fs.ErrNotExist)
+		}
+		return content, nil
+	}
+	return manifest, library, nil
+}
+
+// Illustrations lists the paths of the illustrations of a recipe and of
+// their variants, without duplicates.
+func Illustrations(recipe domain.Recipe) []string {
+	var paths []string
+	for _, step := range recipe.Steps {
+		for _, illustration := range step.RecipeIllustration {
+			candidates := []string{illustration.Filepath}
+			for _, variant := range sortedKeys(illustration.Variants) {
+				candidates = append(candidates, illustration.Variants[variant])
+			}
+			for _, p := range candidates {
+				if p != "" && !slices.Contains(paths, p) {
+					paths = append(paths, p)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// imageName returns the name of an illustration in archives, e.g.
+// images/chocolate_cake/step1.jpg, for paths of stored illustrations.
+func imageName(imagePath string) (string, bool) {
+	name, ok := strings.CutPrefix(imagePath, imagePrefix)
+	if !ok || !fs.ValidPath(name) {
+		return "", false
+	}
+	return imagesDir + name, true
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// fileNames names the file of each recipe after its ID, made unique.
+func fileNames(recipes []domain.Recipe) map[string]string {
+	names := make(map[string]string, len(recipes))
+	used := make(map[string]bool, len(recipes))
+	for _, recipe := range recipes {
+		base := strings.ToLower(unsafeFileChars.ReplaceAllString(recipe.ID, "_"))
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[name] = true
+		names[recipe.ID] = path.Join(recipesDir, name+".json")
+	}
+	return names
+}
+
+// canonicalJSON encodes v as indented JSON, as recipe files are written.
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func decode(files map[string][]byte, name string, v any) error {
+	content, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: %s is missing", ErrInvalidArchive, name)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidArchive, name, err)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
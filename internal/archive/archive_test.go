@@ -0,0 +1,154 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+func testLibrary() Library {
+	images := map[string][]byte{
+		"/images/2/step1.jpg":       []byte("jpeg"),
+		"/images/2/step1_thumb.jpg": []byte("thumbnail"),
+	}
+	return Library{
+		Recipes: []domain.Recipe{
+			{ID: "1", Name: "Pancakes", Ingredients: []domain.Ingredient{}, Steps: []domain.RecipeStep{}},
+			{ID: "2", Name: "Chocolate Cake", Tags: []string{"dessert"}, Ingredients: []domain.Ingredient{}, Steps: []domain.RecipeStep{{
+				ID: "step1",
+				RecipeIllustration: []domain.RecipeIllustration{
+					{ID: "i1", Filepath: "/images/2/step1.jpg", Variants: map[string]string{domain.VariantThumbnail: "/images/2/step1_thumb.jpg"}},
+					{ID: "i2", Filepath: "/images/2/lost.jpg"},
+				},
+			}}},
+		},
+		Access: map[string]domain.RecipeAccess{"2": {RecipeID: "2", OwnerID: "alice", Visibility: domain.VisibilityPrivate}},
+		Images: func(path string) ([]byte, error) {
+			if data, ok := images[path]; ok {
+				return data, nil
+			}
+			return nil, fs.ErrNotExist
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	library := testLibrary()
+	var buf bytes.Buffer
+	stats, err := Write(&buf, library, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats != (Stats{Recipes: 2, Images: 2, MissingImages: 1}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	manifest, read, err := Read(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manifest.Version != Version || manifest.Recipes != 2 || manifest.Images != 2 || len(manifest.Checksums) != 5 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if !reflect.DeepEqual(read.Recipes, library.Recipes) || !reflect.DeepEqual(read.Access, library.Access) {
+		t.Errorf("expected the library back, got %+v", read)
+	}
+	if data, err := read.Images("/images/2/step1_thumb.jpg"); err != nil || string(data) != "thumbnail" {
+		t.Errorf("expected the thumbnail, got %q, %v", data, err)
+	}
+	if _, err := read.Images("/images/2/lost.jpg"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing images to be reported, got %v", err)
+	}
+}
+
+// rewrite copies an archive, changing the content of files with edit.
+func rewrite(t *testing.T, data []byte, edit func(name string, content []byte) []byte) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content = edit(f.Name, content); content == nil {
+			continue
+		}
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRead_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Write(&buf, testLibrary(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	tests := []struct {
+		name string
+		edit func(name string, content []byte) []byte
+		want error
+	}{
+		{"tampered recipe", func(name string, content []byte) []byte {
+			if name == "recipes/1.json" {
+				return bytes.Replace(content, []byte("Pancakes"), []byte("Waffles"), 1)
+			}
+			return content
+		}, ErrChecksum},
+		{"missing image", func(name string, content []byte) []byte {
+			if strings.HasPrefix(name, "images/") {
+				return nil
+			}
+			return content
+		}, ErrInvalidArchive},
+		{"newer version", func(name string, content []byte) []byte {
+			if name == ManifestName {
+				return bytes.Replace(content, []byte(`"version": 1`), []byte(`"version": 99`), 1)
+			}
+			return content
+		}, ErrUnsupportedVersion},
+		{"no manifest", func(name string, content []byte) []byte {
+			if name == ManifestName {
+				return nil
+			}
+			return content
+		}, ErrInvalidArchive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Read(rewrite(t, valid, tt.edit)); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, _, err := Read([]byte("not a zip")); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("expected ErrInvalidArchive, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package auth authenticates API clients with static API keys or JWTs and
+// describes the scopes they are granted.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scopes granted to clients. Admin implies the other scopes.
+const (
+	ScopeRead  = "recipes:read"
+	ScopeWrite = "recipes:write"
+	ScopeAdmin = "admin"
+)
+
+// APIKeyHeader carries an API key; keys are also accepted as bearer tokens.
+const APIKeyHeader = "X-API-Key"
+
+// ErrInvalidCredentials is returned for an unknown API key or a JWT that is
+// malformed, badly signed, expired or meant for another audience.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Principal is an authenticated client.
+type Principal struct {
+	// Subject names the client: the name of its API key, the sub claim of
+	// its JWT or the username of a logged in user.
+	Subject string
+	Scopes  []string
+	// UserID and HouseholdID are set for users logged in with a session token.
+	UserID      string
+	HouseholdID string
+}
+
+// HasScope reports whether the principal was granted scope, directly or
+// through the admin scope.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// APIKey is a static key granting scopes to the client presenting it.
+type APIKey struct {
+	Name   string
+	Key    string
+	Scopes []string
+}
+
+// Options configures an Authenticator. At least one of APIKeys, JWTSecret,
+// JWKSFile and Sessions must be set.
+type Options struct {
+	APIKeys []APIKey
+	// JWTSecret verifies HS256 JWTs.
+	JWTSecret []byte
+	// JWKSFile is a JWKS file holding the RSA public keys verifying RS256 JWTs.
+	JWKSFile string
+	// Issuer and Audience, when set, must match the iss and aud claims of JWTs.
+	Issuer   string
+	Audience string
+	// Sessions resolves the session tokens of logged in users.
+	Sessions SessionResolver
+}
+
+// Authenticator checks the credentials presented with requests.
+type Authenticator struct {
+	apiKeys  []APIKey
+	secret   []byte
+	keys     map[string]*rsa.PublicKey // key ID -> RS256 verification key
+	issuer   string
+	audience string
+	sessions SessionResolver
+	now      func() time.Time
+}
+
+// New returns an Authenticator accepting the given keys and JWTs.
+func New(opts Options) (*Authenticator, error) {
+	if len(opts.APIKeys) == 0 && len(opts.JWTSecret) == 0 && opts.JWKSFile == "" && opts.Sessions == nil {
+		return nil, errors.New("no API keys, JWT secret, JWKS file or sessions configured")
+	}
+	a := &Authenticator{
+		apiKeys:  opts.APIKeys,
+		secret:   opts.JWTSecret,
+		issuer:   opts.Issuer,
+		audience: opts.Audience,
+		sessions: opts.Sessions,
+		now:      time.Now,
+	}
+	for _, key := range opts.APIKeys {
+		if key.Name == "" || key.Key == "" {
+			return nil, errors.New("API keys need a name and a key")
+		}
+		if err := checkScopes(key.Scopes); err != nil {
+			return nil, fmt.Errorf("invalid API key %s: %w", key.Name, err)
+		}
+	}
+	if opts.JWKSFile != "" {
+		keys, err := LoadJWKS(opts.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+	}
+	return a, nil
+}
+
+func checkScopes(scopes []string) error {
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeRead, ScopeWrite, ScopeAdmin:
+		default:
+			return fmt.Errorf("unknown scope %q, expected %s, %s or %s", scope, ScopeRead, ScopeWrite, ScopeAdmin)
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the principal identified by the X-API-Key header or
+// the bearer token of r, or nil if r carries no credentials. Bearer tokens
+// shaped like a JWT are verified as such, session tokens are resolved and
+// others are looked up as API keys.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return nil, nil
+	}
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, fmt.Errorf("%w: expected a bearer token", ErrInvalidCredentials)
+	}
+	switch {
+	case strings.Count(token, ".") == 2:
+		return a.authenticateJWT(token)
+	case strings.HasPrefix(token, sessionTokenPrefix) && a.sessions != nil:
+		return a.authenticateSession(r.Context(), token)
+	}
+	return a.authenticateAPIKey(token)
+}
+
+func (a *Authenticator) authenticateAPIKey(key string) (*Principal, error) {
+	// Compare every key in constant time, so that timing does not tell how
+	// much of a key was guessed right.
+	var found *APIKey
+	for i := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(a.apiKeys[i].Key), []byte(key)) == 1 {
+			found = &a.apiKeys[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	return &Principal{Subject: found.Name, Scopes: found.Scopes}, nil
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal stored in ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
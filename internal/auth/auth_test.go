@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to encode JWT segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret []byte, claims map[string]any) string {
+	t.Helper()
+	unsigned := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	unsigned := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign JWT: %v", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+	jwks := map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatalf("failed to encode JWKS: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func authenticate(t *testing.T, a *Authenticator, header, value string) (*Principal, error) {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/recipes", nil)
+	if header != "" {
+		r.Header.Set(header, value)
+	}
+	return a.Authenticate(r)
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	a, err := New(Options{APIKeys: []APIKey{
+		{Name: "reader", Key: "read-key", Scopes: []string{ScopeRead}},
+		{Name: "ops", Key: "admin-key", Scopes: []string{ScopeAdmin}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	principal, err := authenticate(t, a, APIKeyHeader, "read-key")
+	if err != nil || principal.Subject != "reader" {
+		t.Fatalf("expected the reader key, got %+v, %v", principal, err)
+	}
+	if !principal.HasScope(ScopeRead) || principal.HasScope(ScopeWrite) {
+		t.Errorf("unexpected scopes %v", principal.Scopes)
+	}
+
+	principal, err = authenticate(t, a, "Authorization", "Bearer admin-key")
+	if err != nil || principal.Subject != "ops" {
+		t.Fatalf("expected the admin key as a bearer token, got %+v, %v", principal, err)
+	}
+	if !principal.HasScope(ScopeWrite) {
+		t.Error("expected admin to imply recipes:write")
+	}
+
+	if _, err := authenticate(t, a, APIKeyHeader, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected invalid credentials, got %v", err)
+	}
+	if principal, err := authenticate(t, a, "", ""); principal != nil || err != nil {
+		t.Errorf("expected no principal without credentials, got %+v, %v", principal, err)
+	}
+}
+
+func TestNew_UnknownScope(t *testing.T) {
+	_, err := New(Options{APIKeys: []APIKey{{Name: "ci", Key: "k", Scopes: []string{"recipes:delete"}}}})
+	if err == nil {
+		t.Error("expected an error for an unknown scope")
+	}
+}
+
+func TestAuthenticate_HS256(t *testing.T) {
+	secret := []byte("shared-secret")
+	now := time.Unix(1_700_000_000, 0)
+	a, err := New(Options{JWTSecret: secret, Issuer: "https://id.example.com", Audience: "recipe-manager"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.now = func() time.Time { return now }
+
+	valid := map[string]any{
+		"sub":   "alice",
+		"iss":   "https://id.example.com",
+		"aud":   []string{"recipe-manager"},
+		"exp":   now.Add(time.Hour).Unix(),
+		"scope": "recipes:read recipes:write",
+	}
+	principal, err := authenticate(t, a, "Authorization", "Bearer "+signHS256(t, secret, valid))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if principal.Subject != "alice" || !principal.HasScope(ScopeWrite) || principal.HasScope(ScopeAdmin) {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+
+	with := func(key string, value any) map[string]any {
+		claims := make(map[string]any)
+		for k, v := range valid {
+			claims[k] = v
+		}
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"bad signature", signHS256(t, []byte("other-secret"), valid)},
+		{"expired", signHS256(t, secret, with("exp", now.Add(-time.Hour).Unix()))},
+		{"no expiry", signHS256(t, secret, with("exp", nil))},
+		{"not yet valid", signHS256(t, secret, with("nbf", now.Add(time.Hour).Unix()))},
+		{"wrong issuer", signHS256(t, secret, with("iss", "https://evil.example.com"))},
+		{"wrong audience", signHS256(t, secret, with("aud", "other-service"))},
+		{"unsigned", encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, valid) + "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authenticate(t, a, "Authorization", "Bearer "+tt.token)
+			if !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("expected invalid credentials, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	a, err := New(Options{JWKSFile: writeJWKS(t, "key-1", &key.PublicKey)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims := map[string]any{
+		"sub": "bob",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"scp": []string{ScopeRead},
+	}
+	principal, err := authenticate(t, a, "Authorization", "Bearer "+signRS256(t, key, "key-1", claims))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if principal.Subject != "bob" || !principal.HasScope(ScopeRead) {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	for kid, token := range map[string]string{
+		"key-1": signRS256(t, other, "key-1", claims),
+		"key-2": signRS256(t, key, "key-2", claims),
+	} {
+		if _, err := authenticate(t, a, "Authorization", "Bearer "+token); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("expected invalid credentials for %s, got %v", kid, err)
+		}
+	}
+
+	// HS256 tokens are refused when no secret is configured, even if signed
+	// with the public key.
+	publicKey := []byte(fmt.Sprint(key.PublicKey.N))
+	if _, err := authenticate(t, a, "Authorization", "Bearer "+signHS256(t, publicKey, claims)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected HS256 to be refused, got %v", err)
+	}
+}
+
+// fakeSessions maps session tokens to principals.
+type fakeSessions map[string]*Principal
+
+func (f fakeSessions) ResolveSession(ctx context.Context, token string) (*Principal, error) {
+	principal, ok := f[token]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return principal, nil
+}
+
+func TestAuthenticate_Session(t *testing.T) {
+	token, hash, err := NewSessionToken()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash != HashSessionToken(token) {
+		t.Error("expected the hash of the new token")
+	}
+	alice := &Principal{Subject: "alice", Scopes: []string{ScopeRead, ScopeWrite}, UserID: "u1"}
+	a, err := New(Options{Sessions: fakeSessions{token: alice}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	principal, err := authenticate(t, a, "Authorization", "Bearer "+token)
+	if err != nil || principal != alice {
+		t.Errorf("expected alice, got %+v, %v", principal, err)
+	}
+	if _, err := authenticate(t, a, "Authorization", "Bearer rms_unknown"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials for an unknown session, got %v", err)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	if _, err := HashPassword("short"); err == nil {
+		t.Error("expected short passwords to be refused")
+	}
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !CheckPassword(hash, "correct horse") || CheckPassword(hash, "wrong horse") || CheckPassword("", "correct horse") {
+		t.Error("expected only the right password to match")
+	}
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is the tolerance applied to the exp and nbf claims.
+const clockSkew = time.Minute
+
+// claims are the JWT claims read by the Authenticator. Scopes are read from
+// the space-separated scope claim, or from the scp claim.
+type claims struct {
+	Subject   string     `json:"sub"`
+	Issuer    string     `json:"iss"`
+	Audience  stringList `json:"aud"`
+	ExpiresAt *float64   `json:"exp"`
+	NotBefore *float64   `json:"nbf"`
+	Scope     string     `json:"scope"`
+	Scp       stringList `json:"scp"`
+}
+
+// stringList decodes a claim holding either a string or a list of strings.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = strings.Fields(single)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// authenticateJWT verifies the signature and the claims of a compact JWT.
+// Only HS256 and RS256 are accepted, and tokens must expire.
+func (a *Authenticator) authenticateJWT(token string) (*Principal, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidCredentials, fmt.Sprintf(format, args...))
+	}
+
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalid("malformed JWT header: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed JWT signature: %v", err)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case "HS256":
+		if len(a.secret) == 0 {
+			return nil, invalid("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, a.secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return nil, invalid("bad JWT signature")
+		}
+	case "RS256":
+		key, err := a.verificationKey(header.Kid)
+		if err != nil {
+			return nil, invalid("%v", err)
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, invalid("bad JWT signature")
+		}
+	default:
+		return nil, invalid("unsupported JWT algorithm %q", header.Alg)
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, invalid("malformed JWT claims: %v", err)
+	}
+	now := a.now()
+	if c.ExpiresAt == nil {
+		return nil, invalid("JWT has no exp claim")
+	}
+	if now.After(unixTime(*c.ExpiresAt).Add(clockSkew)) {
+		return nil, invalid("JWT expired")
+	}
+	if c.NotBefore != nil && now.Add(clockSkew).Before(unixTime(*c.NotBefore)) {
+		return nil, invalid("JWT not valid yet")
+	}
+	if a.issuer != "" && c.Issuer != a.issuer {
+		return nil, invalid("unexpected JWT issuer %q", c.Issuer)
+	}
+	if a.audience != "" && !slices.Contains(c.Audience, a.audience) {
+		return nil, invalid("JWT not meant for audience %q", a.audience)
+	}
+	if c.Subject == "" {
+		return nil, invalid("JWT has no sub claim")
+	}
+
+	scopes := strings.Fields(c.Scope)
+	if len(scopes) == 0 {
+		scopes = c.Scp
+	}
+	return &Principal{Subject: c.Subject, Scopes: scopes}, nil
+}
+
+// verificationKey returns the RS256 key named kid, or the only key of the
+// JWKS file when the token names none.
+func (a *Authenticator) verificationKey(kid string) (*rsa.PublicKey, error) {
+	if len(a.keys) == 0 {
+		return nil, errors.New("RS256 tokens are not accepted")
+	}
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, nil
+		}
+	}
+	key, ok := a.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown JWT key ID %q", kid)
+	}
+	return key, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// LoadJWKS reads the RSA signing keys of a JWKS file, indexed by key ID.
+// Keys of other types or meant for encryption are ignored.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file %s: %w", path, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != "RS256") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q in %s: %w", jwk.Kid, path, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid exponent of key %q in %s", jwk.Kid, path)
+		}
+		exponent := 0
+		for _, b := range e {
+			exponent = exponent<<8 | int(b)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no RSA signing keys in JWKS file %s", path)
+	}
+	return keys, nil
+}
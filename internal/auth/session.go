@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sessionTokenPrefix marks session tokens, so that they are recognizable in
+// logs and secret scanners.
+const sessionTokenPrefix = "rms_"
+
+// MinPasswordLength is the minimum length of a user password.
+const MinPasswordLength = 8
+
+// HashPassword returns the bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters long", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// dummyHash is checked instead of the hash of a user that does not exist,
+// so that logging in takes as long with an unknown username as with a wrong
+// password.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// CheckPassword reports whether password matches a hash from HashPassword.
+// An empty hash never matches, but takes as long to check.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewSessionToken returns a random session token for the client, and the
+// hash of it to store.
+func NewSessionToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token = sessionTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashSessionToken(token), nil
+}
+
+// HashSessionToken returns the hash under which a session token is stored.
+// Tokens are random, so a fast hash is enough.
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SessionResolver returns the principal logged in with a session token, or
+// an error wrapping ErrInvalidCredentials for an unknown or expired token.
+type SessionResolver interface {
+	ResolveSession(ctx context.Context, token string) (*Principal, error)
+}
+
+func (a *Authenticator) authenticateSession(ctx context.Context, token string) (*Principal, error) {
+	principal, err := a.sessions.ResolveSession(ctx, token)
+	if err != nil && !errors.Is(err, ErrInvalidCredentials) {
+		return nil, fmt.Errorf("failed to resolve session: %w", err)
+	}
+	return principal, err
+}
+
+// SessionToken returns the session token r carries as its bearer token, or
+// an empty string.
+func SessionToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || !strings.HasPrefix(token, sessionTokenPrefix) {
+		return ""
+	}
+	return token
+}
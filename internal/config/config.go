@@ -2,44 +2,574 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"io/fs"
+	"net"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validation modes for recipe files.
+const (
+	ValidationStrict  = "strict"
+	ValidationLenient = "lenient"
+)
+
+// Sources of the frontend served under /.
+const (
+	// FrontendAuto serves the frontend compiled into the binary, if any, and
+	// StaticPath otherwise.
+	FrontendAuto     = "auto"
+	FrontendEmbedded = "embedded"
+	FrontendDisk     = "disk"
+	FrontendNone     = "none"
+)
+
+// Stores for user accounts.
+const (
+	AccountsStoreJSON   = "json"
+	AccountsStoreSQLite = "sqlite"
+)
+
+// EnvPrefix prefixes the environment variables overriding configuration
+// keys, e.g. RECIPE_MANAGER_SERVER_PORT for server_port.
+const EnvPrefix = "RECIPE_MANAGER_"
+
+// Origins of a configuration value, from lowest to highest precedence.
+const (
+	OriginDefault = "default"
+	OriginFile    = "file"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
 )
 
 // Config holds all application configuration parameters.
 type Config struct {
-	ServerPort  string `json:"server_port"`
-	RecipesPath string `json:"recipes_path"`
+	ServerPort  string `json:"server_port" yaml:"server_port"`
+	RecipesPath string `json:"recipes_path" yaml:"recipes_path"`
+	// RecipesMaxDepth is how many levels of subdirectories of RecipesPath
+	// are read; 0 only reads RecipesPath itself.
+	RecipesMaxDepth int `json:"recipes_max_depth" yaml:"recipes_max_depth"`
+	// RecipesIgnore lists .gitignore-style patterns of files and directories
+	// of RecipesPath to skip, in addition to those of its .recipeignore file.
+	RecipesIgnore []string `json:"recipes_ignore" yaml:"recipes_ignore"`
+	// RecipesFolderCategories sets the category of the recipes in a
+	// subdirectory to its path, e.g. "desserts/cakes".
+	RecipesFolderCategories bool `json:"recipes_folder_categories" yaml:"recipes_folder_categories"`
+	// RecipesNamespaceIDs prefixes the IDs of the recipes in a subdirectory
+	// with its path, e.g. "desserts/cake", so that folders may reuse IDs.
+	RecipesNamespaceIDs bool `json:"recipes_namespace_ids" yaml:"recipes_namespace_ids"`
+	// ImagesPath is the directory where illustrations are stored and served from under /images/.
+	ImagesPath string `json:"images_path" yaml:"images_path"`
+	// FrontendSource serves the frontend "embedded" in the binary (built with
+	// -tags embedfrontend), from StaticPath on "disk" during development, or
+	// not at all with "none". "auto" prefers the embedded frontend.
+	FrontendSource string `json:"frontend_source" yaml:"frontend_source"`
+	// StaticPath is the directory of the built frontend served from disk.
+	// An empty path disables serving the frontend from disk.
+	StaticPath string `json:"static_path" yaml:"static_path"`
+	// MaxImageSize is the maximum size in bytes of an uploaded illustration.
+	MaxImageSize int64 `json:"max_image_size" yaml:"max_image_size"`
+	// ValidationMode is "strict" (an invalid recipe file prevents startup) or
+	// "lenient" (invalid files are skipped and listed at /admin/validation).
+	ValidationMode string `json:"validation_mode" yaml:"validation_mode"`
+	// ReadTimeout, WriteTimeout and IdleTimeout bound how long a client may
+	// take to send a request, to read the response and to keep an idle
+	// connection open. Zero disables the timeout.
+	ReadTimeout  Duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout  Duration `json:"idle_timeout" yaml:"idle_timeout"`
+	// RequestTimeout cancels the work of a request that takes longer, e.g.
+	// loading a recipe from storage. Zero disables the timeout.
+	RequestTimeout Duration `json:"request_timeout" yaml:"request_timeout"`
+	// ShutdownTimeout is how long in-flight requests may take to complete
+	// after SIGINT or SIGTERM before their connections are closed.
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	// LogLevel is the minimum level of the server logs: debug, info, warn or error.
+	LogLevel string `json:"log_level" yaml:"log_level"`
+	// LogFormat is "json" for one JSON object per line, or "text" for key=value lines.
+	LogFormat string `json:"log_format" yaml:"log_format"`
+	// TracingExporter sends OpenTelemetry spans to "stdout", to an "otlp"
+	// collector at TracingEndpoint, or nowhere with "none".
+	TracingExporter string `json:"tracing_exporter" yaml:"tracing_exporter"`
+	// TracingEndpoint is the host:port of the OTLP/HTTP collector.
+	TracingEndpoint string `json:"tracing_endpoint" yaml:"tracing_endpoint"`
+	// AuthEnabled requires requests to present an API key or a JWT carrying
+	// the scope of the route. When false, every route is open.
+	AuthEnabled bool `json:"auth_enabled" yaml:"auth_enabled"`
+	// AuthAnonymousRead lets requests without credentials read recipes when
+	// AuthEnabled is set; writes and admin routes still need credentials.
+	AuthAnonymousRead bool `json:"auth_anonymous_read" yaml:"auth_anonymous_read"`
+	// AuthAPIKeys lists the static API keys accepted in the X-API-Key header
+	// or as bearer tokens, with the scopes each one grants.
+	AuthAPIKeys []APIKey `json:"auth_api_keys" yaml:"auth_api_keys"`
+	// AuthJWTSecret is the shared secret verifying HS256 JWTs.
+	AuthJWTSecret Secret `json:"auth_jwt_secret" yaml:"auth_jwt_secret"`
+	// AuthJWKSFile is a local JWKS file holding the RSA public keys verifying
+	// RS256 JWTs.
+	AuthJWKSFile string `json:"auth_jwks_file" yaml:"auth_jwks_file"`
+	// AuthJWTIssuer and AuthJWTAudience, when set, must match the iss and aud
+	// claims of JWTs.
+	AuthJWTIssuer   string `json:"auth_jwt_issuer" yaml:"auth_jwt_issuer"`
+	AuthJWTAudience string `json:"auth_jwt_audience" yaml:"auth_jwt_audience"`
+	// AccountsStore keeps users, households, sessions, recipe ownership,
+	// collections and reviews in a "json" file or a "sqlite" database at
+	// AccountsPath.
+	AccountsStore string `json:"accounts_store" yaml:"accounts_store"`
+	AccountsPath  string `json:"accounts_path" yaml:"accounts_path"`
+	// SessionTTL is how long a session token stays valid after logging in.
+	SessionTTL Duration `json:"session_ttl" yaml:"session_ttl"`
+	// RegistrationOpen lets anyone create a user; otherwise creating users
+	// needs the admin scope.
+	RegistrationOpen bool `json:"registration_open" yaml:"registration_open"`
+	// CORSAllowedOrigins lists the origins browsers may call the API from,
+	// e.g. "https://recipes.example.com". "*" allows any origin and
+	// "https://*.example.com" the subdomains of a domain. An empty list
+	// refuses cross-origin requests.
+	CORSAllowedOrigins []string `json:"cors_allowed_origins" yaml:"cors_allowed_origins"`
+	// CORSAllowedMethods and CORSAllowedHeaders are what cross-origin
+	// requests may use.
+	CORSAllowedMethods []string `json:"cors_allowed_methods" yaml:"cors_allowed_methods"`
+	CORSAllowedHeaders []string `json:"cors_allowed_headers" yaml:"cors_allowed_headers"`
+	// CORSExposedHeaders are the response headers scripts may read.
+	CORSExposedHeaders []string `json:"cors_exposed_headers" yaml:"cors_exposed_headers"`
+	// CORSAllowCredentials lets browsers send cookies and Authorization
+	// headers; it needs explicit origins rather than "*".
+	CORSAllowCredentials bool `json:"cors_allow_credentials" yaml:"cors_allow_credentials"`
+	// CORSMaxAge is how long browsers may cache the answer to a preflight request.
+	CORSMaxAge Duration `json:"cors_max_age" yaml:"cors_max_age"`
+	// RateLimits limits how fast each IP address, and each authenticated
+	// client, may use a group of routes: "read", "write", "admin", or "auth"
+	// for logging in and registering. Groups not listed are unlimited.
+	RateLimits []RateLimit `json:"rate_limits" yaml:"rate_limits"`
+	// MaxBodySize is the largest request body in bytes accepted by the API,
+	// except for illustration uploads which are limited by MaxImageSize and
+	// library imports which are limited by MaxImportSize.
+	MaxBodySize int64 `json:"max_body_size" yaml:"max_body_size"`
+	// MaxImportSize is the largest library archive in bytes accepted by
+	// POST /import.
+	MaxImportSize int64 `json:"max_import_size" yaml:"max_import_size"`
 	// Add other fields as needed, e.g. database creds, logging level, etc.
+
+	// origins records where each key got its value from.
+	origins map[string]string
+}
+
+// Duration is a time.Duration written as a string such as "30s" or "1m30s".
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// Secret is a string, such as a password or a key, that is never printed.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "[redacted]"
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// APIKey is a static key granting scopes to the client presenting it. Name
+// identifies the client in logs and as the author of its changes.
+type APIKey struct {
+	Name   string   `json:"name" yaml:"name"`
+	Key    Secret   `json:"key" yaml:"key"`
+	Scopes []string `json:"scopes" yaml:"scopes"`
+}
+
+// RateLimit lets each client send RequestsPerMinute requests to the routes of
+// Group on average, and up to Burst at once.
+type RateLimit struct {
+	Group             string `json:"group" yaml:"group"`
+	RequestsPerMinute int    `json:"requests_per_minute" yaml:"requests_per_minute"`
+	Burst             int    `json:"burst" yaml:"burst"`
+}
+
+// Sources lists where Load reads configuration from. Each source overrides
+// the previous one: defaults, then File, then environment, then Flags.
+type Sources struct {
+	// File is a JSON or YAML (.yaml, .yml) config file. An empty path skips it.
+	File string
+	// FileRequired makes a missing File an error instead of being skipped.
+	FileRequired bool
+	// LookupEnv reads environment variables, usually os.LookupEnv. Nil skips them.
+	LookupEnv func(key string) (string, bool)
+	// Flags maps configuration keys to values given on the command line.
+	Flags map[string]string
+}
+
+// Field describes one configuration key.
+type Field struct {
+	Key    string `json:"key"`
+	Env    string `json:"env"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// Default returns the configuration used when no source sets a key.
+func Default() *Config {
+	cfg := &Config{
+		ServerPort:      ":8080",
+		RecipesPath:     "data/recipes",
+		RecipesIgnore:   []string{},
+		ImagesPath:      "data/images",
+		FrontendSource:  FrontendAuto,
+		StaticPath:      "dist",
+		MaxImageSize:    10 << 20,
+		ValidationMode:  ValidationStrict,
+		ReadTimeout:     Duration(15 * time.Second),
+		WriteTimeout:    Duration(60 * time.Second),
+		IdleTimeout:     Duration(120 * time.Second),
+		RequestTimeout:  Duration(30 * time.Second),
+		ShutdownTimeout: Duration(20 * time.Second),
+		LogLevel:        "info",
+		LogFormat:       "json",
+		TracingExporter: "none",
+		TracingEndpoint: "localhost:4318",
+		// Keep reading open once auth is enabled, as before it existed.
+		AuthAnonymousRead: true,
+		AccountsStore:     AccountsStoreJSON,
+		AccountsPath:      "data/accounts.json",
+		SessionTTL:        Duration(30 * 24 * time.Hour),
+		// Any origin may read and write, as before the policy was configurable.
+		CORSAllowedOrigins: []string{"*"},
+		CORSAllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		CORSAllowedHeaders: []string{"Content-Type", "Authorization", "If-Match", "If-None-Match", "X-API-Key", "X-Author", "X-Request-ID"},
+		CORSExposedHeaders: []string{"ETag", "Last-Modified", "Location", "X-Request-ID", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"},
+		CORSMaxAge:         Duration(10 * time.Minute),
+		RateLimits: []RateLimit{
+			{Group: "read", RequestsPerMinute: 600, Burst: 120},
+			{Group: "write", RequestsPerMinute: 120, Burst: 30},
+			{Group: "admin", RequestsPerMinute: 30, Burst: 10},
+			{Group: "auth", RequestsPerMinute: 10, Burst: 5},
+		},
+		MaxBodySize:   1 << 20,
+		MaxImportSize: 256 << 20,
+		origins:       make(map[string]string),
+	}
+	for _, field := range cfg.Fields() {
+		cfg.origins[field.Key] = OriginDefault
+	}
+	return cfg
+}
+
+// Load layers the given sources over the defaults and validates the result.
+func Load(sources Sources) (*Config, error) {
+	cfg := Default()
+
+	if sources.File != "" {
+		if err := cfg.loadFile(sources.File); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) || sources.FileRequired {
+				return nil, err
+			}
+		}
+	}
+
+	if sources.LookupEnv != nil {
+		for _, field := range cfg.Fields() {
+			value, ok := sources.LookupEnv(field.Env)
+			if !ok {
+				continue
+			}
+			if err := cfg.Set(field.Key, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", field.Env, err)
+			}
+			cfg.origins[field.Key] = OriginEnv
+		}
+	}
+
+	for key, value := range sources.Flags {
+		if err := cfg.Set(key, value); err != nil {
+			return nil, fmt.Errorf("invalid flag for %s: %w", key, err)
+		}
+		cfg.origins[key] = OriginFlag
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-// LoadConfig reads a JSON config file from the given path
-// and unmarshals it into a Config struct.
-func LoadConfig(path string) (*Config, error) {
-	file, err := os.Open(path)
+// loadFile reads the keys present in a JSON or YAML file; the other keys
+// keep their current value.
+func (c *Config) loadFile(path string) error {
+	bytes, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open config file %s: %w", path, err)
+		return fmt.Errorf("failed to open config file %s: %w", path, err)
 	}
-	defer file.Close()
 
-	bytes, err := io.ReadAll(file)
+	// Decode into a map first to know which keys the file sets.
+	var values map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bytes, &values)
+	default:
+		err = json.Unmarshal(bytes, &values)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return fmt.Errorf("failed to unmarshal config file %s: %w", path, err)
+	}
+
+	for key, value := range values {
+		if _, ok := c.origins[key]; !ok {
+			return fmt.Errorf("unknown key %q in config file %s", key, path)
+		}
+		var text string
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			text = v
+		case float64:
+			// JSON numbers; keep integers free of exponents.
+			text = strconv.FormatFloat(v, 'f', -1, 64)
+		case []any, map[string]any:
+			// Lists and objects are parsed from their JSON encoding, as in
+			// environment variables and flags.
+			data, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s in config file %s: %w", key, path, err)
+			}
+			text = string(data)
+		default:
+			text = fmt.Sprint(v)
+		}
+		if err := c.Set(key, text); err != nil {
+			return fmt.Errorf("invalid %s in config file %s: %w", key, path, err)
+		}
+		c.origins[key] = OriginFile
+	}
+	return nil
+}
+
+// Set parses value into the field named key.
+func (c *Config) Set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if fieldKey(v.Type().Field(i)) != key {
+			continue
+		}
+		field := v.Field(i)
+		if field.Type() == durationType {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a duration such as \"30s\"", value)
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int, reflect.Int64:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not an integer", value)
+			}
+			field.SetInt(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a boolean", value)
+			}
+			field.SetBool(b)
+		case reflect.Slice:
+			// Lists are given as JSON, e.g. [{"name": "ci", "key": "...", "scopes": ["recipes:read"]}].
+			slice := reflect.New(field.Type())
+			if err := json.Unmarshal([]byte(value), slice.Interface()); err != nil {
+				return fmt.Errorf("%q is not a JSON list: %v", value, err)
+			}
+			field.Set(slice.Elem())
+		default:
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown configuration key %q", key)
+}
+
+// Fields lists every configuration key with its current value and origin.
+func (c *Config) Fields() []Field {
+	v := reflect.ValueOf(c).Elem()
+	var fields []Field
+	for i := 0; i < v.NumField(); i++ {
+		key := fieldKey(v.Type().Field(i))
+		if key == "" {
+			continue
+		}
+		fields = append(fields, Field{
+			Key:    key,
+			Env:    EnvPrefix + strings.ToUpper(key),
+			Value:  fmt.Sprint(v.Field(i).Interface()),
+			Origin: c.origins[key],
+		})
+	}
+	return fields
+}
+
+func fieldKey(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return key
+}
+
+// Validate normalizes the configuration and checks that it can be used: a
+// valid listen address, an existing recipes directory and, when present, a
+// readable static directory.
+func (c *Config) Validate() error {
+	var errs []error
+
+	// Accept a bare port number, as commonly given in container environments.
+	if _, err := strconv.Atoi(c.ServerPort); err == nil {
+		c.ServerPort = ":" + c.ServerPort
+	}
+	if _, port, err := net.SplitHostPort(c.ServerPort); err != nil {
+		errs = append(errs, fmt.Errorf("invalid server_port %q: expected [host]:port", c.ServerPort))
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		errs = append(errs, fmt.Errorf("invalid server_port %q: port must be between 1 and 65535", c.ServerPort))
+	}
+
+	if c.RecipesPath == "" {
+		errs = append(errs, errors.New("recipes_path must not be empty"))
+	} else if info, err := os.Stat(c.RecipesPath); err != nil {
+		errs = append(errs, fmt.Errorf("invalid recipes_path: %w", err))
+	} else if !info.IsDir() {
+		errs = append(errs, fmt.Errorf("invalid recipes_path: %s is not a directory", c.RecipesPath))
+	}
+
+	if c.RecipesMaxDepth < 0 {
+		errs = append(errs, fmt.Errorf("invalid recipes_max_depth %d: must not be negative", c.RecipesMaxDepth))
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(bytes, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config file: %w", err)
+	// The frontend is built separately, so a missing static directory only
+	// means that no frontend is served; an unreadable one is a mistake.
+	if c.StaticPath != "" {
+		if _, err := os.ReadDir(c.StaticPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("invalid static_path: %w", err))
+		}
+	}
+
+	switch c.FrontendSource {
+	case FrontendAuto, FrontendEmbedded, FrontendDisk, FrontendNone:
+	default:
+		errs = append(errs, fmt.Errorf("invalid frontend_source %q, expected %q, %q, %q or %q", c.FrontendSource, FrontendAuto, FrontendEmbedded, FrontendDisk, FrontendNone))
+	}
+	if c.FrontendSource == FrontendDisk && c.StaticPath == "" {
+		errs = append(errs, errors.New("frontend_source disk requires static_path"))
 	}
 
-	// Optionally, set defaults if fields are empty:
-	if cfg.ServerPort == "" {
-		cfg.ServerPort = ":8080"
+	if c.MaxImageSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid max_image_size %d: must be positive", c.MaxImageSize))
+	}
+	timeouts := map[string]Duration{
+		"read_timeout":     c.ReadTimeout,
+		"write_timeout":    c.WriteTimeout,
+		"idle_timeout":     c.IdleTimeout,
+		"request_timeout":  c.RequestTimeout,
+		"shutdown_timeout": c.ShutdownTimeout,
+	}
+	for key, timeout := range timeouts {
+		if timeout < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s %s: must not be negative", key, timeout))
+		}
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("invalid log_level %q, expected debug, info, warn or error", c.LogLevel))
+	}
+	switch strings.ToLower(c.LogFormat) {
+	case "json", "text":
+	default:
+		errs = append(errs, fmt.Errorf("invalid log_format %q, expected json or text", c.LogFormat))
+	}
+	switch c.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("invalid tracing_exporter %q, expected none, stdout or otlp", c.TracingExporter))
+	}
+	// Without any other credentials, users can still register and log in.
+	if c.AuthEnabled && len(c.AuthAPIKeys) == 0 && c.AuthJWTSecret == "" && c.AuthJWKSFile == "" && !c.RegistrationOpen {
+		errs = append(errs, errors.New("auth_enabled requires auth_api_keys, auth_jwt_secret, auth_jwks_file or registration_open"))
+	}
+	for i, key := range c.AuthAPIKeys {
+		if key.Name == "" || key.Key == "" {
+			errs = append(errs, fmt.Errorf("invalid auth_api_keys[%d]: name and key are required", i))
+		}
+	}
+	switch c.AccountsStore {
+	case AccountsStoreJSON, AccountsStoreSQLite:
+	default:
+		errs = append(errs, fmt.Errorf("invalid accounts_store %q, expected %q or %q", c.AccountsStore, AccountsStoreJSON, AccountsStoreSQLite))
+	}
+	if c.AccountsPath == "" {
+		errs = append(errs, errors.New("accounts_path must not be empty"))
+	}
+	if c.SessionTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid session_ttl %s: must be positive", c.SessionTTL))
+	}
+	for _, origin := range c.CORSAllowedOrigins {
+		if _, err := path.Match(origin, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid cors_allowed_origins pattern %q: %w", origin, err))
+		}
+		// Echoing any origin with credentials would let every site act as the user.
+		if origin == "*" && c.CORSAllowCredentials {
+			errs = append(errs, errors.New(`cors_allow_credentials requires explicit cors_allowed_origins, not "*"`))
+		}
+	}
+	if c.CORSMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("invalid cors_max_age %s: must not be negative", c.CORSMaxAge))
+	}
+	groups := make(map[string]bool)
+	for i, limit := range c.RateLimits {
+		switch limit.Group {
+		case "read", "write", "admin", "auth":
+		default:
+			errs = append(errs, fmt.Errorf("invalid rate_limits[%d]: group %q, expected read, write, admin or auth", i, limit.Group))
+		}
+		if groups[limit.Group] {
+			errs = append(errs, fmt.Errorf("invalid rate_limits[%d]: group %q is limited twice", i, limit.Group))
+		}
+		groups[limit.Group] = true
+		if limit.RequestsPerMinute <= 0 || limit.Burst < 0 {
+			errs = append(errs, fmt.Errorf("invalid rate_limits[%d]: requests_per_minute must be positive and burst must not be negative", i))
+		}
+	}
+	if c.MaxBodySize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid max_body_size %d: must be positive", c.MaxBodySize))
+	}
+	if c.MaxImportSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid max_import_size %d: must be positive", c.MaxImportSize))
 	}
-	if cfg.RecipesPath == "" {
-		cfg.RecipesPath = "data/recipes"
+	switch c.ValidationMode {
+	case ValidationStrict, ValidationLenient:
+	default:
+		errs = append(errs, fmt.Errorf("invalid validation_mode %q, expected %q or %q", c.ValidationMode, ValidationStrict, ValidationLenient))
 	}
 
-	return &cfg, nil
+	return errors.Join(errs...)
 }
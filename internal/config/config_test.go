@@ -0,0 +1,210 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.json", `{"server_port": ":9090", "recipes_path": "`+dir+`", "max_image_size": 2048}`)
+	env := map[string]string{
+		"RECIPE_MANAGER_SERVER_PORT":     "7000",
+		"RECIPE_MANAGER_VALIDATION_MODE": "lenient",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	cfg, err := Load(Sources{
+		File:      path,
+		LookupEnv: lookupEnv,
+		Flags:     map[string]string{"validation_mode": "strict"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.ServerPort != ":7000" {
+		t.Errorf("expected the environment to override the file port, got %q", cfg.ServerPort)
+	}
+	if cfg.MaxImageSize != 2048 {
+		t.Errorf("expected max_image_size from the file, got %d", cfg.MaxImageSize)
+	}
+	if cfg.ValidationMode != ValidationStrict {
+		t.Errorf("expected the flag to override the environment, got %q", cfg.ValidationMode)
+	}
+	if cfg.ImagesPath != "data/images" {
+		t.Errorf("expected the default images path, got %q", cfg.ImagesPath)
+	}
+
+	origins := make(map[string]string)
+	for _, field := range cfg.Fields() {
+		origins[field.Key] = field.Origin
+	}
+	want := map[string]string{
+		"server_port":     OriginEnv,
+		"recipes_path":    OriginFile,
+		"images_path":     OriginDefault,
+		"max_image_size":  OriginFile,
+		"validation_mode": OriginFlag,
+	}
+	for key, origin := range want {
+		if origins[key] != origin {
+			t.Errorf("expected %s to come from %s, got %s", key, origin, origins[key])
+		}
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "server_port: \"127.0.0.1:8081\"\nrecipes_path: "+dir+"\nmax_image_size: 4096\nread_timeout: 1m30s\n")
+
+	cfg, err := Load(Sources{File: path, FileRequired: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ServerPort != "127.0.0.1:8081" || cfg.RecipesPath != dir || cfg.MaxImageSize != 4096 || cfg.ReadTimeout != Duration(90*time.Second) {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestLoad_APIKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "recipes_path: "+dir+"\nauth_enabled: true\nauth_api_keys:\n  - name: ci\n    key: s3cret\n    scopes: [recipes:read, recipes:write]\n")
+
+	cfg, err := Load(Sources{File: path, FileRequired: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.AuthAPIKeys) != 1 || cfg.AuthAPIKeys[0].Key != "s3cret" || len(cfg.AuthAPIKeys[0].Scopes) != 2 {
+		t.Fatalf("unexpected API keys %+v", cfg.AuthAPIKeys)
+	}
+
+	// Environment variables and flags give lists as JSON.
+	cfg, err = Load(Sources{
+		File:  path,
+		Flags: map[string]string{"auth_api_keys": `[{"name": "admin", "key": "other", "scopes": ["admin"]}]`},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.AuthAPIKeys) != 1 || cfg.AuthAPIKeys[0].Name != "admin" {
+		t.Errorf("expected the flag to replace the keys, got %+v", cfg.AuthAPIKeys)
+	}
+
+	for _, field := range cfg.Fields() {
+		if strings.Contains(field.Value, "other") {
+			t.Errorf("expected the key to be redacted, got %s=%s", field.Key, field.Value)
+		}
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.json")
+	flags := map[string]string{"recipes_path": dir}
+
+	if _, err := Load(Sources{File: missing, Flags: flags}); err != nil {
+		t.Errorf("expected an optional missing file to be skipped, got %v", err)
+	}
+	if _, err := Load(Sources{File: missing, FileRequired: true, Flags: flags}); err == nil {
+		t.Error("expected an error for a missing required file")
+	}
+}
+
+func TestLoad_InvalidDuration(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.json", `{"recipes_path": "`+dir+`", "idle_timeout": 30}`)
+
+	_, err := Load(Sources{File: path})
+	if err == nil || !strings.Contains(err.Error(), "idle_timeout") {
+		t.Errorf("expected a duration error, got %v", err)
+	}
+}
+
+func TestLoad_UnknownKey(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.json", `{"recipes_path": "`+dir+`", "server_prot": ":80"}`)
+
+	_, err := Load(Sources{File: path})
+	if err == nil || !strings.Contains(err.Error(), "server_prot") {
+		t.Errorf("expected an unknown key error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "file.txt", "")
+
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"bare port", func(c *Config) { c.ServerPort = "8080" }, ""},
+		{"bad port", func(c *Config) { c.ServerPort = "localhost" }, "server_port"},
+		{"port out of range", func(c *Config) { c.ServerPort = ":70000" }, "server_port"},
+		{"missing recipes dir", func(c *Config) { c.RecipesPath = filepath.Join(dir, "missing") }, "recipes_path"},
+		{"recipes path is a file", func(c *Config) { c.RecipesPath = file }, "recipes_path"},
+		{"missing static dir", func(c *Config) { c.StaticPath = filepath.Join(dir, "missing") }, ""},
+		{"static path is a file", func(c *Config) { c.StaticPath = file }, "static_path"},
+		{"bad frontend source", func(c *Config) { c.FrontendSource = "cdn" }, "frontend_source"},
+		{"disk frontend without path", func(c *Config) {
+			c.FrontendSource = FrontendDisk
+			c.StaticPath = ""
+		}, "static_path"},
+		{"bad validation mode", func(c *Config) { c.ValidationMode = "loose" }, "validation_mode"},
+		{"negative timeout", func(c *Config) { c.ShutdownTimeout = -1 }, "shutdown_timeout"},
+		{"bad log level", func(c *Config) { c.LogLevel = "verbose" }, "log_level"},
+		{"bad log format", func(c *Config) { c.LogFormat = "xml" }, "log_format"},
+		{"bad tracing exporter", func(c *Config) { c.TracingExporter = "jaeger" }, "tracing_exporter"},
+		{"bad image size", func(c *Config) { c.MaxImageSize = 0 }, "max_image_size"},
+		{"auth without credentials", func(c *Config) { c.AuthEnabled = true }, "auth_enabled"},
+		{"API key without name", func(c *Config) { c.AuthAPIKeys = []APIKey{{Key: "k"}} }, "auth_api_keys"},
+		{"bad accounts store", func(c *Config) { c.AccountsStore = "postgres" }, "accounts_store"},
+		{"bad session TTL", func(c *Config) { c.SessionTTL = 0 }, "session_ttl"},
+		{"bad CORS origin", func(c *Config) { c.CORSAllowedOrigins = []string{"https://[.example.com"} }, "cors_allowed_origins"},
+		{"CORS credentials for any origin", func(c *Config) { c.CORSAllowCredentials = true }, "cors_allow_credentials"},
+		{"bad rate limit group", func(c *Config) { c.RateLimits = []RateLimit{{Group: "uploads", RequestsPerMinute: 1}} }, "rate_limits"},
+		{"zero rate limit", func(c *Config) { c.RateLimits = []RateLimit{{Group: "read"}} }, "rate_limits"},
+		{"bad max body size", func(c *Config) { c.MaxBodySize = 0 }, "max_body_size"},
+		{"bad max import size", func(c *Config) { c.MaxImportSize = -1 }, "max_import_size"},
+		{"bad recipes max depth", func(c *Config) { c.RecipesMaxDepth = -1 }, "recipes_max_depth"},
+		{"CORS credentials for an origin", func(c *Config) {
+			c.CORSAllowedOrigins = []string{"https://*.example.com"}
+			c.CORSAllowCredentials = true
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.RecipesPath = dir
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error about %s, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Visibility controls who may see a recipe.
+type Visibility string
+
+const (
+	// VisibilityPrivate recipes are only seen by their owner.
+	VisibilityPrivate Visibility = "private"
+	// VisibilityHousehold recipes are seen by every member of the owner's household.
+	VisibilityHousehold Visibility = "household"
+	// VisibilityPublic recipes are seen by everyone, including anonymous clients.
+	VisibilityPublic Visibility = "public"
+)
+
+// ParseVisibility checks that s names a visibility.
+func ParseVisibility(s string) (Visibility, error) {
+	switch v := Visibility(s); v {
+	case VisibilityPrivate, VisibilityHousehold, VisibilityPublic:
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown visibility %q, expected %s, %s or %s", s, VisibilityPrivate, VisibilityHousehold, VisibilityPublic)
+}
+
+// User is a person with an account. PasswordHash is never sent to clients.
+type User struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	HouseholdID  string    `json:"household_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Household groups users sharing the recipes they mark as household-visible.
+type Household struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Session is a login of a user. Only a hash of the token given to the client
+// is stored, so that a leaked store does not leak usable tokens.
+type Session struct {
+	TokenHash string    `json:"token_hash"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RecipeAccess records who owns a recipe and who may see it. Recipes without
+// an owner, such as those written to the recipes directory by hand, are
+// public and may be edited by any client allowed to write.
+type RecipeAccess struct {
+	RecipeID string `json:"recipe_id"`
+	OwnerID  string `json:"owner_id"`
+	// HouseholdID is the household the owner belongs to now. It is not
+	// stored: repositories look it up when reading, so that household-visible
+	// recipes follow their owner from one household to the next.
+	HouseholdID string     `json:"household_id,omitempty"`
+	Visibility  Visibility `json:"visibility"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Collection is a named list of recipes kept by a user.
+type Collection struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	RecipeIDs []string  `json:"recipe_ids"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Viewer is the client a request is served to. The zero Viewer is an
+// anonymous client; Admin viewers see and edit every recipe.
+type Viewer struct {
+	UserID      string
+	HouseholdID string
+	Admin       bool
+}
+
+// CanSee reports whether the viewer may see a recipe with the given access.
+func (v Viewer) CanSee(access RecipeAccess) bool {
+	switch {
+	case v.Admin || access.OwnerID == "" || access.Visibility == VisibilityPublic:
+		return true
+	case v.UserID == "":
+		return false
+	case access.OwnerID == v.UserID:
+		return true
+	case access.Visibility == VisibilityHousehold:
+		return v.HouseholdID != "" && access.HouseholdID == v.HouseholdID
+	}
+	return false
+}
+
+// CanEdit reports whether the viewer may change a recipe with the given
+// access: its owner may, and so may the members of the owner's household
+// unless the recipe is private.
+func (v Viewer) CanEdit(access RecipeAccess) bool {
+	switch {
+	case v.Admin || access.OwnerID == "":
+		return true
+	case v.UserID == "":
+		return false
+	case access.OwnerID == v.UserID:
+		return true
+	}
+	return access.Visibility != VisibilityPrivate && v.HouseholdID != "" && access.HouseholdID == v.HouseholdID
+}
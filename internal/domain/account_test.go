@@ -0,0 +1,51 @@
+package domain
+
+import "testing"
+
+func TestViewer_Access(t *testing.T) {
+	alice := Viewer{UserID: "alice", HouseholdID: "home"}
+	bob := Viewer{UserID: "bob", HouseholdID: "home"}
+	carol := Viewer{UserID: "carol"}
+	anonymous := Viewer{}
+	admin := Viewer{Admin: true}
+
+	access := func(visibility Visibility) RecipeAccess {
+		return RecipeAccess{RecipeID: "1", OwnerID: "alice", HouseholdID: "home", Visibility: visibility}
+	}
+	tests := []struct {
+		name     string
+		viewer   Viewer
+		access   RecipeAccess
+		wantSee  bool
+		wantEdit bool
+	}{
+		{"owner of private", alice, access(VisibilityPrivate), true, true},
+		{"household member of private", bob, access(VisibilityPrivate), false, false},
+		{"household member of household", bob, access(VisibilityHousehold), true, true},
+		{"stranger of household", carol, access(VisibilityHousehold), false, false},
+		{"stranger of public", carol, access(VisibilityPublic), true, false},
+		{"anonymous of public", anonymous, access(VisibilityPublic), true, false},
+		{"anonymous of private", anonymous, access(VisibilityPrivate), false, false},
+		{"admin of private", admin, access(VisibilityPrivate), true, true},
+		{"anonymous of unowned", anonymous, RecipeAccess{RecipeID: "1"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.viewer.CanSee(tt.access); got != tt.wantSee {
+				t.Errorf("CanSee = %v, want %v", got, tt.wantSee)
+			}
+			if got := tt.viewer.CanEdit(tt.access); got != tt.wantEdit {
+				t.Errorf("CanEdit = %v, want %v", got, tt.wantEdit)
+			}
+		})
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	if v, err := ParseVisibility("household"); err != nil || v != VisibilityHousehold {
+		t.Errorf("unexpected result %q, %v", v, err)
+	}
+	if _, err := ParseVisibility("friends"); err == nil {
+		t.Error("expected an error for an unknown visibility")
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"time"
+)
+
+// crockford is the alphabet of ULIDs, without the easily confused I, L, O
+// and U.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewRecipeID returns a new ULID for a recipe. ULIDs sort by creation time,
+// so files named after them list in the order recipes were added.
+func NewRecipeID() (string, error) {
+	id, err := NewULID(time.Now(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate recipe ID: %w", err)
+	}
+	return id, nil
+}
+
+// NewULID encodes the millisecond timestamp t and 80 bits read from entropy
+// as a 26 character ULID.
+func NewULID(t time.Time, entropy io.Reader) (string, error) {
+	var data [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		data[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := io.ReadFull(entropy, data[6:]); err != nil {
+		return "", err
+	}
+
+	// 128 bits in 26 characters of 5 bits: the first one only holds 3 bits.
+	id := make([]byte, 26)
+	var acc uint32
+	bits := 2 // pad the 128 bits to 130
+	pos := 0
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			id[pos] = crockford[(acc>>bits)&31]
+			pos++
+		}
+	}
+	return string(id), nil
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewULID(t *testing.T) {
+	// The example of the ULID specification.
+	id, err := NewULID(time.UnixMilli(1469918176385), bytes.NewReader(make([]byte, 10)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "01ARYZ6S410000000000000000" {
+		t.Errorf("unexpected ULID %s", id)
+	}
+
+	id, _ = NewULID(time.UnixMilli(1<<48-1), bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+	if id != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("expected the largest ULID, got %s", id)
+	}
+
+	if _, err := NewULID(time.Now(), strings.NewReader("short")); err == nil {
+		t.Error("expected missing entropy to fail")
+	}
+
+	first, err := NewRecipeID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := NewRecipeID()
+	if len(first) != 26 || first == second {
+		t.Errorf("expected distinct ULIDs, got %s and %s", first, second)
+	}
+}
@@ -5,8 +5,15 @@ import "errors"
 // RecipeService defines domain-level operations.
 type RecipeService interface {
 	ComputeRatios(recipe *Recipe, constraintName string, constraintQuantity float64) error
+	Scale(recipe *Recipe, factor float64) error
 }
 
+// Errors returned when a recipe cannot be scaled.
+var (
+	ErrIngredientNotFound = errors.New("ingredient constraint not found in recipe")
+	ErrInvalidRatio       = errors.New("invalid scaling ratio")
+)
+
 // recipeService is a concrete implementation of RecipeService.
 type recipeService struct{}
 
@@ -32,18 +39,24 @@ func (s *recipeService) ComputeRatios(recipe *Recipe, constraintName string, con
 	}
 
 	if baseQuantity == 0 {
-		return errors.New("ingredient constraint not found in recipe")
+		return ErrIngredientNotFound
 	}
 
-	ratio := constraintQuantity / baseQuantity
-	if ratio <= 0 {
-		return errors.New("invalid scaling ratio")
+	return s.Scale(recipe, constraintQuantity/baseQuantity)
+}
+
+// Scale multiplies all ingredient quantities of a recipe, and its servings,
+// by factor.
+func (s *recipeService) Scale(recipe *Recipe, factor float64) error {
+	if factor <= 0 {
+		return ErrInvalidRatio
 	}
 
 	// Scale all ingredient quantities.
 	for i := range recipe.Ingredients {
-		recipe.Ingredients[i].Quantity *= ratio
+		recipe.Ingredients[i].Quantity *= factor
 	}
+	recipe.Servings *= factor
 
 	return nil
 }
@@ -76,3 +76,29 @@ func TestComputeRatios_IngredientNotFound(t *testing.T) {
 		t.Error("expected error due to missing ingredient, got none")
 	}
 }
+
+func TestScale(t *testing.T) {
+	service := NewRecipeService()
+
+	recipe := &Recipe{
+		Servings: 4,
+		Ingredients: []Ingredient{
+			{Name: "Flour", Quantity: 200, Unit: "grams"},
+			{Name: "Milk", Quantity: 300, Unit: "ml"},
+		},
+	}
+
+	if err := service.Scale(recipe, 1.5); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if recipe.Ingredients[0].Quantity != 300 || recipe.Ingredients[1].Quantity != 450 {
+		t.Errorf("expected quantities 300 and 450, got %v", recipe.Ingredients)
+	}
+	if recipe.Servings != 6 {
+		t.Errorf("expected 6 servings, got %v", recipe.Servings)
+	}
+
+	if err := service.Scale(recipe, 0); err == nil {
+		t.Error("expected error for a zero factor, got none")
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Bounds of review ratings; a zero rating means the review is not rated.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review is a rating, a comment or a cook-log entry of a recipe, e.g.
+// "cooked this on Tuesday, needed 5 more minutes". Reviews with CookedOn set
+// count as times the recipe was cooked.
+type Review struct {
+	ID       string `json:"id"`
+	RecipeID string `json:"recipe_id"`
+	// UserID is the user who wrote the review, empty for clients that are
+	// not logged in as a user.
+	UserID string `json:"user_id,omitempty"`
+	Author string `json:"author"`
+	Rating int    `json:"rating,omitempty"`
+	Notes  string `json:"notes,omitempty"`
+	// CookedOn is the date the recipe was cooked, as YYYY-MM-DD.
+	CookedOn string `json:"cooked_on,omitempty"`
+	// Servings is how many servings were made.
+	Servings int `json:"servings,omitempty"`
+	// Adjustments lists the changes made to the recipe, e.g. "bake 5 minutes longer".
+	Adjustments []string  `json:"adjustments,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate returns nil when the review is valid.
+func (r Review) Validate() ValidationErrors {
+	var errs ValidationErrors
+	if r.Rating != 0 && (r.Rating < MinRating || r.Rating > MaxRating) {
+		errs = append(errs, FieldError{Path: "rating", Message: fmt.Sprintf("must be between %d and %d", MinRating, MaxRating)})
+	}
+	if r.CookedOn != "" {
+		if _, err := time.Parse(time.DateOnly, r.CookedOn); err != nil {
+			errs = append(errs, FieldError{Path: "cooked_on", Message: "must be a date such as 2024-05-01"})
+		}
+	}
+	if r.Servings < 0 {
+		errs = append(errs, FieldError{Path: "servings", Message: "must not be negative"})
+	}
+	for i, adjustment := range r.Adjustments {
+		if strings.TrimSpace(adjustment) == "" {
+			errs = append(errs, FieldError{Path: fmt.Sprintf("adjustments[%d]", i), Message: "must not be empty"})
+		}
+	}
+	if r.Rating == 0 && r.CookedOn == "" && strings.TrimSpace(r.Notes) == "" {
+		errs = append(errs, FieldError{Message: "a review needs a rating, notes or the date the recipe was cooked"})
+	}
+	return errs
+}
+
+// RecipeStats aggregates the reviews of a recipe.
+type RecipeStats struct {
+	// AverageRating is the mean of the rated reviews, or zero without any.
+	AverageRating float64 `json:"average_rating,omitempty"`
+	RatingCount   int     `json:"rating_count"`
+	TimesCooked   int     `json:"times_cooked"`
+	// LastCooked is the latest CookedOn date, as YYYY-MM-DD.
+	LastCooked string `json:"last_cooked,omitempty"`
+	// UpdatedAt is when the reviews last changed, to validate cached listings.
+	UpdatedAt time.Time `json:"-"`
+}
+
+// SummarizeReviews computes the stats of the reviews of a recipe.
+func SummarizeReviews(reviews []Review) RecipeStats {
+	var stats RecipeStats
+	total := 0
+	for _, review := range reviews {
+		if review.UpdatedAt.After(stats.UpdatedAt) {
+			stats.UpdatedAt = review.UpdatedAt
+		}
+		if review.Rating != 0 {
+			total += review.Rating
+			stats.RatingCount++
+		}
+		if review.CookedOn != "" {
+			stats.TimesCooked++
+			// Dates as YYYY-MM-DD sort as strings.
+			if review.CookedOn > stats.LastCooked {
+				stats.LastCooked = review.CookedOn
+			}
+		}
+	}
+	if stats.RatingCount > 0 {
+		stats.AverageRating = float64(total) / float64(stats.RatingCount)
+	}
+	return stats
+}
+
+// RecipeSummary is a recipe with the stats of its reviews, as listed.
+type RecipeSummary struct {
+	Recipe
+	RecipeStats
+}
+
+// CanChangeReview reports whether the viewer may edit or delete a review:
+// its author and admins may, and anyone may change reviews written without
+// a user, as they may change recipes nobody owns.
+func (v Viewer) CanChangeReview(review Review) bool {
+	return v.Admin || review.UserID == "" || review.UserID == v.UserID
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "testing"
+
+func TestReview_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		review   Review
+		wantPath []string
+	}{
+		{"rating only", Review{Rating: 4}, nil},
+		{"cook log", Review{CookedOn: "2024-05-07", Servings: 4, Adjustments: []string{"bake 5 minutes longer"}}, nil},
+		{"empty", Review{}, []string{""}},
+		{"rating out of range", Review{Rating: 6}, []string{"rating"}},
+		{"bad date", Review{Notes: "tasty", CookedOn: "Tuesday"}, []string{"cooked_on"}},
+		{"negative servings and empty adjustment", Review{Rating: 3, Servings: -1, Adjustments: []string{" "}}, []string{"servings", "adjustments[0]"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.review.Validate()
+			if len(errs) != len(tt.wantPath) {
+				t.Fatalf("expected %d errors, got %v", len(tt.wantPath), errs)
+			}
+			for i, err := range errs {
+				if err.Path != tt.wantPath[i] {
+					t.Errorf("expected error at %q, got %v", tt.wantPath[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeReviews(t *testing.T) {
+	stats := SummarizeReviews([]Review{
+		{Rating: 5, CookedOn: "2024-05-07"},
+		{Rating: 2},
+		{Notes: "needed 5 more minutes", CookedOn: "2024-06-01"},
+	})
+	want := RecipeStats{AverageRating: 3.5, RatingCount: 2, TimesCooked: 2, LastCooked: "2024-06-01"}
+	if stats != want {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+	if stats := SummarizeReviews(nil); stats != (RecipeStats{}) {
+		t.Errorf("expected empty stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"reflect"
+	"time"
+)
+
+// Revision is an immutable snapshot of a recipe, recorded every time it is saved.
+// The initial revision of a recipe loaded from disk has no author.
+type Revision struct {
+	Number    int       `json:"number"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    string    `json:"author"`
+	Recipe    Recipe    `json:"recipe"`
+}
+
+// ValueChange describes a scalar field that differs between two revisions.
+type ValueChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IngredientChange describes an ingredient present in both revisions whose
+// quantity or unit changed.
+type IngredientChange struct {
+	Name string     `json:"name"`
+	From Ingredient `json:"from"`
+	To   Ingredient `json:"to"`
+}
+
+// StepChange describes a step present in both revisions whose content changed.
+type StepChange struct {
+	ID   string     `json:"id"`
+	From RecipeStep `json:"from"`
+	To   RecipeStep `json:"to"`
+}
+
+// RecipeDiff is a structured comparison between two versions of a recipe.
+// Ingredients are matched by name and steps by ID.
+type RecipeDiff struct {
+	FromRevision       int                `json:"from_revision"`
+	ToRevision         int                `json:"to_revision"`
+	Name               *ValueChange       `json:"name,omitempty"`
+	IngredientsAdded   []Ingredient       `json:"ingredients_added,omitempty"`
+	IngredientsRemoved []Ingredient       `json:"ingredients_removed,omitempty"`
+	IngredientsChanged []IngredientChange `json:"ingredients_changed,omitempty"`
+	StepsAdded         []RecipeStep       `json:"steps_added,omitempty"`
+	StepsRemoved       []RecipeStep       `json:"steps_removed,omitempty"`
+	StepsChanged       []StepChange       `json:"steps_changed,omitempty"`
+	StepsReordered     bool               `json:"steps_reordered,omitempty"`
+}
+
+// Empty reports whether the two versions are identical.
+func (d RecipeDiff) Empty() bool {
+	return d.Name == nil &&
+		len(d.IngredientsAdded) == 0 && len(d.IngredientsRemoved) == 0 && len(d.IngredientsChanged) == 0 &&
+		len(d.StepsAdded) == 0 && len(d.StepsRemoved) == 0 && len(d.StepsChanged) == 0 &&
+		!d.StepsReordered
+}
+
+// DiffRecipes compares two versions of a recipe.
+func DiffRecipes(from, to Recipe) RecipeDiff {
+	var diff RecipeDiff
+
+	if from.Name != to.Name {
+		diff.Name = &ValueChange{From: from.Name, To: to.Name}
+	}
+
+	fromIngredients := make(map[string]Ingredient, len(from.Ingredients))
+	for _, ingredient := range from.Ingredients {
+		fromIngredients[ingredient.Name] = ingredient
+	}
+	toIngredients := make(map[string]bool, len(to.Ingredients))
+	for _, ingredient := range to.Ingredients {
+		toIngredients[ingredient.Name] = true
+		previous, ok := fromIngredients[ingredient.Name]
+		switch {
+		case !ok:
+			diff.IngredientsAdded = append(diff.IngredientsAdded, ingredient)
+		case previous != ingredient:
+			diff.IngredientsChanged = append(diff.IngredientsChanged, IngredientChange{
+				Name: ingredient.Name,
+				From: previous,
+				To:   ingredient,
+			})
+		}
+	}
+	for _, ingredient := range from.Ingredients {
+		if !toIngredients[ingredient.Name] {
+			diff.IngredientsRemoved = append(diff.IngredientsRemoved, ingredient)
+		}
+	}
+
+	fromSteps := make(map[string]RecipeStep, len(from.Steps))
+	for _, step := range from.Steps {
+		fromSteps[step.ID] = step
+	}
+	toSteps := make(map[string]bool, len(to.Steps))
+	var commonFrom, commonTo []string
+	for _, step := range to.Steps {
+		toSteps[step.ID] = true
+		previous, ok := fromSteps[step.ID]
+		if !ok {
+			diff.StepsAdded = append(diff.StepsAdded, step)
+			continue
+		}
+		commonTo = append(commonTo, step.ID)
+		if !reflect.DeepEqual(previous, step) {
+			diff.StepsChanged = append(diff.StepsChanged, StepChange{ID: step.ID, From: previous, To: step})
+		}
+	}
+	for _, step := range from.Steps {
+		if !toSteps[step.ID] {
+			diff.StepsRemoved = append(diff.StepsRemoved, step)
+		} else {
+			commonFrom = append(commonFrom, step.ID)
+		}
+	}
+	diff.StepsReordered = !reflect.DeepEqual(commonFrom, commonTo)
+
+	return diff
+}
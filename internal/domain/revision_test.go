@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestDiffRecipes_Identical(t *testing.T) {
+	recipe := Recipe{
+		ID:          "1",
+		Name:        "Pancakes",
+		Ingredients: []Ingredient{{Name: "Flour", Quantity: 200, Unit: "grams"}},
+		Steps:       []RecipeStep{{ID: "s1", Name: "Mix"}},
+	}
+
+	diff := DiffRecipes(recipe, recipe.Clone())
+	if !diff.Empty() {
+		t.Errorf("expected empty diff, got %+v", diff)
+	}
+}
+
+func TestDiffRecipes_Changes(t *testing.T) {
+	from := Recipe{
+		ID:   "1",
+		Name: "Pancakes",
+		Ingredients: []Ingredient{
+			{Name: "Flour", Quantity: 200, Unit: "grams"},
+			{Name: "Milk", Quantity: 300, Unit: "ml"},
+		},
+		Steps: []RecipeStep{
+			{ID: "s1", Name: "Mix", Instructions: "Mix everything"},
+			{ID: "s2", Name: "Rest"},
+		},
+	}
+	to := Recipe{
+		ID:   "1",
+		Name: "Fluffy pancakes",
+		Ingredients: []Ingredient{
+			{Name: "Flour", Quantity: 250, Unit: "grams"},
+			{Name: "Egg", Quantity: 2, Unit: "pieces"},
+		},
+		Steps: []RecipeStep{
+			{ID: "s1", Name: "Mix", Instructions: "Whisk everything"},
+			{ID: "s3", Name: "Cook"},
+		},
+	}
+
+	diff := DiffRecipes(from, to)
+
+	if diff.Name == nil || diff.Name.From != "Pancakes" || diff.Name.To != "Fluffy pancakes" {
+		t.Errorf("unexpected name change: %+v", diff.Name)
+	}
+	if len(diff.IngredientsAdded) != 1 || diff.IngredientsAdded[0].Name != "Egg" {
+		t.Errorf("expected Egg to be added, got %+v", diff.IngredientsAdded)
+	}
+	if len(diff.IngredientsRemoved) != 1 || diff.IngredientsRemoved[0].Name != "Milk" {
+		t.Errorf("expected Milk to be removed, got %+v", diff.IngredientsRemoved)
+	}
+	if len(diff.IngredientsChanged) != 1 || diff.IngredientsChanged[0].To.Quantity != 250 {
+		t.Errorf("expected Flour quantity change, got %+v", diff.IngredientsChanged)
+	}
+	if len(diff.StepsAdded) != 1 || diff.StepsAdded[0].ID != "s3" {
+		t.Errorf("expected step s3 to be added, got %+v", diff.StepsAdded)
+	}
+	if len(diff.StepsRemoved) != 1 || diff.StepsRemoved[0].ID != "s2" {
+		t.Errorf("expected step s2 to be removed, got %+v", diff.StepsRemoved)
+	}
+	if len(diff.StepsChanged) != 1 || diff.StepsChanged[0].ID != "s1" {
+		t.Errorf("expected step s1 to be changed, got %+v", diff.StepsChanged)
+	}
+	if diff.StepsReordered {
+		t.Error("expected steps not to be reordered")
+	}
+}
+
+func TestDiffRecipes_Reordered(t *testing.T) {
+	from := Recipe{Steps: []RecipeStep{{ID: "s1"}, {ID: "s2"}}}
+	to := Recipe{Steps: []RecipeStep{{ID: "s2"}, {ID: "s1"}}}
+
+	diff := DiffRecipes(from, to)
+	if !diff.StepsReordered {
+		t.Error("expected steps to be reordered")
+	}
+}
+
+func TestRecipeClone_Independent(t *testing.T) {
+	recipe := Recipe{
+		Ingredients: []Ingredient{{Name: "Flour", Quantity: 200}},
+		Steps:       []RecipeStep{{ID: "s1", RecipeIllustration: []RecipeIllustration{{ID: "i1"}}}},
+	}
+
+	clone := recipe.Clone()
+	clone.Ingredients[0].Quantity = 400
+	clone.Steps[0].RecipeIllustration[0].ID = "i2"
+
+	if recipe.Ingredients[0].Quantity != 200 {
+		t.Errorf("expected original quantity to stay 200, got %v", recipe.Ingredients[0].Quantity)
+	}
+	if recipe.Steps[0].RecipeIllustration[0].ID != "i1" {
+		t.Errorf("expected original illustration to stay i1, got %s", recipe.Steps[0].RecipeIllustration[0].ID)
+	}
+}
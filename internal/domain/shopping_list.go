@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// ShoppingListItem is the total quantity of one ingredient needed across
+// several recipes. Quantities are only summed for identical units.
+type ShoppingListItem struct {
+	Name     string   `json:"name"`
+	Quantity float64  `json:"quantity"`
+	Unit     string   `json:"unit"`
+	Recipes  []string `json:"recipes"`
+}
+
+// BuildShoppingList merges the ingredients of the given recipes, matching
+// ingredient names case-insensitively, and sorts the result by name.
+func BuildShoppingList(recipes []Recipe) []ShoppingListItem {
+	type key struct{ name, unit string }
+	index := make(map[key]int)
+	items := make([]ShoppingListItem, 0)
+
+	for _, recipe := range recipes {
+		for _, ingredient := range recipe.Ingredients {
+			k := key{strings.ToLower(ingredient.Name), strings.ToLower(ingredient.Unit)}
+			i, ok := index[k]
+			if !ok {
+				i = len(items)
+				index[k] = i
+				items = append(items, ShoppingListItem{Name: ingredient.Name, Unit: ingredient.Unit})
+			}
+			items[i].Quantity += ingredient.Quantity
+			if n := len(items[i].Recipes); n == 0 || items[i].Recipes[n-1] != recipe.Name {
+				items[i].Recipes = append(items[i].Recipes, recipe.Name)
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(a, b int) bool {
+		return strings.ToLower(items[a].Name) < strings.ToLower(items[b].Name)
+	})
+	return items
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestBuildShoppingList(t *testing.T) {
+	recipes := []Recipe{
+		{Name: "Pancakes", Ingredients: []Ingredient{
+			{Name: "Flour", Quantity: 200, Unit: "g"},
+			{Name: "Milk", Quantity: 300, Unit: "ml"},
+		}},
+		{Name: "Cake", Ingredients: []Ingredient{
+			{Name: "flour", Quantity: 250, Unit: "g"},
+			{Name: "Milk", Quantity: 1, Unit: "cup"},
+		}},
+	}
+
+	items := BuildShoppingList(recipes)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %+v", items)
+	}
+	if items[0].Name != "Flour" || items[0].Quantity != 450 || len(items[0].Recipes) != 2 {
+		t.Errorf("expected 450 g of Flour from 2 recipes, got %+v", items[0])
+	}
+	// Different units are kept apart.
+	if items[1].Unit != "ml" || items[2].Unit != "cup" {
+		t.Errorf("expected Milk in ml and in cups, got %+v and %+v", items[1], items[2])
+	}
+}
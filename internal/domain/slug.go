@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ligatures spells out the letters that do not decompose into a base letter
+// and accents.
+var ligatures = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "Æ", "ae", "œ", "oe", "Œ", "oe",
+	"ø", "o", "Ø", "o", "ł", "l", "Ł", "l", "đ", "d", "Đ", "d", "þ", "th", "Þ", "th",
+)
+
+// Slugify turns a recipe name into the lowercase, hyphen-separated ASCII
+// form used in URLs, e.g. "Crème brûlée" into "creme-brulee". Letters and
+// digits of other scripts are kept. It returns "" when name holds neither.
+func Slugify(name string) string {
+	var slug strings.Builder
+	hyphen := false
+	for _, r := range norm.NFD.String(ligatures.Replace(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '\'', r == '’':
+			// Accents decomposed from their letter, and elisions as in
+			// "Grandma's".
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			hyphen = false
+			slug.WriteRune(unicode.ToLower(r))
+		default:
+			hyphen = true
+		}
+	}
+	// Recompose what other scripts decomposed, e.g. Hangul syllables.
+	return norm.NFC.String(slug.String())
+}
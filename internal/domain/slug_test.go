@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Chocolate Cake", "chocolate-cake"},
+		{"Crème brûlée", "creme-brulee"},
+		{"  Spaghetti  alla   Bolognese!  ", "spaghetti-alla-bolognese"},
+		{"Grandma's Apple Pie", "grandmas-apple-pie"},
+		{"Käsespätzle & Rösti", "kasespatzle-rosti"},
+		{"Smørrebrød", "smorrebrod"},
+		{"Weißwurst", "weisswurst"},
+		{"Œufs à la neige", "oeufs-a-la-neige"},
+		{"Pierogi z Łodzi", "pierogi-z-lodzi"},
+		{"Bibimbap 비빔밥", "bibimbap-비빔밥"},
+		{"7-Minute Eggs", "7-minute-eggs"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.name); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,9 +1,20 @@
 package domain
 
+import "maps"
+
+// Names of the variants generated when an illustration is uploaded.
+const (
+	VariantThumbnail     = "thumbnail"
+	VariantWebP          = "webp"
+	VariantThumbnailWebP = "thumbnail_webp"
+)
+
 type RecipeIllustration struct {
 	ID          string `json:"id"`
 	Description string `json:"description"`
 	Filepath    string `json:"filepath"`
+	// Variants maps a variant name (e.g. VariantThumbnail) to its path.
+	Variants map[string]string `json:"variants,omitempty"`
 }
 
 type RecipeStep struct {
@@ -20,8 +31,96 @@ type Ingredient struct {
 }
 
 type Recipe struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Slug identifies the recipe in URLs, e.g. "creme-brulee". It is unique
+	// and derived from the name unless set.
+	Slug string `json:"slug,omitempty"`
+	// Aliases are the previous slugs of a renamed recipe, which redirect to
+	// the current one.
+	Aliases []string `json:"aliases,omitempty"`
+	// Servings is how many people the recipe feeds, if known.
+	Servings float64 `json:"servings,omitempty"`
+	// Tags classify the recipe, e.g. "dessert" or "vegetarian".
+	Tags []string `json:"tags,omitempty"`
+	// Category groups recipes, e.g. "desserts/cakes". It may be set from the
+	// folder the recipe file is in.
+	Category string `json:"category,omitempty"`
+	// Times and Nutrition are left out when unknown.
+	Times       *Times       `json:"times,omitempty"`
+	Nutrition   *Nutrition   `json:"nutrition,omitempty"`
 	Ingredients []Ingredient `json:"ingredients"`
 	Steps       []RecipeStep `json:"steps"`
 }
+
+// Times says how long a recipe takes, in minutes. Zero is unknown.
+type Times struct {
+	Prep  int `json:"prep,omitempty"`
+	Cook  int `json:"cook,omitempty"`
+	Total int `json:"total,omitempty"`
+}
+
+// Nutrition describes a serving of a recipe: its energy in kilocalories,
+// sodium and cholesterol in milligrams, everything else in grams. Zero is
+// unknown.
+type Nutrition struct {
+	Calories      float64 `json:"calories,omitempty"`
+	Fat           float64 `json:"fat,omitempty"`
+	SaturatedFat  float64 `json:"saturated_fat,omitempty"`
+	Carbohydrates float64 `json:"carbohydrates,omitempty"`
+	Sugar         float64 `json:"sugar,omitempty"`
+	Fiber         float64 `json:"fiber,omitempty"`
+	Protein       float64 `json:"protein,omitempty"`
+	Sodium        float64 `json:"sodium,omitempty"`
+	Cholesterol   float64 `json:"cholesterol,omitempty"`
+}
+
+type nutritionFact struct {
+	name  string
+	value float64
+}
+
+// facts lists the values of n with their JSON names, in order.
+func (n Nutrition) facts() []nutritionFact {
+	return []nutritionFact{
+		{"calories", n.Calories}, {"fat", n.Fat}, {"saturated_fat", n.SaturatedFat},
+		{"carbohydrates", n.Carbohydrates}, {"sugar", n.Sugar}, {"fiber", n.Fiber},
+		{"protein", n.Protein}, {"sodium", n.Sodium}, {"cholesterol", n.Cholesterol},
+	}
+}
+
+// Clone returns a deep copy of the recipe, so that callers can modify the
+// ingredients and steps (e.g. when scaling) without affecting stored data.
+func (r Recipe) Clone() Recipe {
+	clone := r
+	if r.Tags != nil {
+		clone.Tags = append([]string(nil), r.Tags...)
+	}
+	if r.Aliases != nil {
+		clone.Aliases = append([]string(nil), r.Aliases...)
+	}
+	if r.Times != nil {
+		times := *r.Times
+		clone.Times = &times
+	}
+	if r.Nutrition != nil {
+		nutrition := *r.Nutrition
+		clone.Nutrition = &nutrition
+	}
+	if r.Ingredients != nil {
+		clone.Ingredients = append([]Ingredient(nil), r.Ingredients...)
+	}
+	if r.Steps != nil {
+		clone.Steps = make([]RecipeStep, len(r.Steps))
+		for i, step := range r.Steps {
+			clone.Steps[i] = step
+			if step.RecipeIllustration != nil {
+				clone.Steps[i].RecipeIllustration = append([]RecipeIllustration(nil), step.RecipeIllustration...)
+				for j, illustration := range step.RecipeIllustration {
+					clone.Steps[i].RecipeIllustration[j].Variants = maps.Clone(illustration.Variants)
+				}
+			}
+		}
+	}
+	return clone
+}
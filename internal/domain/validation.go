@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// KnownUnits lists the ingredient units accepted by the default validator.
+// An empty unit is allowed for ingredients counted without one.
+var KnownUnits = []string{
+	"", "g", "gram", "grams", "kg", "mg",
+	"ml", "cl", "dl", "l",
+	"tsp", "tbsp", "cup", "cups", "oz", "lb", "fl oz",
+	"pc", "pcs", "piece", "pieces",
+	"pinch", "clove", "cloves", "slice", "slices", "can", "cans", "bunch", "sprig", "sprigs",
+}
+
+// FieldError describes a problem with one field of a recipe. Path uses
+// JSON field names with indices, e.g. "ingredients[2].quantity".
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors is the list of problems found in a recipe.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return "invalid recipe: " + strings.Join(messages, "; ")
+}
+
+// RecipeValidator checks recipes before they are loaded or saved.
+type RecipeValidator interface {
+	// Validate returns nil when the recipe is valid.
+	Validate(recipe Recipe) ValidationErrors
+}
+
+type recipeValidator struct {
+	units map[string]bool
+}
+
+// NewRecipeValidator returns a validator accepting the given units
+// (compared case-insensitively), or KnownUnits if none are given.
+func NewRecipeValidator(units ...string) RecipeValidator {
+	if len(units) == 0 {
+		units = KnownUnits
+	}
+	v := &recipeValidator{units: make(map[string]bool, len(units))}
+	for _, unit := range units {
+		v.units[strings.ToLower(unit)] = true
+	}
+	return v
+}
+
+// Validate checks identifiers, quantities and units of a recipe.
+func (v *recipeValidator) Validate(recipe Recipe) ValidationErrors {
+	var errs ValidationErrors
+	add := func(path, format string, args ...any) {
+		errs = append(errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(recipe.ID) == "" {
+		add("id", "must not be empty")
+	}
+	if strings.TrimSpace(recipe.Name) == "" {
+		add("name", "must not be empty")
+	}
+	if math.IsNaN(recipe.Servings) || math.IsInf(recipe.Servings, 0) || recipe.Servings < 0 {
+		add("servings", "must be a non-negative number")
+	}
+	if t := recipe.Times; t != nil {
+		for _, time := range []struct {
+			name    string
+			minutes int
+		}{{"prep", t.Prep}, {"cook", t.Cook}, {"total", t.Total}} {
+			if time.minutes < 0 {
+				add("times."+time.name, "must not be negative")
+			}
+		}
+	}
+	if n := recipe.Nutrition; n != nil {
+		for _, fact := range n.facts() {
+			if math.IsNaN(fact.value) || math.IsInf(fact.value, 0) || fact.value < 0 {
+				add("nutrition."+fact.name, "must be a non-negative number")
+			}
+		}
+	}
+	tags := make(map[string]int)
+	for i, tag := range recipe.Tags {
+		path := fmt.Sprintf("tags[%d]", i)
+		if strings.TrimSpace(tag) == "" {
+			add(path, "must not be empty")
+		} else if first, ok := tags[strings.ToLower(tag)]; ok {
+			add(path, "duplicates tags[%d]", first)
+		} else {
+			tags[strings.ToLower(tag)] = i
+		}
+	}
+
+	ingredientNames := make(map[string]int)
+	for i, ingredient := range recipe.Ingredients {
+		path := fmt.Sprintf("ingredients[%d]", i)
+		if strings.TrimSpace(ingredient.Name) == "" {
+			add(path+".name", "must not be empty")
+		} else if first, ok := ingredientNames[ingredient.Name]; ok {
+			add(path+".name", "duplicates ingredients[%d]", first)
+		} else {
+			ingredientNames[ingredient.Name] = i
+		}
+		if math.IsNaN(ingredient.Quantity) || math.IsInf(ingredient.Quantity, 0) || ingredient.Quantity < 0 {
+			add(path+".quantity", "must be a non-negative number")
+		}
+		if !v.units[strings.ToLower(ingredient.Unit)] {
+			add(path+".unit", "unknown unit %q", ingredient.Unit)
+		}
+	}
+
+	stepIDs := make(map[string]int)
+	illustrationIDs := make(map[string]string)
+	for i, step := range recipe.Steps {
+		path := fmt.Sprintf("steps[%d]", i)
+		if strings.TrimSpace(step.ID) == "" {
+			add(path+".id", "must not be empty")
+		} else if first, ok := stepIDs[step.ID]; ok {
+			add(path+".id", "duplicates steps[%d]", first)
+		} else {
+			stepIDs[step.ID] = i
+		}
+		for j, illustration := range step.RecipeIllustration {
+			illustrationPath := fmt.Sprintf("%s.illustration[%d]", path, j)
+			if strings.TrimSpace(illustration.ID) == "" {
+				add(illustrationPath+".id", "must not be empty")
+			} else if first, ok := illustrationIDs[illustration.ID]; ok {
+				add(illustrationPath+".id", "duplicates %s", first)
+			} else {
+				illustrationIDs[illustration.ID] = illustrationPath
+			}
+			if strings.TrimSpace(illustration.Filepath) == "" {
+				add(illustrationPath+".filepath", "must not be empty")
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidate_ValidRecipe(t *testing.T) {
+	validator := NewRecipeValidator()
+
+	recipe := Recipe{
+		ID:   "1",
+		Name: "Pancakes",
+		Ingredients: []Ingredient{
+			{Name: "Flour", Quantity: 200, Unit: "g"},
+			{Name: "Eggs", Quantity: 2, Unit: "PC"},
+			{Name: "Salt", Quantity: 0, Unit: ""},
+		},
+		Steps: []RecipeStep{
+			{ID: "step1", RecipeIllustration: []RecipeIllustration{{ID: "i1", Filepath: "/images/1/i1.jpg"}}},
+			{ID: "step2"},
+		},
+	}
+
+	if errs := validator.Validate(recipe); errs != nil {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidate_InvalidRecipe(t *testing.T) {
+	validator := NewRecipeValidator()
+
+	recipe := Recipe{
+		Name:      "",
+		Servings:  -2,
+		Tags:      []string{"Dessert", "dessert"},
+		Times:     &Times{Prep: 10, Cook: -5},
+		Nutrition: &Nutrition{Calories: 250, Sugar: math.Inf(1)},
+		Ingredients: []Ingredient{
+			{Name: "Flour", Quantity: -1, Unit: "g"},
+			{Name: "Flour", Quantity: math.NaN(), Unit: "handful"},
+		},
+		Steps: []RecipeStep{
+			{ID: "step1", RecipeIllustration: []RecipeIllustration{{ID: "i1", Filepath: "/a.jpg"}}},
+			{ID: "step1", RecipeIllustration: []RecipeIllustration{{ID: "i1"}}},
+		},
+	}
+
+	errs := validator.Validate(recipe)
+	expected := map[string]bool{
+		"id":                                true,
+		"name":                              true,
+		"servings":                          true,
+		"tags[1]":                           true,
+		"times.cook":                        true,
+		"nutrition.sugar":                   true,
+		"ingredients[0].quantity":           true,
+		"ingredients[1].name":               true,
+		"ingredients[1].quantity":           true,
+		"ingredients[1].unit":               true,
+		"steps[1].id":                       true,
+		"steps[1].illustration[0].id":       true,
+		"steps[1].illustration[0].filepath": true,
+	}
+	for _, fieldErr := range errs {
+		if !expected[fieldErr.Path] {
+			t.Errorf("unexpected error %v", fieldErr)
+		}
+		delete(expected, fieldErr.Path)
+	}
+	for path := range expected {
+		t.Errorf("expected an error for %s", path)
+	}
+}
+
+func TestValidate_CustomUnits(t *testing.T) {
+	validator := NewRecipeValidator("handful")
+
+	recipe := Recipe{ID: "1", Name: "Salad", Ingredients: []Ingredient{{Name: "Rocket", Quantity: 1, Unit: "handful"}}}
+	if errs := validator.Validate(recipe); errs != nil {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	recipe.Ingredients[0].Unit = "g"
+	if errs := validator.Validate(recipe); len(errs) != 1 {
+		t.Errorf("expected one error, got %v", errs)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build embedfrontend
+
+package frontend
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// dist is the built frontend, copied here before building with
+// -tags embedfrontend (see the Dockerfile).
+//
+//go:embed all:dist
+var dist embed.FS
+
+// Embedded returns the frontend compiled into the binary.
+func Embedded() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
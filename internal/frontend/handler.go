@@ -0,0 +1,172 @@
+// Package frontend serves the built single-page frontend, either compiled
+// into the binary or from a directory on disk.
+package frontend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hashedAsset matches the files Vite writes under assets/ with a hash of
+// their content in the name, e.g. assets/index-BfJ3x9aZ.js. Their content
+// never changes under the same name, so browsers may cache them for good.
+var hashedAsset = regexp.MustCompile(`^assets/.+-[A-Za-z0-9_-]{8,}\.[A-Za-z0-9]+$`)
+
+// encodings lists the precompressed variants looked for next to each file,
+// in order of preference.
+var encodings = []struct {
+	name, ext string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Handler serves the files of a built frontend. Paths without a file
+// extension that match no file are client-side routes of the single-page
+// app, answered with index.html.
+type Handler struct {
+	fsys fs.FS
+
+	mu    sync.Mutex
+	etags map[string]etag
+}
+
+// etag caches the ETag of a file along with what identifies its version.
+type etag struct {
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+// NewHandler serves the frontend in fsys, whose root holds index.html.
+func NewHandler(fsys fs.FS) *Handler {
+	return &Handler{
+		fsys:  fsys,
+		etags: make(map[string]etag),
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+	if !h.isFile(name) {
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = "index.html"
+	}
+	h.serveFile(w, r, name)
+}
+
+// serveFile sends the file name, or its best precompressed variant the
+// client accepts.
+func (h *Handler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Cache-Control", cacheControl(name))
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	served := name
+	for _, encoding := range encodings {
+		if acceptsEncoding(r.Header.Get("Accept-Encoding"), encoding.name) && h.isFile(name+encoding.ext) {
+			served = name + encoding.ext
+			w.Header().Set("Content-Encoding", encoding.name)
+			break
+		}
+	}
+
+	data, info, err := h.read(served)
+	if err != nil {
+		http.Error(w, "failed to read "+name, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", h.etag(served, info, data))
+	// Embedded files have no modification time, ServeContent then relies on
+	// the ETag alone.
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(data))
+}
+
+func (h *Handler) isFile(name string) bool {
+	info, err := fs.Stat(h.fsys, name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func (h *Handler) read(name string) ([]byte, fs.FileInfo, error) {
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, info, nil
+}
+
+// etag returns a strong ETag of the content of a file, computed once per
+// version of the file.
+func (h *Handler) etag(name string, info fs.FileInfo, data []byte) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cached, ok := h.etags[name]
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.value
+	}
+	sum := sha256.Sum256(data)
+	value := strconv.Quote(base64.RawURLEncoding.EncodeToString(sum[:12]))
+	h.etags[name] = etag{modTime: info.ModTime(), size: info.Size(), value: value}
+	return value
+}
+
+// cacheControl lets browsers keep hashed assets for a year, and revalidate
+// everything else, index.html first, so that new builds show up at once.
+func cacheControl(name string) string {
+	if hashedAsset.MatchString(name) {
+		return "public, max-age=31536000, immutable"
+	}
+	return "no-cache"
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header accepts
+// encoding, e.g. "gzip, deflate, br;q=0.9".
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(coding, encoding) && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package frontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":                  {Data: []byte("<html>app</html>")},
+		"favicon.svg":                 {Data: []byte("<svg/>")},
+		"assets/index-BfJ3x9aZ.js":    {Data: []byte("console.log('app')")},
+		"assets/index-BfJ3x9aZ.js.br": {Data: []byte("brotli")},
+		"assets/index-BfJ3x9aZ.js.gz": {Data: []byte("gzip")},
+	}
+	handler := NewHandler(fsys)
+
+	tests := []struct {
+		name           string
+		method, path   string
+		acceptEncoding string
+		wantStatus     int
+		wantBody       string
+		wantCache      string
+		wantEncoding   string
+	}{
+		{"index", "GET", "/", "", http.StatusOK, "<html>app</html>", "no-cache", ""},
+		{"client route", "GET", "/recipes/42/edit", "", http.StatusOK, "<html>app</html>", "no-cache", ""},
+		{"missing asset", "GET", "/assets/missing.js", "", http.StatusNotFound, "", "", ""},
+		{"static file", "GET", "/favicon.svg", "", http.StatusOK, "<svg/>", "no-cache", ""},
+		{"hashed asset", "GET", "/assets/index-BfJ3x9aZ.js", "", http.StatusOK, "console.log('app')", "public, max-age=31536000, immutable", ""},
+		{"brotli", "GET", "/assets/index-BfJ3x9aZ.js", "gzip, deflate, br", http.StatusOK, "brotli", "public, max-age=31536000, immutable", "br"},
+		{"gzip", "GET", "/assets/index-BfJ3x9aZ.js", "gzip, br;q=0", http.StatusOK, "gzip", "public, max-age=31536000, immutable", "gzip"},
+		{"no variant", "GET", "/favicon.svg", "br", http.StatusOK, "<svg/>", "no-cache", ""},
+		{"escape", "GET", "/../../etc/passwd", "", http.StatusOK, "<html>app</html>", "no-cache", ""},
+		{"post", "POST", "/", "", http.StatusMethodNotAllowed, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("expected Cache-Control %q, got %q", tt.wantCache, got)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.wantEncoding, got)
+			}
+			if got := rec.Header().Get("Content-Type"); got == "" || got == "application/octet-stream" {
+				t.Errorf("expected the content type of the original file, got %q", got)
+			}
+		})
+	}
+}
+
+func TestHandler_ETag(t *testing.T) {
+	handler := NewHandler(fstest.MapFS{"index.html": {Data: []byte("<html>app</html>")}})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching ETag, got %d", rec.Code)
+	}
+}
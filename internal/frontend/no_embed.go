@@ -0,0 +1,11 @@
+//go:build !embedfrontend
+
+package frontend
+
+import "io/fs"
+
+// Embedded returns nil: the binary was built without -tags embedfrontend, so
+// the frontend can only be served from disk.
+func Embedded() fs.FS {
+	return nil
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// Credentials is the body of the register and login requests.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// HouseholdRequest is the body of the create household request.
+type HouseholdRequest struct {
+	Name string `json:"name"`
+}
+
+// MemberRequest is the body of the add household member request.
+type MemberRequest struct {
+	Username string `json:"username"`
+}
+
+// VisibilityRequest is the body of the set recipe visibility request.
+type VisibilityRequest struct {
+	Visibility domain.Visibility `json:"visibility"`
+	// OwnerID hands the recipe to another user; only admins may set it.
+	OwnerID string `json:"owner_id,omitempty"`
+}
+
+type AccountHandler struct {
+	registerUserUC        usecase.RegisterUserUseCase
+	loginUC               usecase.LoginUseCase
+	logoutUC              usecase.LogoutUseCase
+	getProfileUC          usecase.GetProfileUseCase
+	createHouseholdUC     usecase.CreateHouseholdUseCase
+	addHouseholdMemberUC  usecase.AddHouseholdMemberUseCase
+	setRecipeVisibilityUC usecase.SetRecipeVisibilityUseCase
+}
+
+func NewAccountHandler(
+	registerUserUC usecase.RegisterUserUseCase,
+	loginUC usecase.LoginUseCase,
+	logoutUC usecase.LogoutUseCase,
+	getProfileUC usecase.GetProfileUseCase,
+	createHouseholdUC usecase.CreateHouseholdUseCase,
+	addHouseholdMemberUC usecase.AddHouseholdMemberUseCase,
+	setRecipeVisibilityUC usecase.SetRecipeVisibilityUseCase,
+) *AccountHandler {
+	return &AccountHandler{
+		registerUserUC:        registerUserUC,
+		loginUC:               loginUC,
+		logoutUC:              logoutUC,
+		getProfileUC:          getProfileUC,
+		createHouseholdUC:     createHouseholdUC,
+		addHouseholdMemberUC:  addHouseholdMemberUC,
+		setRecipeVisibilityUC: setRecipeVisibilityUC,
+	}
+}
+
+// decodeJSON reads the JSON body of r into v, answering 400 or 413 on failure.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeDecodeError(w, "invalid request body", err)
+		return false
+	}
+	return true
+}
+
+// Register godoc
+// @Summary      Create a user
+// @Description  Creates a user with a username of 3 to 32 lowercase letters, digits, '.', '_' or '-' and a password
+// @Description  of at least 8 characters. Needs the admin scope unless registration is open.
+// @Tags         accounts
+// @Param        credentials  body  Credentials  true  "Username and password"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  domain.User
+// @Failure      400  {string}  string "invalid username or password"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      409  {string}  string "username already taken"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /users [post]
+func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var credentials Credentials
+	if !decodeJSON(w, r, &credentials) {
+		return
+	}
+
+	user, err := h.registerUserUC.Execute(r.Context(), credentials.Username, credentials.Password)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Info("registered user", "user_id", user.ID, "username", user.Username)
+	writeJSON(w, r, http.StatusCreated, user)
+}
+
+// Login godoc
+// @Summary      Log in
+// @Description  Checks the password of a user and returns a session token to send as `Authorization: Bearer <token>`.
+// @Tags         accounts
+// @Param        credentials  body  Credentials  true  "Username and password"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  usecase.LoginResult
+// @Failure      400  {string}  string "invalid request body"
+// @Failure      401  {string}  string "invalid username or password"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /auth/login [post]
+func (h *AccountHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var credentials Credentials
+	if !decodeJSON(w, r, &credentials) {
+		return
+	}
+
+	result, err := h.loginUC.Execute(r.Context(), credentials.Username, credentials.Password)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Debug("logged in", "user_id", result.User.ID)
+	writeJSON(w, r, http.StatusOK, result)
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Ends the session of the bearer token of the request.
+// @Tags         accounts
+// @Success      204
+// @Failure      401  {string}  string "user session required"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /auth/logout [post]
+func (h *AccountHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.logoutUC.Execute(r.Context(), auth.SessionToken(r)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me godoc
+// @Summary      Retrieve the logged in user
+// @Description  Returns the user of the session with their household and its members.
+// @Tags         accounts
+// @Produce      json
+// @Success      200  {object}  usecase.Profile
+// @Failure      401  {string}  string "user session required"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me [get]
+func (h *AccountHandler) Me(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.getProfileUC.Execute(r.Context(), requestViewer(r).UserID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, profile)
+}
+
+// CreateHousehold godoc
+// @Summary      Create a household
+// @Description  Creates a household and moves the logged in user into it, along with the recipes they own.
+// @Tags         accounts
+// @Param        household  body  HouseholdRequest  true  "Household name"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  domain.Household
+// @Failure      400  {string}  string "invalid household name"
+// @Failure      401  {string}  string "user session required"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /households [post]
+func (h *AccountHandler) CreateHousehold(w http.ResponseWriter, r *http.Request) {
+	var request HouseholdRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	household, err := h.createHouseholdUC.Execute(r.Context(), requestViewer(r).UserID, request.Name)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, household)
+}
+
+// AddHouseholdMember godoc
+// @Summary      Add a member to a household
+// @Description  Moves a user into the household of the logged in user, along with the recipes they own.
+// @Tags         accounts
+// @Param        householdID  path  string         true  "Household ID"
+// @Param        member       body  MemberRequest  true  "Username of the new member"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  domain.User
+// @Failure      400  {string}  string "invalid request body"
+// @Failure      401  {string}  string "user session required"
+// @Failure      404  {string}  string "household or user not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /households/{householdID}/members [post]
+func (h *AccountHandler) AddHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	var request MemberRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	member, err := h.addHouseholdMemberUC.Execute(r.Context(), requestViewer(r).UserID, r.PathValue("householdID"), request.Username)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, member)
+}
+
+// SetRecipeVisibility godoc
+// @Summary      Change who may see a recipe
+// @Description  Sets a recipe private, household or public. Only its owner and admins may. Admins may also hand
+// @Description  the recipe to another user with owner_id; recipes nobody owns need one to change their visibility.
+// @Tags         accounts
+// @Param        recipeID    path  string             true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        visibility  body  VisibilityRequest  true  "New visibility"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  domain.RecipeAccess
+// @Failure      400  {string}  string "unknown visibility or owner, or recipe without owner"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "not the owner of the recipe or not an admin"
+// @Failure      404  {string}  string "recipe not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/visibility [put]
+func (h *AccountHandler) SetRecipeVisibility(w http.ResponseWriter, r *http.Request) {
+	var request VisibilityRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	visibility, err := domain.ParseVisibility(string(request.Visibility))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	access, err := h.setRecipeVisibilityUC.Execute(r.Context(), requestViewer(r), r.PathValue("recipeID"), visibility, request.OwnerID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, access)
+}
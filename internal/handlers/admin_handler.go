@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+type AdminHandler struct {
+	getValidationReportUC usecase.GetValidationReportUseCase
+	reloadRecipesUC       usecase.ReloadRecipesUseCase
+}
+
+func NewAdminHandler(
+	getValidationReportUC usecase.GetValidationReportUseCase,
+	reloadRecipesUC usecase.ReloadRecipesUseCase,
+) *AdminHandler {
+	return &AdminHandler{
+		getValidationReportUC: getValidationReportUC,
+		reloadRecipesUC:       reloadRecipesUC,
+	}
+}
+
+// ValidationReport godoc
+// @Summary      Report invalid recipe files
+// @Description  Lists the recipe files that failed validation when the repository was loaded. In lenient mode these
+// @Description  files were skipped; in strict mode the server would not have started.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  repository.ValidationReport
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /admin/validation [get]
+func (h *AdminHandler) ValidationReport(w http.ResponseWriter, r *http.Request) {
+	logging.FromContext(r.Context()).Debug("reporting recipe validation")
+
+	report, err := h.getValidationReportUC.Execute(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
+
+// Reload godoc
+// @Summary      Reload the recipe files
+// @Description  Reads the recipe directory again, e.g. after files were edited on disk. If loading fails, the
+// @Description  previously loaded recipes are kept. The server also reloads on SIGHUP.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  repository.ValidationReport
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      500  {string}  string "failed to reload recipes"
+// @Router       /admin/reload [post]
+func (h *AdminHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	logging.FromContext(r.Context()).Debug("reloading recipes")
+
+	report, err := h.reloadRecipesUC.Execute(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/ratelimit"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// Authenticator identifies the client sending a request. It returns a nil
+// principal when the request carries no credentials.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*auth.Principal, error)
+}
+
+// anonymousAccess says which routes clients without credentials may use.
+type anonymousAccess int
+
+const (
+	anonymousNone anonymousAccess = iota
+	anonymousRead
+	anonymousAll
+)
+
+// allows reports whether anonymous clients may use a route needing scope.
+func (a anonymousAccess) allows(scope string) bool {
+	return a == anonymousAll || (a == anonymousRead && scope == auth.ScopeRead)
+}
+
+// requireScope wraps a route so that it is only served to clients granted
+// scope. The principal is stored in the request context for the handler.
+// Without an authenticator every route is open; otherwise anonymous says
+// which routes clients without credentials may use.
+func requireScope(authenticator Authenticator, anonymous anonymousAccess, scope string) func(http.HandlerFunc) http.Handler {
+	return func(next http.HandlerFunc) http.Handler {
+		if authenticator == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := logging.FromContext(r.Context())
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				logger.Debug("rejected credentials", "error", err)
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, "invalid credentials", http.StatusUnauthorized)
+				return
+			}
+			if principal == nil {
+				if anonymous.allows(scope) {
+					next(w, r)
+					return
+				}
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "authentication required", http.StatusUnauthorized)
+				return
+			}
+			if !principal.HasScope(scope) {
+				logger.Debug("missing scope", "principal", principal.Subject, "scope", scope)
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scope))
+				http.Error(w, "missing scope "+scope, http.StatusForbidden)
+				return
+			}
+
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("enduser.id", principal.Subject))
+			ctx := auth.WithPrincipal(r.Context(), principal)
+			ctx = logging.WithLogger(ctx, logger.With("principal", principal.Subject))
+			next(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// scopedRoute wraps a route needing scope, rate limited by ipLimiter before
+// the client is authenticated and by principalLimiter after.
+func scopedRoute(authenticator Authenticator, anonymous anonymousAccess, scope string, ipLimiter, principalLimiter *ratelimit.Limiter) func(http.HandlerFunc) http.Handler {
+	authorize := requireScope(authenticator, anonymous, scope)
+	limitIP, limitPrincipal := limitRate(ipLimiter), limitPrincipalRate(principalLimiter)
+	return func(next http.HandlerFunc) http.Handler {
+		return limitIP(authorize(limitPrincipal(next)).ServeHTTP)
+	}
+}
+
+// requireUser wraps a route so that it is only served to clients logged in
+// as a user, rather than anonymous clients or API keys.
+func requireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if requestViewer(r).UserID == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "user session required", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requestViewer returns who is sending a request, to decide which recipes
+// they may see and change.
+func requestViewer(r *http.Request) domain.Viewer {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		return domain.Viewer{}
+	}
+	return domain.Viewer{
+		UserID:      principal.UserID,
+		HouseholdID: principal.HouseholdID,
+		Admin:       principal.HasScope(auth.ScopeAdmin),
+	}
+}
+
+// resolveRecipeKey wraps a route of the recipe named by the recipeID path
+// value, which may be its ID, slug or an alias, so that the route sees its ID.
+// Keys of no recipe are passed on unchanged, e.g. to create a recipe.
+func resolveRecipeKey(resolveRecipeUC usecase.ResolveRecipeUseCase) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ref, err := resolveRecipeUC.Execute(r.Context(), r.PathValue("recipeID"))
+			switch {
+			case err == nil:
+				r.SetPathValue("recipeID", ref.ID)
+			case !errors.Is(err, repository.ErrNotFound):
+				writeError(w, r, err)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// requireRecipeAccess wraps a route of the recipe named by the recipeID path
+// value, resolved as by resolveRecipeKey, so that it is only served to
+// clients who may see the recipe, and change it if edit is set.
+func requireRecipeAccess(resolveRecipeUC usecase.ResolveRecipeUseCase, authorizeRecipeUC usecase.AuthorizeRecipeUseCase, edit bool) func(http.HandlerFunc) http.HandlerFunc {
+	resolve := resolveRecipeKey(resolveRecipeUC)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return resolve(func(w http.ResponseWriter, r *http.Request) {
+			if err := authorizeRecipeUC.Execute(r.Context(), requestViewer(r), r.PathValue("recipeID"), edit); err != nil {
+				writeError(w, r, err)
+				return
+			}
+			next(w, r)
+		})
+	}
+}
+
+// requireImageAccess wraps the route of stored images, so that an image is
+// only served to clients who may see a recipe it illustrates. Images of
+// hidden recipes answer 404 like missing ones.
+func requireImageAccess(findImageRecipesUC usecase.FindImageRecipesUseCase, authorizeRecipeUC usecase.AuthorizeRecipeUseCase) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			recipeIDs, err := findImageRecipesUC.Execute(r.Context(), strings.TrimPrefix(r.URL.Path, usecase.ImageURLPrefix))
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+			viewer := requestViewer(r)
+			for _, recipeID := range recipeIDs {
+				if err = authorizeRecipeUC.Execute(r.Context(), viewer, recipeID, false); err == nil {
+					next(w, r)
+					return
+				}
+				if !errors.Is(err, repository.ErrNotFound) {
+					break
+				}
+			}
+			writeError(w, r, err)
+		}
+	}
+}
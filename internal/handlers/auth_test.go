@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// fakeAuthenticator maps the X-API-Key header to principals.
+type fakeAuthenticator map[string]*auth.Principal
+
+func (f fakeAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	key := r.Header.Get(auth.APIKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+	principal, ok := f[key]
+	if !ok {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return principal, nil
+}
+
+func TestRequireScope(t *testing.T) {
+	authenticator := fakeAuthenticator{
+		"reader": {Subject: "reader", Scopes: []string{auth.ScopeRead}},
+		"writer": {Subject: "writer", Scopes: []string{auth.ScopeRead, auth.ScopeWrite}},
+		"admin":  {Subject: "ops", Scopes: []string{auth.ScopeAdmin}},
+	}
+	var author string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		author = requestAuthor(r)
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name       string
+		scope      string
+		anonymous  anonymousAccess
+		key        string
+		wantStatus int
+		wantAuthor string
+	}{
+		{"anonymous read allowed", auth.ScopeRead, anonymousRead, "", http.StatusNoContent, "header"},
+		{"anonymous read refused", auth.ScopeRead, anonymousNone, "", http.StatusUnauthorized, ""},
+		{"anonymous write", auth.ScopeWrite, anonymousRead, "", http.StatusUnauthorized, ""},
+		{"anonymous write allowed", auth.ScopeWrite, anonymousAll, "", http.StatusNoContent, "header"},
+		{"invalid key with anonymous access", auth.ScopeWrite, anonymousAll, "wrong", http.StatusUnauthorized, ""},
+		{"invalid key", auth.ScopeRead, anonymousRead, "wrong", http.StatusUnauthorized, ""},
+		{"reader reads", auth.ScopeRead, anonymousNone, "reader", http.StatusNoContent, "reader"},
+		{"reader writes", auth.ScopeWrite, anonymousNone, "reader", http.StatusForbidden, ""},
+		{"writer writes", auth.ScopeWrite, anonymousNone, "writer", http.StatusNoContent, "writer"},
+		{"writer administers", auth.ScopeAdmin, anonymousNone, "writer", http.StatusForbidden, ""},
+		{"admin writes", auth.ScopeWrite, anonymousNone, "admin", http.StatusNoContent, "ops"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			author = ""
+			r := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+			if tt.key != "" {
+				r.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			// The principal wins over the X-Author header.
+			r.Header.Set("X-Author", "header")
+			w := httptest.NewRecorder()
+			requireScope(authenticator, tt.anonymous, tt.scope)(handler).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if author != tt.wantAuthor {
+				t.Errorf("expected author %q, got %q", tt.wantAuthor, author)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestRequireScope_Disabled(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+	requireScope(nil, anonymousNone, auth.ScopeAdmin)(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected open routes without an authenticator, got %d", w.Code)
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	authenticator := fakeAuthenticator{
+		"ci":    {Subject: "ci", Scopes: []string{auth.ScopeRead}},
+		"alice": {Subject: "alice", Scopes: []string{auth.ScopeRead}, UserID: "u1", HouseholdID: "h1"},
+	}
+	var viewer domain.Viewer
+	handler := requireScope(authenticator, anonymousRead, auth.ScopeRead)(requireUser(func(w http.ResponseWriter, r *http.Request) {
+		viewer = requestViewer(r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for key, want := range map[string]int{"": http.StatusUnauthorized, "ci": http.StatusUnauthorized, "alice": http.StatusNoContent} {
+		r := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if key != "" {
+			r.Header.Set(auth.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("%q: expected status %d, got %d", key, want, w.Code)
+		}
+	}
+	if viewer != (domain.Viewer{UserID: "u1", HouseholdID: "h1"}) {
+		t.Errorf("expected the viewer of alice, got %+v", viewer)
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// CollectionRequest is the body of the create and update collection requests.
+type CollectionRequest struct {
+	Name      string   `json:"name"`
+	RecipeIDs []string `json:"recipe_ids"`
+}
+
+type CollectionHandler struct {
+	listFavoritesUC    usecase.ListFavoritesUseCase
+	setFavoriteUC      usecase.SetFavoriteUseCase
+	listCollectionsUC  usecase.ListCollectionsUseCase
+	getCollectionUC    usecase.GetCollectionUseCase
+	saveCollectionUC   usecase.SaveCollectionUseCase
+	deleteCollectionUC usecase.DeleteCollectionUseCase
+}
+
+func NewCollectionHandler(
+	listFavoritesUC usecase.ListFavoritesUseCase,
+	setFavoriteUC usecase.SetFavoriteUseCase,
+	listCollectionsUC usecase.ListCollectionsUseCase,
+	getCollectionUC usecase.GetCollectionUseCase,
+	saveCollectionUC usecase.SaveCollectionUseCase,
+	deleteCollectionUC usecase.DeleteCollectionUseCase,
+) *CollectionHandler {
+	return &CollectionHandler{
+		listFavoritesUC:    listFavoritesUC,
+		setFavoriteUC:      setFavoriteUC,
+		listCollectionsUC:  listCollectionsUC,
+		getCollectionUC:    getCollectionUC,
+		saveCollectionUC:   saveCollectionUC,
+		deleteCollectionUC: deleteCollectionUC,
+	}
+}
+
+// ListFavorites godoc
+// @Summary      List the favorite recipes of the logged in user
+// @Description  Returns the favorite recipes in the order they were added, leaving out recipes no longer visible.
+// @Tags         collections
+// @Produce      json
+// @Success      200  {array}   domain.Recipe
+// @Failure      401  {string}  string "user session required"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/favorites [get]
+func (h *CollectionHandler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	favorites, err := h.listFavoritesUC.Execute(r.Context(), requestViewer(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, favorites)
+}
+
+// AddFavorite godoc
+// @Summary      Add a recipe to the favorites of the logged in user
+// @Tags         collections
+// @Param        recipeID  path  string  true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Success      204
+// @Failure      401  {string}  string "user session required"
+// @Failure      404  {string}  string "recipe not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/favorites/{recipeID} [put]
+func (h *CollectionHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setFavorite(w, r, true)
+}
+
+// RemoveFavorite godoc
+// @Summary      Remove a recipe from the favorites of the logged in user
+// @Tags         collections
+// @Param        recipeID  path  string  true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Success      204
+// @Failure      401  {string}  string "user session required"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/favorites/{recipeID} [delete]
+func (h *CollectionHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setFavorite(w, r, false)
+}
+
+func (h *CollectionHandler) setFavorite(w http.ResponseWriter, r *http.Request, favorite bool) {
+	if err := h.setFavoriteUC.Execute(r.Context(), requestViewer(r), r.PathValue("recipeID"), favorite); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCollections godoc
+// @Summary      List the collections of the logged in user
+// @Tags         collections
+// @Produce      json
+// @Success      200  {array}   domain.Collection
+// @Failure      401  {string}  string "user session required"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/collections [get]
+func (h *CollectionHandler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := h.listCollectionsUC.Execute(r.Context(), requestViewer(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, collections)
+}
+
+// GetCollection godoc
+// @Summary      Retrieve a collection of the logged in user
+// @Tags         collections
+// @Param        collectionID  path  string  true  "Collection ID"
+// @Produce      json
+// @Success      200  {object}  domain.Collection
+// @Failure      401  {string}  string "user session required"
+// @Failure      404  {string}  string "collection not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/collections/{collectionID} [get]
+func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	collection, err := h.getCollectionUC.Execute(r.Context(), requestViewer(r), r.PathValue("collectionID"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, collection)
+}
+
+// CreateCollection godoc
+// @Summary      Create a collection for the logged in user
+// @Tags         collections
+// @Param        collection  body  CollectionRequest  true  "Name and recipes of the collection"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  domain.Collection
+// @Failure      400  {string}  string "invalid collection"
+// @Failure      401  {string}  string "user session required"
+// @Failure      404  {string}  string "recipe not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/collections [post]
+func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	h.saveCollection(w, r, "", http.StatusCreated)
+}
+
+// UpdateCollection godoc
+// @Summary      Replace the name and recipes of a collection of the logged in user
+// @Tags         collections
+// @Param        collectionID  path  string             true  "Collection ID"
+// @Param        collection    body  CollectionRequest  true  "Name and recipes of the collection"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  domain.Collection
+// @Failure      400  {string}  string "invalid collection"
+// @Failure      401  {string}  string "user session required"
+// @Failure      404  {string}  string "collection or recipe not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/collections/{collectionID} [put]
+func (h *CollectionHandler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	h.saveCollection(w, r, r.PathValue("collectionID"), http.StatusOK)
+}
+
+func (h *CollectionHandler) saveCollection(w http.ResponseWriter, r *http.Request, collectionID string, status int) {
+	var request CollectionRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	collection := domain.Collection{ID: collectionID, Name: request.Name, RecipeIDs: request.RecipeIDs}
+	saved, err := h.saveCollectionUC.Execute(r.Context(), requestViewer(r), collection)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, status, saved)
+}
+
+// DeleteCollection godoc
+// @Summary      Delete a collection of the logged in user
+// @Tags         collections
+// @Param        collectionID  path  string  true  "Collection ID"
+// @Success      204
+// @Failure      401  {string}  string "user session required"
+// @Failure      404  {string}  string "collection not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /me/collections/{collectionID} [delete]
+func (h *CollectionHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	if err := h.deleteCollectionUC.Execute(r.Context(), requestViewer(r), r.PathValue("collectionID")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
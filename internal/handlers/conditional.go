@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/repository"
+)
+
+// variantETag derives the entity tag of a representation that depends on
+// request parameters (scaling, filtering) from the version of the data behind it.
+func variantETag(base string, params ...string) string {
+	if len(params) == 0 || strings.Join(params, "") == "" {
+		return base
+	}
+	hash := sha256.Sum256([]byte(strings.Join(params, "\x00")))
+	return base + "-" + hex.EncodeToString(hash[:4])
+}
+
+// writeValidators sets the ETag and Last-Modified headers for a response.
+func writeValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", fmt.Sprintf("%q", etag))
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// checkNotModified writes the validators for the given version and answers
+// 304 Not Modified if the client's cached copy is still current. It returns
+// true when the response has been fully written.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	writeValidators(w, etag, lastModified)
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110, 13.2.2).
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatches(inm, etag, true) {
+			return false
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// checkIfMatch enforces optimistic concurrency on write requests: when the
+// client sends If-Match and it does not match the current version, the request
+// is rejected with 412 Precondition Failed. It returns true when the response
+// has been fully written.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, current repository.Version, exists bool) bool {
+	im := r.Header.Get("If-Match")
+	if im == "" {
+		return false
+	}
+	if exists && etagMatches(im, current.ETag, false) {
+		return false
+	}
+	http.Error(w, "precondition failed: recipe has been modified", http.StatusPreconditionFailed)
+	return true
+}
+
+// etagMatches reports whether the header value (a list of entity tags or "*")
+// matches etag. Weak comparison ignores the W/ prefix, as required for
+// If-None-Match; strong comparison is used for If-Match.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if strings.Trim(candidate, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
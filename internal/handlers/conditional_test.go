@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/repository"
+)
+
+func TestCheckNotModified_IfNoneMatch(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"no header", "", false},
+		{"matching tag", `"abc"`, true},
+		{"weak matching tag", `W/"abc"`, true},
+		{"tag in list", `"xyz", "abc"`, true},
+		{"wildcard", "*", true},
+		{"other tag", `"xyz"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/recipe/1", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+
+			got := checkNotModified(rec, req, "abc", lastModified)
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if got && rec.Code != http.StatusNotModified {
+				t.Errorf("expected status 304, got %d", rec.Code)
+			}
+			if rec.Header().Get("ETag") != `"abc"` {
+				t.Errorf("expected ETag header \"abc\", got %q", rec.Header().Get("ETag"))
+			}
+			if rec.Header().Get("Last-Modified") != "Tue, 02 Jan 2024 03:04:05 GMT" {
+				t.Errorf("unexpected Last-Modified header %q", rec.Header().Get("Last-Modified"))
+			}
+		})
+	}
+}
+
+func TestCheckNotModified_IfModifiedSince(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 500, time.UTC)
+
+	req := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+	req.Header.Set("If-Modified-Since", "Tue, 02 Jan 2024 03:04:05 GMT")
+	if !checkNotModified(httptest.NewRecorder(), req, "abc", lastModified) {
+		t.Error("expected not modified when the date matches the last modification")
+	}
+
+	req.Header.Set("If-Modified-Since", "Mon, 01 Jan 2024 00:00:00 GMT")
+	if checkNotModified(httptest.NewRecorder(), req, "abc", lastModified) {
+		t.Error("expected a full response when modified after the given date")
+	}
+
+	// If-None-Match wins over If-Modified-Since.
+	req.Header.Set("If-Modified-Since", "Tue, 02 Jan 2024 03:04:05 GMT")
+	req.Header.Set("If-None-Match", `"xyz"`)
+	if checkNotModified(httptest.NewRecorder(), req, "abc", lastModified) {
+		t.Error("expected If-None-Match to take precedence")
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	current := repository.Version{ETag: "abc"}
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		exists  bool
+		want    bool
+	}{
+		{"no header", "", true, false},
+		{"matching tag", `"abc"`, true, false},
+		{"stale tag", `"xyz"`, true, true},
+		{"weak tag never matches", `W/"abc"`, true, true},
+		{"wildcard on existing", "*", true, false},
+		{"wildcard on missing", "*", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/recipe/1", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+
+			got := checkIfMatch(rec, req, current, tt.exists)
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if got && rec.Code != http.StatusPreconditionFailed {
+				t.Errorf("expected status 412, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestVariantETag(t *testing.T) {
+	if variantETag("abc") != "abc" || variantETag("abc", "", "") != "abc" {
+		t.Error("expected the base tag when no parameters are set")
+	}
+	scaled := variantETag("abc", "Flour", "300")
+	if scaled == "abc" || scaled == variantETag("abc", "Flour", "400") {
+		t.Errorf("expected distinct tags per parameter set, got %s", scaled)
+	}
+}
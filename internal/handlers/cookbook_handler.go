@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/fromenjn/recipe-manager/internal/render"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+type CookbookHandler struct {
+	getCookbookUC usecase.GetCookbookUseCase
+	getImageUC    usecase.GetImageUseCase
+}
+
+func NewCookbookHandler(getCookbookUC usecase.GetCookbookUseCase, getImageUC usecase.GetImageUseCase) *CookbookHandler {
+	return &CookbookHandler{
+		getCookbookUC: getCookbookUC,
+		getImageUC:    getImageUC,
+	}
+}
+
+// GetCookbook godoc
+// @Summary      Export recipes as a PDF cookbook
+// @Description  Binds recipes into a printable book with a table of contents and an index of ingredients. The
+// @Description  recipes are those of a collection of the logged in user, or the given ones in order, or else every
+// @Description  recipe the client may see, sorted by name.
+// @Tags         recipes
+// @Param        collection  query     string    false  "Collection whose recipes to bind, also giving the title"
+// @Param        id          query     []string  false  "Recipes to bind, in order" collectionFormat(multi)
+// @Param        title       query     string    false  "Title of the cookbook"
+// @Produce      application/pdf
+// @Success      200  {file}    file
+// @Failure      400  {string}  string "collection and id are exclusive"
+// @Failure      403  {string}  string "collections need a user session"
+// @Failure      404  {string}  string "recipe or collection not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /cookbook.pdf [get]
+func (h *CookbookHandler) GetCookbook(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selection := usecase.CookbookSelection{CollectionID: query.Get("collection"), RecipeIDs: query["id"]}
+	if selection.CollectionID != "" && len(selection.RecipeIDs) > 0 {
+		http.Error(w, "collection and id are exclusive", http.StatusBadRequest)
+		return
+	}
+
+	cookbook, err := h.getCookbookUC.Execute(r.Context(), requestViewer(r), selection)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if title := strings.TrimSpace(query.Get("title")); title != "" {
+		cookbook.Title = title
+	}
+
+	var body bytes.Buffer
+	if err := render.Cookbook(&body, cookbook.Title, cookbook.Recipes, illustrations(r.Context(), h.getImageUC)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writePDF(w, "cookbook", body.Bytes())
+}
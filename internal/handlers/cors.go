@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy lists what cross-origin browsers may do with the API.
+type CORSPolicy struct {
+	// AllowedOrigins lists the origins allowed to call the API, e.g.
+	// "https://recipes.example.com". "*" matches any origin and a pattern
+	// such as "https://*.example.com" matches the subdomains of a domain.
+	// An empty list refuses every cross-origin request.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders are what preflight requests may ask for.
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers scripts may read, e.g. ETag.
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and Authorization headers.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the answer to a preflight request.
+	MaxAge time.Duration
+}
+
+// allowsOrigin reports whether origin matches one of the allowed origins.
+func (p CORSPolicy) allowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range p.AllowedOrigins {
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(strings.ToLower(pattern), origin); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowsMethod(method string) bool {
+	// Browsers never preflight simple methods, but some clients still ask.
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodPost ||
+		slices.Contains(p.AllowedMethods, method)
+}
+
+// allowsHeaders reports whether every header of the comma-separated
+// Access-Control-Request-Headers list is allowed.
+func (p CORSPolicy) allowsHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !slices.ContainsFunc(p.AllowedHeaders, func(allowed string) bool { return strings.EqualFold(allowed, header) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithCORS applies policy to the requests served by mux. It echoes the origin
+// of allowed cross-origin requests, answers allowed preflight requests itself
+// and refuses the others with 403 Forbidden, or 404 Not Found when no API
+// route serves the requested method and path. Other requests are passed on, without
+// CORS headers when their origin is not allowed, so that browsers block them.
+func WithCORS(policy CORSPolicy, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Responses differ by origin, so shared caches must keep them apart.
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		requestedMethod := r.Header.Get("Access-Control-Request-Method")
+		if origin == "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		if r.Method == http.MethodOptions && requestedMethod != "" {
+			preflight(policy, mux, w, r, origin, requestedMethod)
+			return
+		}
+
+		if policy.allowsOrigin(origin) {
+			setAllowOrigin(policy, w, origin)
+			if len(policy.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func preflight(policy CORSPolicy, mux *http.ServeMux, w http.ResponseWriter, r *http.Request, origin, requestedMethod string) {
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+	requestedHeaders := r.Header.Get("Access-Control-Request-Headers")
+	if !policy.allowsOrigin(origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if !policy.allowsMethod(requestedMethod) || !policy.allowsHeaders(requestedHeaders) {
+		http.Error(w, "method or headers not allowed", http.StatusForbidden)
+		return
+	}
+
+	// Only preflight requests to API routes that exist. The frontend serves
+	// every other path, but not to other origins.
+	actual := r.Clone(r.Context())
+	actual.Method = requestedMethod
+	if _, pattern := mux.Handler(actual); pattern == "" || pattern == frontendPattern {
+		http.NotFound(w, r)
+		return
+	}
+
+	setAllowOrigin(policy, w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+	if requestedHeaders != "" {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+	}
+	if policy.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setAllowOrigin(policy CORSPolicy, w http.ResponseWriter, origin string) {
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
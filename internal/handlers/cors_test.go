@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/frontend"
+)
+
+func TestWithCORS(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /recipes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("PUT /recipe/{recipeID}", func(w http.ResponseWriter, r *http.Request) {})
+	// The frontend catch-all serves every other path.
+	mux.Handle(frontendPattern, frontend.NewHandler(fstest.MapFS{"index.html": {Data: []byte("<html></html>")}}))
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.recipes.dev"},
+		AllowedMethods:   []string{"GET", "PUT"},
+		AllowedHeaders:   []string{"Content-Type", "If-Match"},
+		ExposedHeaders:   []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	handler := WithCORS(policy, mux)
+
+	tests := []struct {
+		name           string
+		method, path   string
+		origin         string
+		requestMethod  string
+		requestHeaders string
+		wantStatus     int
+		wantOrigin     string
+	}{
+		{"same origin", "GET", "/recipes", "", "", "", http.StatusOK, ""},
+		{"allowed origin", "GET", "/recipes", "https://app.example.com", "", "", http.StatusOK, "https://app.example.com"},
+		{"wildcard origin", "GET", "/recipes", "https://preview.recipes.dev", "", "", http.StatusOK, "https://preview.recipes.dev"},
+		{"other origin", "GET", "/recipes", "https://evil.example", "", "", http.StatusOK, ""},
+		{"preflight", "OPTIONS", "/recipe/1", "https://app.example.com", "PUT", "content-type, if-match", http.StatusNoContent, "https://app.example.com"},
+		{"preflight from other origin", "OPTIONS", "/recipe/1", "https://evil.example", "PUT", "", http.StatusForbidden, ""},
+		{"preflight for other method", "OPTIONS", "/recipe/1", "https://app.example.com", "DELETE", "", http.StatusForbidden, ""},
+		{"preflight for other header", "OPTIONS", "/recipe/1", "https://app.example.com", "PUT", "X-Secret", http.StatusForbidden, ""},
+		{"preflight for unknown route", "OPTIONS", "/unknown", "https://app.example.com", "GET", "", http.StatusNotFound, ""},
+		{"preflight for unknown API route", "OPTIONS", "/recipes/1/unknown", "https://app.example.com", "PUT", "", http.StatusNotFound, ""},
+		{"plain OPTIONS", "OPTIONS", "/recipes", "https://app.example.com", "", "", http.StatusMethodNotAllowed, "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			if tt.requestHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.requestHeaders)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("expected allowed origin %q, got %q", tt.wantOrigin, got)
+			}
+			if got := rec.Header().Get("Vary"); got == "" {
+				t.Error("expected a Vary header")
+			}
+			if tt.wantOrigin == "" {
+				return
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+				t.Errorf("expected credentials to be allowed, got %q", got)
+			}
+			if tt.requestMethod != "" {
+				if got := rec.Header().Get("Access-Control-Max-Age"); got != "600" {
+					t.Errorf("expected a max age of 600, got %q", got)
+				}
+			} else if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "ETag" {
+				t.Errorf("expected ETag to be exposed, got %q", got)
+			}
+		})
+	}
+}
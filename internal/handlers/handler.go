@@ -1,48 +1,99 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
-	"log"
-	"log/slog"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/imaging"
+	"github.com/fromenjn/recipe-manager/internal/jsonld"
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/render"
+	"github.com/fromenjn/recipe-manager/internal/repository"
 	"github.com/fromenjn/recipe-manager/internal/usecase"
 )
 
 type RecipeHandler struct {
-	getRecipeUC         usecase.GetRecipeUseCase
-	getAllRecipesUC     usecase.GetAllRecipesUseCase
-	getAllIngredientsUC usecase.GetAllIngredientsUseCase
+	getRecipeUC            usecase.GetRecipeUseCase
+	listRecipeSummariesUC  usecase.ListRecipeSummariesUseCase
+	getAllIngredientsUC    usecase.GetAllIngredientsUseCase
+	getRecipeVersionUC     usecase.GetRecipeVersionUseCase
+	getCollectionVersionUC usecase.GetCollectionVersionUseCase
+	saveRecipeUC           usecase.SaveRecipeUseCase
+	authorizeRecipeUC      usecase.AuthorizeRecipeUseCase
+	resolveRecipeUC        usecase.ResolveRecipeUseCase
+	getImageUC             usecase.GetImageUseCase
 }
 
-func NewRecipeHandler(getRecipeUC usecase.GetRecipeUseCase, getAllRecipesUC usecase.GetAllRecipesUseCase, getAllIngredientsUC usecase.GetAllIngredientsUseCase) *RecipeHandler {
+func NewRecipeHandler(
+	getRecipeUC usecase.GetRecipeUseCase,
+	listRecipeSummariesUC usecase.ListRecipeSummariesUseCase,
+	getAllIngredientsUC usecase.GetAllIngredientsUseCase,
+	getRecipeVersionUC usecase.GetRecipeVersionUseCase,
+	getCollectionVersionUC usecase.GetCollectionVersionUseCase,
+	saveRecipeUC usecase.SaveRecipeUseCase,
+	authorizeRecipeUC usecase.AuthorizeRecipeUseCase,
+	resolveRecipeUC usecase.ResolveRecipeUseCase,
+	getImageUC usecase.GetImageUseCase,
+) *RecipeHandler {
 	return &RecipeHandler{
-		getRecipeUC:         getRecipeUC,
-		getAllRecipesUC:     getAllRecipesUC,
-		getAllIngredientsUC: getAllIngredientsUC,
+		getRecipeUC:            getRecipeUC,
+		listRecipeSummariesUC:  listRecipeSummariesUC,
+		getAllIngredientsUC:    getAllIngredientsUC,
+		getRecipeVersionUC:     getRecipeVersionUC,
+		getCollectionVersionUC: getCollectionVersionUC,
+		saveRecipeUC:           saveRecipeUC,
+		authorizeRecipeUC:      authorizeRecipeUC,
+		resolveRecipeUC:        resolveRecipeUC,
+		getImageUC:             getImageUC,
 	}
 }
 
 // GetRecipe godoc
 // @Summary      Retrieve a single recipe
-// @Description  Get a recipe by its ID. Optionally, scale ingredient quantities by specifying `ingredient` and `quantity`.
+// @Description  Get a recipe by its ID or its slug. A previous slug of a renamed recipe redirects to the current one.
+// @Description  Optionally, scale ingredient quantities by specifying `ingredient` and `quantity`.
+// @Description  The recipe is sent as JSON, rendered as Markdown or plain text for sharing, as a printable PDF
+// @Description  recipe card, or as a schema.org Recipe in JSON-LD with absolute links to the recipe and its
+// @Description  illustrations, as asked by the Accept header or the `format` query parameter, which takes precedence.
+// @Description  `/recipe/{recipeID}.pdf` always answers the PDF recipe card.
+// @Description  Responses carry ETag and Last-Modified headers; conditional requests answer 304 when unchanged.
 // @Tags         recipes
-// @Param        recipeID    path      string  true  "Recipe ID (e.g. '123')"
-// @Param        ingredient  query     string  false "Ingredient to scale (e.g. 'Flour')"
-// @Param        quantity    query     number  false "Quantity to scale the ingredient to (e.g. '300')"
+// @Param        recipeID           path      string  true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        ingredient         query     string  false "Ingredient to scale (e.g. 'Flour')"
+// @Param        quantity           query     number  false "Quantity to scale the ingredient to (e.g. '300')"
+// @Param        format             query     string  false "Representation, overriding Accept" Enums(json, markdown, text, pdf, jsonld)
+// @Param        If-None-Match      header    string  false "Entity tag of the cached representation"
+// @Param        If-Modified-Since  header    string  false "Date of the cached representation"
 // @Produce      json
+// @Produce      text/markdown
+// @Produce      plain
+// @Produce      application/pdf
+// @Produce      application/ld+json
 // @Success      200  {object}  domain.Recipe
+// @Success      301  {string}  string "moved to the current slug of the recipe"
+// @Success      304  {string}  string "not modified"
 // @Failure      400  {string}  string "invalid 'quantity' query parameter"
 // @Failure      404  {string}  string "recipe not found"
+// @Failure      406  {string}  string "none of the accepted media types is available"
 // @Failure      500  {string}  string "internal server error"
 // @Router       /recipe/{recipeID} [get]
+// @Router       /recipe/{recipeID}.pdf [get]
 func (rh *RecipeHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
 	// e.g. /recipes/123?ingredient=Flour&quantity=300
 	path := r.URL.Path
 	// This is not robust - you'd use a proper router in practice
-	recipeID := path[len("/recipe/"):]
+	key := path[len("/recipe/"):]
+	key, card := strings.CutSuffix(key, ".pdf")
 
 	// Query params
 	ingredient := r.URL.Query().Get("ingredient")
@@ -57,70 +108,454 @@ func (rh *RecipeHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
 		}
 		quantity = parsedQ
 	}
+	mediaType := mediaPDF
+	if !card {
+		var err error
+		mediaType, err = negotiate(r, mediaJSON, mediaMarkdown, mediaText, mediaPDF, mediaJSONLD)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if mediaType == "" {
+			http.Error(w, "none of the accepted media types is available, use application/json, text/markdown, text/plain, application/pdf or application/ld+json", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Add("Vary", "Accept")
+	}
 
-	recipe, err := rh.getRecipeUC.Execute(recipeID, ingredient, quantity)
+	ref, err := rh.resolveRecipeUC.Execute(r.Context(), key)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
+	recipeID := ref.ID
 
-	// Convert to JSON and return
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(recipe); err != nil {
-		log.Printf("failed to write response: %v", err)
+	// Recipes hidden from the client answer 404, like missing ones.
+	if err := rh.authorizeRecipeUC.Execute(r.Context(), requestViewer(r), recipeID, false); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if ref.Redirect != "" {
+		target := &url.URL{Path: "/recipe/" + ref.Redirect, RawQuery: r.URL.RawQuery}
+		if card {
+			target.Path += ".pdf"
+		}
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	if version, err := rh.getRecipeVersionUC.Execute(r.Context(), recipeID); err == nil {
+		variant := ""
+		switch mediaType {
+		case mediaJSON:
+		case mediaJSONLD:
+			// Links in JSON-LD are absolute, so they depend on the host.
+			variant = mediaType + " " + baseURL(r)
+		default:
+			variant = mediaType
+		}
+		etag := variantETag(version.ETag, ingredient, quantityStr, variant)
+		if checkNotModified(w, r, etag, version.LastModified) {
+			return
+		}
+	}
+
+	recipe, err := rh.getRecipeUC.Execute(r.Context(), recipeID, ingredient, quantity)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	switch mediaType {
+	case mediaMarkdown:
+		writeRendered(w, r, render.FormatMarkdown, recipe, ingredient, quantity)
+	case mediaText:
+		writeRendered(w, r, render.FormatText, recipe, ingredient, quantity)
+	case mediaPDF:
+		rh.writeCard(w, r, recipe, ingredient, quantity)
+	case mediaJSONLD:
+		writeJSONLD(w, r, recipe)
+	default:
+		writeJSON(w, r, http.StatusOK, recipe)
+	}
+}
+
+// writeJSONLD answers the schema.org Recipe of a recipe, linking to it and
+// its illustrations on the host of the request.
+func writeJSONLD(w http.ResponseWriter, r *http.Request, recipe *domain.Recipe) {
+	base := baseURL(r)
+	doc := jsonld.FromRecipe(*recipe, jsonld.Links{
+		Recipe: func(recipe domain.Recipe) string { return base + "/recipe/" + url.PathEscape(recipe.ID) },
+		Image:  func(path string) string { return base + path },
+	})
+	body, err := json.Marshal(doc)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", mediaJSONLD)
+	w.Write(body)
+}
+
+// baseURL returns the scheme and host the client reached the server at,
+// e.g. https://recipes.example.com.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// writeRendered renders a recipe scaled so that ingredient reaches quantity,
+// unless ingredient is empty, as the body of the response.
+func writeRendered(w http.ResponseWriter, r *http.Request, format render.Format, recipe *domain.Recipe, ingredient string, quantity float64) {
+	var scaling *render.Scaling
+	if ingredient != "" {
+		scaling = render.ScaledTo(*recipe, ingredient, quantity)
+	}
+
+	var body bytes.Buffer
+	if err := render.Recipe(&body, format, *recipe, scaling); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Write(body.Bytes())
+}
+
+// writeCard answers the PDF recipe card of a recipe scaled so that
+// ingredient reaches quantity, unless ingredient is empty.
+func (rh *RecipeHandler) writeCard(w http.ResponseWriter, r *http.Request, recipe *domain.Recipe, ingredient string, quantity float64) {
+	var scaling *render.Scaling
+	if ingredient != "" {
+		scaling = render.ScaledTo(*recipe, ingredient, quantity)
+	}
+
+	var body bytes.Buffer
+	if err := render.RecipeCard(&body, *recipe, scaling, illustrations(r.Context(), rh.getImageUC)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writePDF(w, recipe.ID, body.Bytes())
+}
+
+// illustrations loads the illustrations embedded in PDF documents.
+func illustrations(ctx context.Context, getImageUC usecase.GetImageUseCase) render.Images {
+	return func(path string) ([]byte, error) {
+		return usecase.ReadIllustration(ctx, getImageUC, path)
+	}
+}
+
+// writePDF answers a PDF document, named after name when saved.
+func writePDF(w http.ResponseWriter, name string, body []byte) {
+	w.Header().Set("Content-Type", render.PDFContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": name + ".pdf"}))
+	w.Write(body)
+}
+
+// UpdateRecipe godoc
+// @Summary      Create or replace a recipe
+// @Description  Stores the recipe and records a new revision in its history. Send `If-Match` with the ETag
+// @Description  of the recipe you edited to make sure nobody changed it in the meantime. A schema.org Recipe in
+// @Description  JSON-LD, sent as `application/ld+json`, is imported under the ID of the URL: its ingredients are
+// @Description  split into quantity, unit and name, and links to illustrations on this server are kept.
+// @Tags         recipes
+// @Param        recipeID  path      string         true   "Recipe ID, slug or alias; an unused ID creates the recipe"
+// @Param        If-Match  header    string         false  "ETag the update is based on"
+// @Param        X-Author  header    string         false  "Name recorded as the author of the revision, unless the client is authenticated"
+// @Param        recipe    body      domain.Recipe  true   "Recipe content"
+// @Accept       json
+// @Accept       application/ld+json
+// @Produce      json
+// @Success      200  {object}  domain.Recipe
+// @Success      201  {object}  domain.Recipe
+// @Failure      400  {string}  string "invalid recipe"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope or not allowed to change the recipe"
+// @Failure      404  {string}  string "recipe not found"
+// @Failure      412  {string}  string "precondition failed: recipe has been modified"
+// @Failure      413  {string}  string "request body too large"
+// @Failure      422  {object}  map[string][]domain.FieldError "validation errors"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipe/{recipeID} [put]
+func (rh *RecipeHandler) UpdateRecipe(w http.ResponseWriter, r *http.Request) {
+	recipeID := r.PathValue("recipeID")
+
+	var recipe domain.Recipe
+	if contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); contentType == mediaJSONLD {
+		imported, ok := rh.importJSONLD(w, r)
+		if !ok {
+			return
+		}
+		recipe = imported
+		recipe.ID = recipeID
+	} else if err := json.NewDecoder(r.Body).Decode(&recipe); err != nil {
+		writeDecodeError(w, "invalid recipe", err)
+		return
+	}
+	if recipe.ID == "" {
+		recipe.ID = recipeID
+	} else if recipe.ID != recipeID {
+		http.Error(w, "invalid recipe: ID does not match the URL", http.StatusBadRequest)
+		return
+	}
+
+	current, err := rh.getRecipeVersionUC.Execute(r.Context(), recipeID)
+	exists := err == nil
+	if checkIfMatch(w, r, current, exists) {
+		return
 	}
+
+	revision, err := rh.saveRecipeUC.Execute(r.Context(), requestViewer(r), recipe, requestAuthor(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Debug("saved recipe", "recipe_id", recipeID, "revision", revision.Number)
+
+	if version, err := rh.getRecipeVersionUC.Execute(r.Context(), recipeID); err == nil {
+		writeValidators(w, version.ETag, version.LastModified)
+	}
+	status := http.StatusOK
+	if !exists {
+		status = http.StatusCreated
+	}
+	writeJSON(w, r, status, revision.Recipe)
+}
+
+// CreateRecipe godoc
+// @Summary      Create a recipe with a new ID
+// @Description  Stores a new recipe under a generated ULID, which sorts by creation time, and records its first
+// @Description  revision. The recipe gets a unique slug derived from its name unless it has one. The recipe must
+// @Description  not have an ID; use PUT /recipe/{recipeID} to choose it. A schema.org Recipe in JSON-LD, sent as
+// @Description  `application/ld+json`, is imported.
+// @Tags         recipes
+// @Param        X-Author  header    string         false  "Name recorded as the author of the revision, unless the client is authenticated"
+// @Param        recipe    body      domain.Recipe  true   "Recipe content"
+// @Accept       json
+// @Accept       application/ld+json
+// @Produce      json
+// @Success      201  {object}  domain.Recipe
+// @Header       201  {string}  Location  "URL of the new recipe"
+// @Failure      400  {string}  string "invalid recipe"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      413  {string}  string "request body too large"
+// @Failure      422  {object}  map[string][]domain.FieldError "validation errors"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes [post]
+func (rh *RecipeHandler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
+	var recipe domain.Recipe
+	if contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); contentType == mediaJSONLD {
+		imported, ok := rh.importJSONLD(w, r)
+		if !ok {
+			return
+		}
+		recipe = imported
+		recipe.ID = ""
+	} else if err := json.NewDecoder(r.Body).Decode(&recipe); err != nil {
+		writeDecodeError(w, "invalid recipe", err)
+		return
+	}
+	if recipe.ID != "" {
+		http.Error(w, "invalid recipe: the ID is assigned by the server, use PUT /recipe/{recipeID} to choose it", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := rh.saveRecipeUC.Execute(r.Context(), requestViewer(r), recipe, requestAuthor(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	recipeID := revision.Recipe.ID
+	logging.FromContext(r.Context()).Debug("created recipe", "recipe_id", recipeID, "slug", revision.Recipe.Slug)
+
+	if version, err := rh.getRecipeVersionUC.Execute(r.Context(), recipeID); err == nil {
+		writeValidators(w, version.ETag, version.LastModified)
+	}
+	w.Header().Set("Location", "/recipe/"+url.PathEscape(recipeID))
+	writeJSON(w, r, http.StatusCreated, revision.Recipe)
+}
+
+// importJSONLD reads the schema.org Recipe of a request body. Illustrations
+// linked on this server get back their paths, and the variants stored for
+// them, e.g. thumbnails. It returns false when it answered an error.
+func (rh *RecipeHandler) importJSONLD(w http.ResponseWriter, r *http.Request) (domain.Recipe, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeDecodeError(w, "invalid recipe", err)
+		return domain.Recipe{}, false
+	}
+	base := baseURL(r)
+	recipes, err := jsonld.Parse(body, func(link string) string {
+		if path, ok := strings.CutPrefix(link, base); ok && strings.HasPrefix(path, usecase.ImageURLPrefix) {
+			return path
+		}
+		return link
+	})
+	if err != nil {
+		http.Error(w, "invalid recipe: "+err.Error(), http.StatusBadRequest)
+		return domain.Recipe{}, false
+	}
+	if len(recipes) != 1 {
+		http.Error(w, "invalid recipe: expected a single schema.org Recipe", http.StatusBadRequest)
+		return domain.Recipe{}, false
+	}
+	recipe := recipes[0]
+
+	if stored, err := rh.getRecipeUC.Execute(r.Context(), r.PathValue("recipeID"), "", 0); err == nil {
+		variants := make(map[string]map[string]string)
+		for _, step := range stored.Steps {
+			for _, illustration := range step.RecipeIllustration {
+				variants[illustration.Filepath] = illustration.Variants
+			}
+		}
+		for _, step := range recipe.Steps {
+			for i := range step.RecipeIllustration {
+				step.RecipeIllustration[i].Variants = variants[step.RecipeIllustration[i].Filepath]
+			}
+		}
+	}
+	return recipe, true
 }
 
 // ListRecipes godoc
 // @Summary      List all recipes
-// @Description  Returns all recipes in the system
+// @Description  Returns the recipes the client may see: public recipes and recipes nobody owns, and for logged in
+// @Description  users their own recipes and those their household may see. Each recipe carries the average rating
+// @Description  and the number of times it was cooked, from its reviews.
 // @Tags         recipes
-// @Param        ingredient  query     string  false "Ingredient to scale (e.g. 'Flour')"
+// @Param        ingredient         query     string  false "Only list recipes using this ingredient (e.g. 'Flour')"
+// @Param        sort               query     string  false "Order of the recipes" Enums(name, rating, last_cooked)
+// @Param        If-None-Match      header    string  false "Entity tag of the cached representation"
+// @Param        If-Modified-Since  header    string  false "Date of the cached representation"
 // @Produce      json
-// @Success      200  {array}  domain.Recipe
-// @Failure      500  {string}  string "failed to write response"
+// @Success      200  {array}  domain.RecipeSummary
+// @Success      304  {string}  string "not modified"
+// @Failure      400  {string}  string "invalid sort order"
+// @Failure      500  {string}  string "internal server error"
 // @Router       /recipes [get]
 func (rh *RecipeHandler) ListRecipes(w http.ResponseWriter, r *http.Request) {
 	ingredient := r.URL.Query().Get("ingredient")
+	sortBy, err := usecase.ParseRecipeSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
 	if ingredient != "" {
-		slog.Debug(fmt.Sprintf("Listing all recipes with ingredient containing %s", ingredient))
+		logging.FromContext(r.Context()).Debug("listing recipes", "ingredient", ingredient)
 	} else {
-		slog.Debug("Listing all recipes")
+		logging.FromContext(r.Context()).Debug("listing recipes")
 	}
 
-	recipes, err := rh.getAllRecipesUC.Execute(ingredient)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	viewer := requestViewer(r)
+	if version, err := rh.getCollectionVersionUC.Execute(r.Context(), viewer); err == nil {
+		if checkNotModified(w, r, variantETag(version.ETag, ingredient, string(sortBy)), version.LastModified) {
+			return
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(recipes); err != nil {
-		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to write response", http.StatusInternalServerError)
+	recipes, err := rh.listRecipeSummariesUC.Execute(r.Context(), viewer, ingredient, sortBy)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
+	writeJSON(w, r, http.StatusOK, recipes)
 }
 
 // ListIngredients godoc
 // @Summary      List all ingredients
-// @Description  Returns all ingredients from all the recipes
+// @Description  Returns all ingredients from the recipes the client may see
 // @Tags         recipes
+// @Param        If-None-Match      header    string  false "Entity tag of the cached representation"
+// @Param        If-Modified-Since  header    string  false "Date of the cached representation"
 // @Produce      json
 // @Success      200  {array}  string
-// @Failure      500  {string}  string "failed to write response"
+// @Success      304  {string}  string "not modified"
+// @Failure      500  {string}  string "internal server error"
 // @Router       /ingredients [get]
 func (rh *RecipeHandler) ListIngredients(w http.ResponseWriter, r *http.Request) {
-	slog.Debug("Listing all ingredients")
+	logging.FromContext(r.Context()).Debug("listing ingredients")
 
-	recipes, err := rh.getAllIngredientsUC.Execute()
+	viewer := requestViewer(r)
+	if version, err := rh.getCollectionVersionUC.Execute(r.Context(), viewer); err == nil {
+		if checkNotModified(w, r, version.ETag, version.LastModified) {
+			return
+		}
+	}
+
+	ingredients, err := rh.getAllIngredientsUC.Execute(r.Context(), viewer)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, ingredients)
+}
+
+// requestAuthor returns the name to record as the author of a change: the
+// authenticated client, or else the X-Author header.
+func requestAuthor(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return principal.Subject
+	}
+	if author := r.Header.Get("X-Author"); author != "" {
+		return author
+	}
+	return "anonymous"
+}
+
+// writeError answers with the status matching err. Validation errors are sent
+// as JSON so that clients can point at the offending fields.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrs domain.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		writeJSON(w, r, http.StatusUnprocessableEntity, map[string]any{"errors": validationErrs})
 		return
 	}
+	http.Error(w, err.Error(), statusForError(err))
+}
+
+// statusForError maps domain, repository and context errors to HTTP status codes.
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrRevisionNotFound),
+		errors.Is(err, usecase.ErrStepNotFound), errors.Is(err, domain.ErrIngredientNotFound),
+		errors.Is(err, repository.ErrImageNotFound), errors.Is(err, repository.ErrInvalidName),
+		errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrHouseholdNotFound),
+		errors.Is(err, repository.ErrCollectionNotFound), errors.Is(err, repository.ErrReviewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidRatio), errors.Is(err, usecase.ErrInvalidAccount),
+		errors.Is(err, usecase.ErrInvalidSort), errors.Is(err, usecase.ErrInvalidImport):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrInvalidLogin):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrUsernameTaken):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		// The request timed out or the client went away.
+		return http.StatusServiceUnavailable
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, imaging.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON encodes v as the JSON body of the response.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	_, span := tracer.Start(r.Context(), "EncodeJSON")
+	defer span.End()
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(recipes); err != nil {
-		log.Printf("failed to encode response: %v", err)
-		http.Error(w, "failed to write response", http.StatusInternalServerError)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		span.RecordError(err)
+		logging.FromContext(r.Context()).Error("failed to write response", "error", err)
 	}
 }
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// HealthHandler answers liveness and readiness probes. The server marks
+// itself ready once the repository has loaded, and not ready again when it
+// starts shutting down so that load balancers stop routing to it.
+type HealthHandler struct {
+	ready atomic.Bool
+}
+
+func NewHealthHandler() *HealthHandler {
+	return &HealthHandler{}
+}
+
+// SetReady changes the answer of the readiness probe.
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// Healthz godoc
+// @Summary      Liveness probe
+// @Description  Answers 200 as long as the process is serving requests.
+// @Tags         health
+// @Produce      plain
+// @Success      200  {string}  string "ok"
+// @Router       /healthz [get]
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+// Readyz godoc
+// @Summary      Readiness probe
+// @Description  Answers 200 once the recipes have loaded successfully, and 503 while starting or shutting down.
+// @Tags         health
+// @Produce      plain
+// @Success      200  {string}  string "ready"
+// @Failure      503  {string}  string "not ready"
+// @Router       /readyz [get]
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ready\n"))
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	h := NewHealthHandler()
+
+	probe := func(handler http.HandlerFunc) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	if code := probe(h.Healthz); code != http.StatusOK {
+		t.Errorf("expected healthz to answer 200, got %d", code)
+	}
+	if code := probe(h.Readyz); code != http.StatusServiceUnavailable {
+		t.Errorf("expected readyz to answer 503 before loading, got %d", code)
+	}
+
+	h.SetReady(true)
+	if code := probe(h.Readyz); code != http.StatusOK {
+		t.Errorf("expected readyz to answer 200 once ready, got %d", code)
+	}
+
+	h.SetReady(false)
+	if code := probe(h.Readyz); code != http.StatusServiceUnavailable {
+		t.Errorf("expected readyz to answer 503 while shutting down, got %d", code)
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// imageCacheControl lets browsers and proxies keep images for a day; the
+// ETag and Last-Modified validators make revalidation after that cheap.
+const imageCacheControl = "public, max-age=86400"
+
+type ImageHandler struct {
+	uploadIllustrationUC usecase.UploadIllustrationUseCase
+	getImageUC           usecase.GetImageUseCase
+	findImageRecipesUC   usecase.FindImageRecipesUseCase
+	maxUploadSize        int64
+}
+
+func NewImageHandler(
+	uploadIllustrationUC usecase.UploadIllustrationUseCase,
+	getImageUC usecase.GetImageUseCase,
+	findImageRecipesUC usecase.FindImageRecipesUseCase,
+	maxUploadSize int64,
+) *ImageHandler {
+	return &ImageHandler{
+		uploadIllustrationUC: uploadIllustrationUC,
+		getImageUC:           getImageUC,
+		findImageRecipesUC:   findImageRecipesUC,
+		maxUploadSize:        maxUploadSize,
+	}
+}
+
+// multipartOverhead leaves room for the multipart envelope and the
+// description field around an uploaded picture.
+const multipartOverhead = 64 * 1024
+
+// maxRequestSize is the largest upload request accepted.
+func (h *ImageHandler) maxRequestSize() int64 {
+	return h.maxUploadSize + multipartOverhead
+}
+
+// UploadIllustration godoc
+// @Summary      Upload an illustration for a recipe step
+// @Description  Accepts a JPEG, PNG, GIF or WebP picture as multipart form data. The content type is detected from
+// @Description  the file itself. A thumbnail and WebP variants are generated and the illustration is added to the step.
+// @Tags         images
+// @Param        recipeID     path      string  true   "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        stepID       path      string  true   "Step ID (e.g. 'step1')"
+// @Param        file         formData  file    true   "Picture to upload"
+// @Param        description  formData  string  false  "Description of the picture"
+// @Param        X-Author     header    string  false  "Name recorded as the author of the revision, unless the client is authenticated"
+// @Accept       multipart/form-data
+// @Produce      json
+// @Success      201  {object}  domain.RecipeIllustration
+// @Failure      400  {string}  string "missing 'file' form field"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      404  {string}  string "recipe not found"
+// @Failure      413  {string}  string "upload too large"
+// @Failure      415  {string}  string "unsupported image format"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/steps/{stepID}/illustrations [post]
+func (h *ImageHandler) UploadIllustration(w http.ResponseWriter, r *http.Request) {
+	recipeID := r.PathValue("recipeID")
+	stepID := r.PathValue("stepID")
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxRequestSize())
+	if err := r.ParseMultipartForm(h.maxUploadSize); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "upload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid multipart form: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "missing 'file' form field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > h.maxUploadSize {
+		http.Error(w, "upload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "failed to read upload", http.StatusBadRequest)
+		return
+	}
+
+	illustration, err := h.uploadIllustrationUC.Execute(r.Context(), recipeID, stepID, r.FormValue("description"), data, requestAuthor(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Debug("stored illustration", "recipe_id", recipeID, "step_id", stepID, "illustration_id", illustration.ID)
+	writeJSON(w, r, http.StatusCreated, illustration)
+}
+
+// ServeImage godoc
+// @Summary      Retrieve a stored image
+// @Description  Serves illustrations and their variants with caching headers, to clients who may see a recipe
+// @Description  they illustrate.
+// @Tags         images
+// @Param        name  path  string  true  "Image path (e.g. 'chocolate_cake/step1.jpg')"
+// @Produce      image/jpeg,image/png,image/gif,image/webp
+// @Success      200  {file}    file
+// @Success      304  {string}  string "not modified"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      404  {string}  string "image not found"
+// @Router       /images/{name} [get]
+func (h *ImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, usecase.ImageURLPrefix)
+
+	image, err := h.getImageUC.Execute(r.Context(), name)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer image.Close()
+
+	w.Header().Set("Cache-Control", imageCacheControl)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, image.ModTime.UnixNano(), image.Size))
+	// ServeContent sniffs the content type and handles Range and conditional requests.
+	http.ServeContent(w, r, name, image.ModTime, image)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// mediaZip is the media type of library archives.
+const mediaZip = "application/zip"
+
+type LibraryHandler struct {
+	exportLibraryUC usecase.ExportLibraryUseCase
+	importLibraryUC usecase.ImportLibraryUseCase
+	maxImportSize   int64
+}
+
+func NewLibraryHandler(
+	exportLibraryUC usecase.ExportLibraryUseCase,
+	importLibraryUC usecase.ImportLibraryUseCase,
+	maxImportSize int64,
+) *LibraryHandler {
+	return &LibraryHandler{
+		exportLibraryUC: exportLibraryUC,
+		importLibraryUC: importLibraryUC,
+		maxImportSize:   maxImportSize,
+	}
+}
+
+// Export godoc
+// @Summary      Export the library as a zip archive
+// @Description  Packs the recipes the client may see in canonical JSON, their illustration files, a catalog of the
+// @Description  recipes with their owner and visibility, and a manifest with the format version and the SHA-256
+// @Description  checksum of every file. The archive can be imported back with POST /import.
+// @Tags         admin
+// @Produce      application/zip
+// @Success      200  {file}    file
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /export [get]
+func (h *LibraryHandler) Export(w http.ResponseWriter, r *http.Request) {
+	var body bytes.Buffer
+	stats, err := h.exportLibraryUC.Execute(r.Context(), requestViewer(r), &body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Debug("exported library",
+		"recipes", stats.Recipes, "images", stats.Images, "missing_images", stats.MissingImages)
+
+	name := "recipes-" + time.Now().UTC().Format("20060102") + ".zip"
+	w.Header().Set("Content-Type", mediaZip)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Write(body.Bytes())
+}
+
+// Import godoc
+// @Summary      Import a library archive
+// @Description  Adds the recipes and illustrations of an archive made by GET /export, after checking its format
+// @Description  version and checksums. Recipes whose ID is taken are skipped, overwritten as a new revision, or
+// @Description  renamed to a free ID such as `2-2`, as chosen by `conflict`. Invalid recipes fail without stopping
+// @Description  the import. Illustrations are written to the directory of their recipe; those pointing elsewhere
+// @Description  move there with a warning. With `dry_run`, nothing is changed and the report tells what would be.
+// @Tags         admin
+// @Param        conflict  query     string  false  "What to do with recipes whose ID is taken" Enums(skip, overwrite, rename) default(skip)
+// @Param        dry_run   query     bool    false  "Report what would change without changing anything"
+// @Param        X-Author  header    string  false  "Name recorded as the author of the revisions, unless the client is authenticated"
+// @Param        archive   body      string  true   "Zip archive made by GET /export"
+// @Accept       application/zip
+// @Produce      json
+// @Success      200  {object}  usecase.ImportReport
+// @Failure      400  {string}  string "invalid library archive or conflict policy"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      413  {string}  string "request body too large"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /import [post]
+func (h *LibraryHandler) Import(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := usecase.ImportOptions{Conflict: usecase.ConflictSkip, Author: requestAuthor(r)}
+	if conflict := query.Get("conflict"); conflict != "" {
+		policy, err := usecase.ParseConflictPolicy(conflict)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.Conflict = policy
+	}
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		parsed, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			http.Error(w, "invalid 'dry_run' query parameter", http.StatusBadRequest)
+			return
+		}
+		opts.DryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxImportSize)
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read archive", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.importLibraryUC.Execute(r.Context(), requestViewer(r), data, opts)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Debug("imported library", "dry_run", report.DryRun,
+		"created", report.Created, "overwritten", report.Overwritten, "renamed", report.Renamed,
+		"skipped", report.Skipped, "failed", report.Failed)
+	writeJSON(w, r, http.StatusOK, report)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/ratelimit"
+)
+
+// Route groups sharing a rate limit.
+const (
+	RateLimitRead  = "read"
+	RateLimitWrite = "write"
+	RateLimitAdmin = "admin"
+	// RateLimitAuth covers logging in and registering, to slow down password guessing.
+	RateLimitAuth = "auth"
+)
+
+// limitRate wraps a route so that each client IP address may only send
+// requests as fast as limiter allows, answering 429 Too Many Requests
+// otherwise. It comes before authentication, so that requests with wrong
+// credentials are counted too. A nil limiter leaves the route unlimited.
+func limitRate(limiter *ratelimit.Limiter) func(http.HandlerFunc) http.HandlerFunc {
+	return limitRateBy(limiter, clientIPKey)
+}
+
+// limitPrincipalRate wraps an authenticated route so that each API key, token
+// or user also has its own quota, wherever it connects from. Anonymous
+// requests are left to limitRate.
+func limitPrincipalRate(limiter *ratelimit.Limiter) func(http.HandlerFunc) http.HandlerFunc {
+	return limitRateBy(limiter, principalKey)
+}
+
+// limitRateBy limits the requests sharing the key of a client, answering 429
+// Too Many Requests once it is over its quota. Clients are told their quota
+// in RateLimit-* headers. Requests without a key are not limited.
+func limitRateBy(limiter *ratelimit.Limiter, key func(r *http.Request) string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if limiter == nil {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			client := key(r)
+			if client == "" {
+				next(w, r)
+				return
+			}
+			decision := limiter.Allow(client)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			w.Header().Set("RateLimit-Reset", seconds(decision.Reset))
+			if !decision.Allowed {
+				logging.FromContext(r.Context()).Debug("rate limited", "client", client)
+				w.Header().Set("Retry-After", seconds(decision.RetryAfter))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// clientIPKey identifies the client of a request by its IP address.
+func clientIPKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// principalKey identifies the client of an authenticated request by its API
+// key, token or user, and is empty for anonymous requests.
+func principalKey(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return "principal:" + principal.Subject
+	}
+	return ""
+}
+
+// seconds formats d as whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// WithMaxBodySize refuses request bodies larger than limit with 413 Request
+// Entity Too Large, before handlers start decoding them. The routes of mux
+// listed in routeLimits, by pattern, get their own limit, e.g. uploads.
+func WithMaxBodySize(limit int64, routeLimits map[string]int64, mux *http.ServeMux, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		maxSize := limit
+		if len(routeLimits) > 0 {
+			if _, pattern := mux.Handler(r); routeLimits[pattern] > 0 {
+				maxSize = routeLimits[pattern]
+			}
+		}
+		if r.ContentLength > maxSize {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeDecodeError answers 413 when a request body went over the size limit
+// while being decoded, and 400 for other decoding failures.
+func writeDecodeError(w http.ResponseWriter, message string, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, message+": "+err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/ratelimit"
+)
+
+func TestLimitRate(t *testing.T) {
+	handler := limitRate(ratelimit.New(ratelimit.Limit{RequestsPerMinute: 60, Burst: 2}))(func(w http.ResponseWriter, r *http.Request) {})
+	send := func(remoteAddr string, principal *auth.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+		req.RemoteAddr = remoteAddr
+		if principal != nil {
+			req = req.WithContext(auth.WithPrincipal(context.Background(), principal))
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := send("192.0.2.1:1234", nil); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "2" {
+			t.Fatalf("expected request %d to be allowed, got %d with %v", i, rec.Code, rec.Header())
+		}
+	}
+	rec := send("192.0.2.1:5678", nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 once the burst is spent, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected rate limit headers %v", rec.Header())
+	}
+
+	// Authenticated clients share the quota of their IP address before being
+	// authenticated.
+	if rec := send("192.0.2.1:1234", &auth.Principal{Subject: "ci"}); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected requests to be limited by IP address, got %d", rec.Code)
+	}
+	if rec := send("198.51.100.7:1234", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected another IP address to be limited separately, got %d", rec.Code)
+	}
+}
+
+func TestLimitPrincipalRate(t *testing.T) {
+	handler := limitPrincipalRate(ratelimit.New(ratelimit.Limit{RequestsPerMinute: 60, Burst: 1}))(func(w http.ResponseWriter, r *http.Request) {})
+	send := func(remoteAddr string, principal *auth.Principal) int {
+		req := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+		req.RemoteAddr = remoteAddr
+		if principal != nil {
+			req = req.WithContext(auth.WithPrincipal(context.Background(), principal))
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	if code := send("192.0.2.1:1234", &auth.Principal{Subject: "ci"}); code != http.StatusOK {
+		t.Fatalf("expected the first request to be allowed, got %d", code)
+	}
+	// The quota follows the API key, wherever it connects from.
+	if code := send("198.51.100.7:1234", &auth.Principal{Subject: "ci"}); code != http.StatusTooManyRequests {
+		t.Errorf("expected the API key to be limited, got %d", code)
+	}
+	if code := send("192.0.2.1:1234", &auth.Principal{Subject: "other"}); code != http.StatusOK {
+		t.Errorf("expected another API key to be limited separately, got %d", code)
+	}
+	for i := 0; i < 3; i++ {
+		if code := send("192.0.2.1:1234", nil); code != http.StatusOK {
+			t.Errorf("expected anonymous requests to be left to the IP limit, got %d", code)
+		}
+	}
+}
+
+func TestScopedRoute_BadCredentials(t *testing.T) {
+	limit := ratelimit.Limit{RequestsPerMinute: 60, Burst: 3}
+	handler := scopedRoute(fakeAuthenticator{}, anonymousNone, auth.ScopeRead, ratelimit.New(limit), ratelimit.New(limit))(
+		func(w http.ResponseWriter, r *http.Request) {})
+
+	var codes []int
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set(auth.APIKeyHeader, "guess")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests}
+	if !slices.Equal(codes, want) {
+		t.Errorf("expected wrong credentials to be rate limited, got %v", codes)
+	}
+}
+
+func TestWithMaxBodySize(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /recipe/{recipeID}", func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]any
+		decodeJSON(w, r, &v)
+	})
+	mux.HandleFunc("POST /upload", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		}
+	})
+	handler := WithMaxBodySize(16, map[string]int64{"POST /upload": 64}, mux, mux)
+
+	body := `{"name": "` + strings.Repeat("a", 32) + `"}`
+	tests := []struct {
+		name       string
+		path       string
+		body       io.Reader
+		wantStatus int
+	}{
+		{"small body", "/recipe/1", strings.NewReader(`{"a": 1}`), http.StatusOK},
+		{"declared too large", "/recipe/1", strings.NewReader(body), http.StatusRequestEntityTooLarge},
+		// Without a Content-Length, the body is cut while decoding.
+		{"streamed too large", "/recipe/1", io.MultiReader(strings.NewReader(body)), http.StatusRequestEntityTooLarge},
+		{"route limit", "/upload", strings.NewReader(body), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodPut
+			if tt.path == "/upload" {
+				method = http.MethodPost
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(method, tt.path, tt.body))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body)
+			}
+		})
+	}
+}
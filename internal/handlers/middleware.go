@@ -1,24 +1,161 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/logging"
 )
 
-// withCORS is a middleware that adds the necessary headers to handle CORS.
-func WithCORS(next http.Handler) http.Handler {
+// RequestIDHeader carries the identifier correlating the log lines of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits the request IDs accepted from clients, so that they
+// can be logged safely.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// WithRequestLogging reuses the X-Request-ID of the request or assigns a new
+// one, echoes it in the response, stores a logger tagged with it in the
+// request context and logs one access line per request.
+func WithRequestLogging(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		requestLogger := logger.With("request_id", requestID)
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			requestLogger = requestLogger.With("trace_id", spanContext.TraceID().String())
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		serveWithContext(next, recorder, r, logging.WithLogger(r.Context(), requestLogger))
+
+		requestLogger.Info("request",
+			"method", r.Method,
+			"route", r.Pattern,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"bytes", recorder.bytes,
+			"duration", time.Since(start),
+			"remote_addr", r.RemoteAddr,
+		)
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestObserver records the outcome of every request served. Route is the
+// pattern of the handler that served it, or "none" if no route matched.
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// WithTimeout gives every request a deadline after timeout, which use cases
+// and the repository honor through the request context. Zero disables it.
+func WithTimeout(timeout time.Duration, next http.Handler) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		serveWithContext(next, w, r, ctx)
+	})
+}
+
+// WithTracing records a server span per request, continuing the trace of the
+// caller when the request carries a W3C traceparent header.
+func WithTracing(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*") // or a specific domain
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		))
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		serveWithContext(next, recorder, r, ctx)
+
+		// Name the span after the route once ServeMux has matched it.
+		if r.Pattern != "" {
+			span.SetName(r.Pattern)
+			span.SetAttributes(attribute.String("http.route", r.Pattern))
 		}
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// serveWithContext calls next with a copy of r using ctx, then copies back the
+// route that ServeMux records on the request, so that the middlewares around
+// this one still see it.
+func serveWithContext(next http.Handler, w http.ResponseWriter, r *http.Request, ctx context.Context) {
+	inner := r.WithContext(ctx)
+	next.ServeHTTP(w, inner)
+	r.Pattern = inner.Pattern
+}
+
+// WithMetrics reports each request to observer once it has been served.
+func WithMetrics(observer RequestObserver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
 
-		// Pass control to the next handler
-		next.ServeHTTP(w, r)
+		// ServeMux records the matched pattern on the request it dispatches.
+		route := r.Pattern
+		if route == "" {
+			route = "none"
+		}
+		observer.ObserveRequest(r.Method, route, recorder.status, time.Since(start))
 	})
 }
+
+// statusRecorder remembers the status code and body size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
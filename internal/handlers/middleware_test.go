@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fromenjn/recipe-manager/internal/logging"
+)
+
+type recordedRequest struct {
+	method, route string
+	status        int
+}
+
+type fakeObserver struct {
+	requests []recordedRequest
+}
+
+func (o *fakeObserver) ObserveRequest(method, route string, status int, duration time.Duration) {
+	o.requests = append(o.requests, recordedRequest{method, route, status})
+}
+
+func TestWithMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /recipes/{recipeID}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "recipe not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /recipes", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[]"))
+	})
+	observer := &fakeObserver{}
+	handler := WithMetrics(observer, WithCORS(CORSPolicy{}, mux))
+
+	for _, path := range []string{"/recipes", "/recipes/42", "/unknown"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	want := []recordedRequest{
+		{http.MethodGet, "GET /recipes", http.StatusOK},
+		{http.MethodGet, "GET /recipes/{recipeID}", http.StatusNotFound},
+		{http.MethodGet, "none", http.StatusNotFound},
+	}
+	if len(observer.requests) != len(want) {
+		t.Fatalf("expected %d observed requests, got %v", len(want), observer.requests)
+	}
+	for i, got := range observer.requests {
+		if got != want[i] {
+			t.Errorf("request %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+}
+
+func TestWithRequestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /recipes/{recipeID}", func(w http.ResponseWriter, r *http.Request) {
+		logging.FromContext(r.Context()).Debug("fetching recipe")
+		w.Write([]byte("hello"))
+	})
+	handler := WithRequestLogging(logger, mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/recipes/42", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Errorf("expected the client request ID to be echoed, got %q", got)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a handler line and an access line, got %q", buf.String())
+	}
+	var access map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &access); err != nil {
+		t.Fatalf("invalid access log line: %v", err)
+	}
+	for key, want := range map[string]any{
+		"request_id": "abc-123",
+		"method":     "GET",
+		"route":      "GET /recipes/{recipeID}",
+		"status":     float64(200),
+		"bytes":      float64(5),
+	} {
+		if access[key] != want {
+			t.Errorf("expected %s=%v in the access log, got %v", key, want, access[key])
+		}
+	}
+	if !strings.Contains(lines[0], `"request_id":"abc-123"`) {
+		t.Errorf("expected the handler log line to carry the request ID, got %s", lines[0])
+	}
+
+	// Unsafe request IDs are replaced by a generated one.
+	req = httptest.NewRequest(http.MethodGet, "/recipes/42", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(RequestIDHeader); got == "" || got == "bad id\n" {
+		t.Errorf("expected a generated request ID, got %q", got)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	handler := WithTimeout(time.Minute, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/recipes", nil))
+	if !hasDeadline || time.Until(deadline) > time.Minute {
+		t.Errorf("expected a deadline within a minute, got %v", deadline)
+	}
+
+	handler = WithTimeout(0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline = r.Context().Deadline()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/recipes", nil))
+	if hasDeadline {
+		t.Error("expected no deadline when the timeout is disabled")
+	}
+}
+
+func TestStatusForError_Context(t *testing.T) {
+	for _, err := range []error{context.DeadlineExceeded, context.Canceled} {
+		if got := statusForError(fmt.Errorf("loading recipe: %w", err)); got != http.StatusServiceUnavailable {
+			t.Errorf("expected 503 for %v, got %d", err, got)
+		}
+	}
+}
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /recipes/{recipeID}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, http.StatusOK, map[string]string{"id": r.PathValue("recipeID")})
+	})
+	observer := &fakeObserver{}
+	handler := WithMetrics(observer, WithTracing(mux))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/recipes/42", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected an encoding span and a request span, got %d", len(spans))
+	}
+	if spans[0].Name() != "EncodeJSON" || spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("expected EncodeJSON to be a child of the request span, got %s", spans[0].Name())
+	}
+	if spans[1].Name() != "GET /recipes/{recipeID}" {
+		t.Errorf("expected the request span to be named after the route, got %s", spans[1].Name())
+	}
+	// The middleware replaces the request context; outer middlewares must still see the route.
+	if len(observer.requests) != 1 || observer.requests[0].route != "GET /recipes/{recipeID}" {
+		t.Errorf("expected the route to be observed, got %v", observer.requests)
+	}
+}
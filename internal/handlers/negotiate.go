@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Media types of the representations of recipes.
+const (
+	mediaJSON     = "application/json"
+	mediaMarkdown = "text/markdown"
+	mediaText     = "text/plain"
+	mediaPDF      = "application/pdf"
+	mediaJSONLD   = "application/ld+json"
+)
+
+// errUnsupportedFormat is wrapped by errors about unknown format= values.
+var errUnsupportedFormat = errors.New("unsupported format")
+
+// formats maps the values of the format query parameter to media types.
+var formats = map[string]string{
+	"json":     mediaJSON,
+	"markdown": mediaMarkdown,
+	"md":       mediaMarkdown,
+	"text":     mediaText,
+	"txt":      mediaText,
+	"pdf":      mediaPDF,
+	"jsonld":   mediaJSONLD,
+}
+
+// negotiate picks the media type of a response among offers, in order of
+// preference. The format query parameter names one directly, e.g.
+// format=markdown, for links and clients that cannot set headers; otherwise
+// the Accept header decides. It returns "" when the client accepts none of
+// the offers.
+func negotiate(r *http.Request, offers ...string) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		mediaType, ok := formats[strings.ToLower(format)]
+		if !ok || !slices.Contains(offers, mediaType) {
+			return "", fmt.Errorf("%w %q", errUnsupportedFormat, format)
+		}
+		return mediaType, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], nil
+	}
+	best, bestWeight := "", 0.0
+	for _, offer := range offers {
+		if weight := acceptWeight(accept, offer); weight > bestWeight {
+			best, bestWeight = offer, weight
+		}
+	}
+	return best, nil
+}
+
+// acceptWeight returns the quality the Accept header gives to mediaType,
+// taken from its most specific matching range, or 0 if none matches.
+func acceptWeight(accept, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	weight, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		acceptedType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		var s int
+		switch acceptedType {
+		case mediaType:
+			s = 2
+		case mainType + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		weight, specificity = q, s
+	}
+	return weight
+}
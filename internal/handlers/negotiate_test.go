@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{mediaJSON, mediaMarkdown, mediaText, mediaPDF, mediaJSONLD}
+	tests := []struct {
+		name    string
+		url     string
+		accept  string
+		want    string
+		wantErr error
+	}{
+		{"no preference", "/recipe/1", "", mediaJSON, nil},
+		{"markdown", "/recipe/1", "text/markdown", mediaMarkdown, nil},
+		{"browser", "/recipe/1", "text/html,application/xhtml+xml,*/*;q=0.8", mediaJSON, nil},
+		{"text over any", "/recipe/1", "text/*;q=0.9, application/json;q=0.5", mediaMarkdown, nil},
+		{"quality", "/recipe/1", "text/markdown;q=0.2, text/plain", mediaText, nil},
+		{"specific range wins", "/recipe/1", "text/*, text/markdown;q=0", mediaText, nil},
+		{"not acceptable", "/recipe/1", "image/png", "", nil},
+		{"format overrides Accept", "/recipe/1?format=md", "application/json", mediaMarkdown, nil},
+		{"pdf", "/recipe/1?format=pdf", "", mediaPDF, nil},
+		{"json-ld", "/recipe/1", "application/ld+json, application/json;q=0.9", mediaJSONLD, nil},
+		{"json-ld format", "/recipe/1?format=jsonld", "", mediaJSONLD, nil},
+		{"unknown format", "/recipe/1?format=docx", "", "", errUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			got, err := negotiate(req, offers...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
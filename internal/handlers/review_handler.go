@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// ReviewRequest is the body of the add and update review requests.
+type ReviewRequest struct {
+	// Rating from 1 to 5, or 0 for a review that is not rated.
+	Rating int    `json:"rating"`
+	Notes  string `json:"notes"`
+	// CookedOn is the date the recipe was cooked, as YYYY-MM-DD.
+	CookedOn    string   `json:"cooked_on"`
+	Servings    int      `json:"servings"`
+	Adjustments []string `json:"adjustments"`
+}
+
+func (req ReviewRequest) review() domain.Review {
+	return domain.Review{
+		Rating:      req.Rating,
+		Notes:       req.Notes,
+		CookedOn:    req.CookedOn,
+		Servings:    req.Servings,
+		Adjustments: req.Adjustments,
+	}
+}
+
+type ReviewHandler struct {
+	listReviewsUC  usecase.ListReviewsUseCase
+	getReviewUC    usecase.GetReviewUseCase
+	addReviewUC    usecase.AddReviewUseCase
+	updateReviewUC usecase.UpdateReviewUseCase
+	deleteReviewUC usecase.DeleteReviewUseCase
+}
+
+func NewReviewHandler(
+	listReviewsUC usecase.ListReviewsUseCase,
+	getReviewUC usecase.GetReviewUseCase,
+	addReviewUC usecase.AddReviewUseCase,
+	updateReviewUC usecase.UpdateReviewUseCase,
+	deleteReviewUC usecase.DeleteReviewUseCase,
+) *ReviewHandler {
+	return &ReviewHandler{
+		listReviewsUC:  listReviewsUC,
+		getReviewUC:    getReviewUC,
+		addReviewUC:    addReviewUC,
+		updateReviewUC: updateReviewUC,
+		deleteReviewUC: deleteReviewUC,
+	}
+}
+
+// ListReviews godoc
+// @Summary      List the reviews of a recipe
+// @Description  Returns the ratings, comments and cook-log entries of a recipe, newest first.
+// @Tags         reviews
+// @Param        recipeID  path  string  true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Produce      json
+// @Success      200  {array}   domain.Review
+// @Failure      404  {string}  string "recipe not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/reviews [get]
+func (h *ReviewHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.listReviewsUC.Execute(r.Context(), r.PathValue("recipeID"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, reviews)
+}
+
+// GetReview godoc
+// @Summary      Retrieve a review of a recipe
+// @Tags         reviews
+// @Param        recipeID  path  string  true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        reviewID  path  string  true  "Review ID"
+// @Produce      json
+// @Success      200  {object}  domain.Review
+// @Failure      404  {string}  string "review not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/reviews/{reviewID} [get]
+func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	review, err := h.getReviewUC.Execute(r.Context(), r.PathValue("recipeID"), r.PathValue("reviewID"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, review)
+}
+
+// AddReview godoc
+// @Summary      Review a recipe or log cooking it
+// @Description  Records a rating, notes, the date the recipe was cooked, the servings made and the adjustments,
+// @Description  e.g. "cooked this on Tuesday, needed 5 more minutes". A review needs a rating, notes or a date.
+// @Tags         reviews
+// @Param        recipeID  path    string         true   "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        X-Author  header  string         false  "Name recorded as the author of the review, unless the client is authenticated"
+// @Param        review    body    ReviewRequest  true   "Review content"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  domain.Review
+// @Failure      400  {string}  string "invalid request body"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      404  {string}  string "recipe not found"
+// @Failure      422  {object}  map[string][]domain.FieldError "validation errors"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/reviews [post]
+func (h *ReviewHandler) AddReview(w http.ResponseWriter, r *http.Request) {
+	var request ReviewRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	review, err := h.addReviewUC.Execute(r.Context(), requestViewer(r), r.PathValue("recipeID"), request.review(), requestAuthor(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Debug("added review", "recipe_id", review.RecipeID, "review_id", review.ID)
+	writeJSON(w, r, http.StatusCreated, review)
+}
+
+// UpdateReview godoc
+// @Summary      Change a review of a recipe
+// @Description  Replaces the rating, notes, date, servings and adjustments of a review. Only its author and admins may.
+// @Tags         reviews
+// @Param        recipeID  path  string         true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        reviewID  path  string         true  "Review ID"
+// @Param        review    body  ReviewRequest  true  "Review content"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  domain.Review
+// @Failure      400  {string}  string "invalid request body"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "not the author of the review"
+// @Failure      404  {string}  string "review not found"
+// @Failure      422  {object}  map[string][]domain.FieldError "validation errors"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/reviews/{reviewID} [put]
+func (h *ReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	var request ReviewRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+
+	review, err := h.updateReviewUC.Execute(r.Context(), requestViewer(r), r.PathValue("recipeID"), r.PathValue("reviewID"), request.review())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, review)
+}
+
+// DeleteReview godoc
+// @Summary      Delete a review of a recipe
+// @Description  Only the author of the review and admins may.
+// @Tags         reviews
+// @Param        recipeID  path  string  true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        reviewID  path  string  true  "Review ID"
+// @Success      204
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "not the author of the review"
+// @Failure      404  {string}  string "review not found"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/reviews/{reviewID} [delete]
+func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	if err := h.deleteReviewUC.Execute(r.Context(), requestViewer(r), r.PathValue("recipeID"), r.PathValue("reviewID")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fromenjn/recipe-manager/internal/logging"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+type RevisionHandler struct {
+	listRevisionsUC usecase.ListRevisionsUseCase
+	getRevisionUC   usecase.GetRevisionUseCase
+	diffRevisionsUC usecase.DiffRevisionsUseCase
+	revertRecipeUC  usecase.RevertRecipeUseCase
+}
+
+func NewRevisionHandler(
+	listRevisionsUC usecase.ListRevisionsUseCase,
+	getRevisionUC usecase.GetRevisionUseCase,
+	diffRevisionsUC usecase.DiffRevisionsUseCase,
+	revertRecipeUC usecase.RevertRecipeUseCase,
+) *RevisionHandler {
+	return &RevisionHandler{
+		listRevisionsUC: listRevisionsUC,
+		getRevisionUC:   getRevisionUC,
+		diffRevisionsUC: diffRevisionsUC,
+		revertRecipeUC:  revertRecipeUC,
+	}
+}
+
+// ListRevisions godoc
+// @Summary      List the revisions of a recipe
+// @Description  Returns the append-only history of a recipe, oldest first, with a full snapshot per revision.
+// @Tags         revisions
+// @Param        recipeID  path  string  true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Produce      json
+// @Success      200  {array}   domain.Revision
+// @Failure      404  {string}  string "recipe not found"
+// @Router       /recipes/{recipeID}/revisions [get]
+func (h *RevisionHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	recipeID := r.PathValue("recipeID")
+	logging.FromContext(r.Context()).Debug("listing revisions", "recipe_id", recipeID)
+
+	revisions, err := h.listRevisionsUC.Execute(r.Context(), recipeID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, revisions)
+}
+
+// GetRevision godoc
+// @Summary      Retrieve a single revision of a recipe
+// @Tags         revisions
+// @Param        recipeID  path  string   true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        revision  path  integer  true  "Revision number, starting at 1"
+// @Produce      json
+// @Success      200  {object}  domain.Revision
+// @Failure      400  {string}  string "invalid revision number"
+// @Failure      404  {string}  string "revision not found"
+// @Router       /recipes/{recipeID}/revisions/{revision} [get]
+func (h *RevisionHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("revision"))
+	if err != nil {
+		http.Error(w, "invalid revision number", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.getRevisionUC.Execute(r.Context(), r.PathValue("recipeID"), number)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, revision)
+}
+
+// DiffRevisions godoc
+// @Summary      Compare two revisions of a recipe
+// @Description  Returns the ingredients added, removed or changed and the steps added, removed, changed or reordered
+// @Description  between two revisions.
+// @Tags         revisions
+// @Param        recipeID  path   string   true  "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        from      query  integer  true  "Older revision number"
+// @Param        to        query  integer  true  "Newer revision number"
+// @Produce      json
+// @Success      200  {object}  domain.RecipeDiff
+// @Failure      400  {string}  string "invalid 'from' or 'to' query parameter"
+// @Failure      404  {string}  string "revision not found"
+// @Router       /recipes/{recipeID}/revisions/diff [get]
+func (h *RevisionHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil {
+		http.Error(w, "invalid 'from' or 'to' query parameter", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := h.diffRevisionsUC.Execute(r.Context(), r.PathValue("recipeID"), from, to)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, diff)
+}
+
+// RevertRecipe godoc
+// @Summary      Revert a recipe to an earlier revision
+// @Description  Restores the content of the given revision. The revert is recorded as a new revision.
+// @Tags         revisions
+// @Param        recipeID  path    string   true   "Recipe ID, slug or alias (e.g. 'creme-brulee')"
+// @Param        revision  path    integer  true   "Revision number to restore"
+// @Param        X-Author  header  string   false  "Name recorded as the author of the revision, unless the client is authenticated"
+// @Produce      json
+// @Success      200  {object}  domain.Revision
+// @Failure      400  {string}  string "invalid revision number"
+// @Failure      401  {string}  string "authentication required"
+// @Failure      403  {string}  string "missing scope"
+// @Failure      404  {string}  string "revision not found"
+// @Failure      422  {object}  map[string][]domain.FieldError "validation errors"
+// @Failure      500  {string}  string "internal server error"
+// @Router       /recipes/{recipeID}/revisions/{revision}/revert [post]
+func (h *RevisionHandler) RevertRecipe(w http.ResponseWriter, r *http.Request) {
+	recipeID := r.PathValue("recipeID")
+	number, err := strconv.Atoi(r.PathValue("revision"))
+	if err != nil {
+		http.Error(w, "invalid revision number", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.revertRecipeUC.Execute(r.Context(), recipeID, number, requestAuthor(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	logging.FromContext(r.Context()).Debug("reverted recipe", "recipe_id", recipeID, "to_revision", number, "revision", revision.Number)
+	writeJSON(w, r, http.StatusOK, revision)
+}
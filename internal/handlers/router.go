@@ -1,20 +1,162 @@
 package handlers
 
 import (
+	"io/fs"
+	"log/slog"
 	"net/http"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/frontend"
+	"github.com/fromenjn/recipe-manager/internal/ratelimit"
 )
 
-func NewRouter(recipeHandler *RecipeHandler) http.Handler {
+// Metrics observes requests and serves the collected metrics.
+type Metrics interface {
+	RequestObserver
+	http.Handler
+}
+
+// RouterConfig holds the settings of the middlewares wrapping the routes.
+type RouterConfig struct {
+	// Frontend holds the built frontend served under /. A nil Frontend
+	// disables serving the frontend.
+	Frontend fs.FS
+	// RequestTimeout cancels requests that take longer, unless it is zero.
+	RequestTimeout time.Duration
+	// Logger writes the access log and is the base of request-scoped loggers.
+	Logger *slog.Logger
+	// Metrics observes every request and serves the collected metrics under /metrics.
+	Metrics Metrics
+	// Auth authenticates the clients of the API routes, which then need the
+	// scope of the route. A nil Auth leaves every route open.
+	Auth Authenticator
+	// RequireAuth refuses clients without credentials, except on the
+	// read-only routes when AnonymousRead is set. Otherwise they may use
+	// every route but the user ones.
+	RequireAuth bool
+	// AnonymousRead lets clients without credentials use the read-only routes.
+	AnonymousRead bool
+	// RegistrationOpen lets anyone create a user; otherwise only admins may.
+	RegistrationOpen bool
+	// CORS is what cross-origin browsers may do with the API.
+	CORS CORSPolicy
+	// RateLimits limits how fast each client may use the routes of a group
+	// (RateLimitRead, RateLimitWrite, RateLimitAdmin or RateLimitAuth).
+	// Groups without a limit are unlimited.
+	RateLimits map[string]ratelimit.Limit
+	// MaxBodySize is the largest request body accepted, except for uploads
+	// and imports which are limited by their handlers. Zero disables the limit.
+	MaxBodySize int64
+}
 
+// frontendPattern is the catch-all route of the frontend.
+const frontendPattern = "/"
+
+// NewRouter registers the API routes and wraps them with the middlewares.
+func NewRouter(
+	cfg RouterConfig,
+	healthHandler *HealthHandler,
+	recipeHandler *RecipeHandler,
+	revisionHandler *RevisionHandler,
+	imageHandler *ImageHandler,
+	adminHandler *AdminHandler,
+	accountHandler *AccountHandler,
+	collectionHandler *CollectionHandler,
+	reviewHandler *ReviewHandler,
+	cookbookHandler *CookbookHandler,
+	libraryHandler *LibraryHandler,
+) http.Handler {
 	mux := http.NewServeMux()
 
-	fileServer := http.FileServer(http.Dir("./dist"))
-	mux.Handle("/", fileServer)
+	if cfg.Frontend != nil {
+		mux.Handle(frontendPattern, frontend.NewHandler(cfg.Frontend))
+	}
+
+	mux.HandleFunc("GET /healthz", healthHandler.Healthz)
+	mux.HandleFunc("GET /readyz", healthHandler.Readyz)
+	mux.Handle("GET /metrics", cfg.Metrics)
+
+	// The frontend, probes and metrics stay open; the API routes need a scope.
+	anonymous := anonymousAll
+	if cfg.RequireAuth {
+		anonymous = anonymousNone
+		if cfg.AnonymousRead {
+			anonymous = anonymousRead
+		}
+	}
+	// Clients are rate limited by IP address before authenticating, so that
+	// guessing credentials is slowed down too, then by principal.
+	ipLimiters := make(map[string]*ratelimit.Limiter)
+	principalLimiters := make(map[string]*ratelimit.Limiter)
+	for group, limit := range cfg.RateLimits {
+		ipLimiters[group], principalLimiters[group] = ratelimit.New(limit), ratelimit.New(limit)
+	}
+	route := func(scope, group string) func(http.HandlerFunc) http.Handler {
+		return scopedRoute(cfg.Auth, anonymous, scope, ipLimiters[group], principalLimiters[group])
+	}
+	read := route(auth.ScopeRead, RateLimitRead)
+	write := route(auth.ScopeWrite, RateLimitWrite)
+	admin := route(auth.ScopeAdmin, RateLimitAdmin)
+	public := limitRate(ipLimiters[RateLimitAuth])
+	// Routes of a single recipe take its ID, slug or an alias, and are only
+	// served to clients who may see it.
+	resolve := resolveRecipeKey(recipeHandler.resolveRecipeUC)
+	canSee := requireRecipeAccess(recipeHandler.resolveRecipeUC, recipeHandler.authorizeRecipeUC, false)
+	canEdit := requireRecipeAccess(recipeHandler.resolveRecipeUC, recipeHandler.authorizeRecipeUC, true)
+	canSeeImage := requireImageAccess(imageHandler.findImageRecipesUC, recipeHandler.authorizeRecipeUC)
+
+	mux.Handle("/recipe/", read(recipeHandler.GetRecipe))
+	mux.Handle("PUT /recipe/{recipeID}", write(canEdit(recipeHandler.UpdateRecipe)))
+	mux.Handle("/recipes", read(recipeHandler.ListRecipes))
+	mux.Handle("POST /recipes", write(recipeHandler.CreateRecipe))
+	mux.Handle("/ingredients", read(recipeHandler.ListIngredients))
+	mux.Handle("GET /cookbook.pdf", read(cookbookHandler.GetCookbook))
+
+	mux.Handle("GET /recipes/{recipeID}/revisions", read(canSee(revisionHandler.ListRevisions)))
+	mux.Handle("GET /recipes/{recipeID}/revisions/diff", read(canSee(revisionHandler.DiffRevisions)))
+	mux.Handle("GET /recipes/{recipeID}/revisions/{revision}", read(canSee(revisionHandler.GetRevision)))
+	mux.Handle("POST /recipes/{recipeID}/revisions/{revision}/revert", write(canEdit(revisionHandler.RevertRecipe)))
+	mux.Handle("PUT /recipes/{recipeID}/visibility", write(resolve(accountHandler.SetRecipeVisibility)))
+
+	// Everyone who may see a recipe may review it.
+	mux.Handle("GET /recipes/{recipeID}/reviews", read(canSee(reviewHandler.ListReviews)))
+	mux.Handle("POST /recipes/{recipeID}/reviews", write(canSee(reviewHandler.AddReview)))
+	mux.Handle("GET /recipes/{recipeID}/reviews/{reviewID}", read(canSee(reviewHandler.GetReview)))
+	mux.Handle("PUT /recipes/{recipeID}/reviews/{reviewID}", write(canSee(reviewHandler.UpdateReview)))
+	mux.Handle("DELETE /recipes/{recipeID}/reviews/{reviewID}", write(canSee(reviewHandler.DeleteReview)))
+
+	uploadRoute := "POST /recipes/{recipeID}/steps/{stepID}/illustrations"
+	mux.Handle(uploadRoute, write(canEdit(imageHandler.UploadIllustration)))
+	mux.Handle("GET /images/", read(canSeeImage(imageHandler.ServeImage)))
+
+	mux.Handle("GET /admin/validation", admin(adminHandler.ValidationReport))
+	mux.Handle("POST /admin/reload", admin(adminHandler.Reload))
+	mux.Handle("GET /export", admin(libraryHandler.Export))
+	importRoute := "POST /import"
+	mux.Handle(importRoute, admin(libraryHandler.Import))
+
+	if cfg.RegistrationOpen {
+		mux.HandleFunc("POST /users", public(accountHandler.Register))
+	} else {
+		mux.Handle("POST /users", admin(accountHandler.Register))
+	}
+	mux.HandleFunc("POST /auth/login", public(accountHandler.Login))
+	mux.Handle("POST /auth/logout", read(requireUser(accountHandler.Logout)))
+	mux.Handle("GET /me", read(requireUser(accountHandler.Me)))
+	mux.Handle("POST /households", write(requireUser(accountHandler.CreateHousehold)))
+	mux.Handle("POST /households/{householdID}/members", write(requireUser(accountHandler.AddHouseholdMember)))
 
-	mux.HandleFunc("/recipe/", recipeHandler.GetRecipe)
-	mux.HandleFunc("/recipes", recipeHandler.ListRecipes)
-	mux.HandleFunc("/ingredients", recipeHandler.ListIngredients)
+	mux.Handle("GET /me/favorites", read(requireUser(collectionHandler.ListFavorites)))
+	mux.Handle("PUT /me/favorites/{recipeID}", write(requireUser(resolve(collectionHandler.AddFavorite))))
+	mux.Handle("DELETE /me/favorites/{recipeID}", write(requireUser(resolve(collectionHandler.RemoveFavorite))))
+	mux.Handle("GET /me/collections", read(requireUser(collectionHandler.ListCollections)))
+	mux.Handle("POST /me/collections", write(requireUser(collectionHandler.CreateCollection)))
+	mux.Handle("GET /me/collections/{collectionID}", read(requireUser(collectionHandler.GetCollection)))
+	mux.Handle("PUT /me/collections/{collectionID}", write(requireUser(collectionHandler.UpdateCollection)))
+	mux.Handle("DELETE /me/collections/{collectionID}", write(requireUser(collectionHandler.DeleteCollection)))
 
-	muxWithCors := WithCORS(mux)
-	return muxWithCors
+	routeLimits := map[string]int64{uploadRoute: imageHandler.maxRequestSize(), importRoute: libraryHandler.maxImportSize}
+	handler := WithMaxBodySize(cfg.MaxBodySize, routeLimits, mux, WithCORS(cfg.CORS, mux))
+	return WithTimeout(cfg.RequestTimeout, WithTracing(WithRequestLogging(cfg.Logger, WithMetrics(cfg.Metrics, handler))))
 }
@@ -0,0 +1,8 @@
+package handlers
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// tracer records the spans of the HTTP layer.
+var tracer = otel.Tracer("github.com/fromenjn/recipe-manager/internal/handlers")
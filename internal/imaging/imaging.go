@@ -0,0 +1,105 @@
+// Package imaging validates uploaded pictures and produces the resized and
+// WebP variants served alongside recipe illustrations. Everything is pure Go.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the WebP decoder
+)
+
+// MaxPixels bounds the decoded size of an image, so that a small but highly
+// compressed upload cannot exhaust memory.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+)
+
+// extensions maps the supported content types to the file extension used to store them.
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Sniff detects the content type of data from its first bytes, ignoring any
+// type claimed by the client, and returns it with the matching file extension.
+func Sniff(data []byte) (contentType, extension string, err error) {
+	contentType = http.DetectContentType(data)
+	extension, ok := extensions[contentType]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+	return contentType, extension, nil
+}
+
+// Decode checks the dimensions of an image before decoding it.
+func Decode(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	return img, nil
+}
+
+// Thumbnail scales img down so that neither side exceeds maxSide, keeping the
+// aspect ratio. Images that already fit are returned unchanged.
+func Thumbnail(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+	if width >= height {
+		height = max(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = max(1, width*maxSide/height)
+		height = maxSide
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img in the format identified by contentType.
+func Encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	case "image/gif":
+		err = gif.Encode(&buf, img, nil)
+	case "image/webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", contentType, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	contentType, extension, err := Sniff(testPNG(t, 4, 4))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != "image/png" || extension != ".png" {
+		t.Errorf("expected image/png and .png, got %s and %s", contentType, extension)
+	}
+
+	if _, _, err := Sniff([]byte("<html><body>not an image</body></html>")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	img, err := Decode(testPNG(t, 800, 200))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	thumbnail := Thumbnail(img, 400)
+	if thumbnail.Bounds().Dx() != 400 || thumbnail.Bounds().Dy() != 100 {
+		t.Errorf("expected 400x100 thumbnail, got %v", thumbnail.Bounds())
+	}
+
+	small := Thumbnail(thumbnail, 1000)
+	if small != thumbnail {
+		t.Error("expected images that already fit to be returned unchanged")
+	}
+}
+
+func TestEncodeWebP(t *testing.T) {
+	img, err := Decode(testPNG(t, 16, 8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := Encode(img, "image/webp")
+	if err != nil {
+		t.Fatalf("failed to encode WebP: %v", err)
+	}
+	if contentType, _, err := Sniff(data); err != nil || contentType != "image/webp" {
+		t.Fatalf("expected image/webp, got %s (err %v)", contentType, err)
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("failed to decode WebP: %v", err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("expected bounds %v, got %v", img.Bounds(), decoded.Bounds())
+	}
+	// WebP variants are lossless.
+	r1, g1, b1, _ := img.At(5, 3).RGBA()
+	r2, g2, b2, _ := decoded.At(5, 3).RGBA()
+	if r1 != r2 || g1 != g2 || b1 != b2 {
+		t.Errorf("expected identical pixels, got %v and %v", img.At(5, 3), decoded.At(5, 3))
+	}
+}
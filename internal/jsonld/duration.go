@@ -0,0 +1,49 @@
+package jsonld
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FormatDuration writes minutes as an ISO 8601 duration, e.g. 90 as
+// "PT1H30M", or returns "" for zero.
+func FormatDuration(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours := minutes / 60; hours > 0 {
+		b.WriteString(strconv.Itoa(hours) + "H")
+	}
+	if minutes%60 > 0 {
+		b.WriteString(strconv.Itoa(minutes%60) + "M")
+	}
+	return b.String()
+}
+
+var durationPattern = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseDuration reads an ISO 8601 duration of days, hours, minutes and
+// seconds, e.g. "PT1H30M" or "P0DT45M", and returns it in whole minutes.
+func ParseDuration(duration string) (int, error) {
+	match := durationPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(duration)))
+	if match == nil || duration == "P" || strings.HasSuffix(duration, "T") {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", duration)
+	}
+	var minutes float64
+	for i, perUnit := range []float64{24 * 60, 60, 1, 1.0 / 60} {
+		if match[i+1] == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q: %w", duration, err)
+		}
+		minutes += value * perUnit
+	}
+	return int(math.Round(minutes)), nil
+}
@@ -0,0 +1,152 @@
+// Package jsonld maps recipes to schema.org Recipe documents in JSON-LD, the
+// structured data search engines read from recipe pages, and back: Parse
+// imports the recipes of documents written by this package or published by
+// recipe websites.
+package jsonld
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/render"
+)
+
+// Context is the vocabulary of the documents.
+const Context = "https://schema.org"
+
+// Recipe is a schema.org Recipe.
+type Recipe struct {
+	Context            string                `json:"@context"`
+	Type               string                `json:"@type"`
+	Identifier         string                `json:"identifier,omitempty"`
+	Name               string                `json:"name"`
+	URL                string                `json:"url,omitempty"`
+	Image              []string              `json:"image,omitempty"`
+	RecipeYield        string                `json:"recipeYield,omitempty"`
+	PrepTime           string                `json:"prepTime,omitempty"`
+	CookTime           string                `json:"cookTime,omitempty"`
+	TotalTime          string                `json:"totalTime,omitempty"`
+	Keywords           string                `json:"keywords,omitempty"`
+	Nutrition          *NutritionInformation `json:"nutrition,omitempty"`
+	RecipeIngredient   []string              `json:"recipeIngredient"`
+	RecipeInstructions []HowToStep           `json:"recipeInstructions"`
+}
+
+// HowToStep is a step of the instructions of a schema.org Recipe.
+type HowToStep struct {
+	Type       string        `json:"@type"`
+	Identifier string        `json:"identifier,omitempty"`
+	Name       string        `json:"name,omitempty"`
+	Text       string        `json:"text"`
+	Image      []ImageObject `json:"image,omitempty"`
+}
+
+// ImageObject is a schema.org image with its caption.
+type ImageObject struct {
+	Type       string `json:"@type"`
+	Identifier string `json:"identifier,omitempty"`
+	URL        string `json:"url"`
+	Caption    string `json:"caption,omitempty"`
+}
+
+// NutritionInformation is the schema.org nutrition of a serving, with values
+// such as "250 calories" or "9 g".
+type NutritionInformation struct {
+	Type                string `json:"@type"`
+	Calories            string `json:"calories,omitempty"`
+	FatContent          string `json:"fatContent,omitempty"`
+	SaturatedFatContent string `json:"saturatedFatContent,omitempty"`
+	CarbohydrateContent string `json:"carbohydrateContent,omitempty"`
+	SugarContent        string `json:"sugarContent,omitempty"`
+	FiberContent        string `json:"fiberContent,omitempty"`
+	ProteinContent      string `json:"proteinContent,omitempty"`
+	SodiumContent       string `json:"sodiumContent,omitempty"`
+	CholesterolContent  string `json:"cholesterolContent,omitempty"`
+}
+
+// Links resolves the URLs of documents: Recipe is the page of the recipe,
+// and Image maps the path of an illustration, e.g.
+// /images/chocolate_cake/step1.jpg, to its URL, or to "" to leave it out.
+// Nil functions leave the recipe URL out and the paths unchanged.
+type Links struct {
+	Recipe func(recipe domain.Recipe) string
+	Image  func(path string) string
+}
+
+// FromRecipe maps a recipe to a schema.org Recipe: its ingredients as text
+// with their quantities, its steps with their illustrations, its yield,
+// times and nutrition when known, and its tags as keywords.
+func FromRecipe(recipe domain.Recipe, links Links) Recipe {
+	imageURL := links.Image
+	if imageURL == nil {
+		imageURL = func(path string) string { return path }
+	}
+
+	doc := Recipe{
+		Context:            Context,
+		Type:               "Recipe",
+		Identifier:         recipe.ID,
+		Name:               recipe.Name,
+		Keywords:           strings.Join(recipe.Tags, ", "),
+		RecipeIngredient:   make([]string, 0, len(recipe.Ingredients)),
+		RecipeInstructions: make([]HowToStep, 0, len(recipe.Steps)),
+	}
+	if links.Recipe != nil {
+		doc.URL = links.Recipe(recipe)
+	}
+	if recipe.Servings > 0 {
+		doc.RecipeYield = number(recipe.Servings) + " servings"
+	}
+	if t := recipe.Times; t != nil {
+		doc.PrepTime, doc.CookTime, doc.TotalTime = FormatDuration(t.Prep), FormatDuration(t.Cook), FormatDuration(t.Total)
+	}
+	if n := recipe.Nutrition; n != nil {
+		doc.Nutrition = &NutritionInformation{
+			Type:                "NutritionInformation",
+			Calories:            amount(n.Calories, "calories"),
+			FatContent:          amount(n.Fat, "g"),
+			SaturatedFatContent: amount(n.SaturatedFat, "g"),
+			CarbohydrateContent: amount(n.Carbohydrates, "g"),
+			SugarContent:        amount(n.Sugar, "g"),
+			FiberContent:        amount(n.Fiber, "g"),
+			ProteinContent:      amount(n.Protein, "g"),
+			SodiumContent:       amount(n.Sodium, "mg"),
+			CholesterolContent:  amount(n.Cholesterol, "mg"),
+		}
+	}
+	for _, ingredient := range recipe.Ingredients {
+		doc.RecipeIngredient = append(doc.RecipeIngredient, strings.TrimSpace(render.Amount(ingredient.Quantity, ingredient.Unit)+" "+ingredient.Name))
+	}
+	for _, step := range recipe.Steps {
+		howTo := HowToStep{Type: "HowToStep", Identifier: step.ID, Name: step.Name, Text: strings.TrimSpace(step.Instructions)}
+		if howTo.Text == "" {
+			howTo.Text = step.Name
+		}
+		for _, illustration := range step.RecipeIllustration {
+			url := imageURL(illustration.Filepath)
+			if url == "" {
+				continue
+			}
+			howTo.Image = append(howTo.Image, ImageObject{Type: "ImageObject", Identifier: illustration.ID, URL: url, Caption: illustration.Description})
+			doc.Image = append(doc.Image, url)
+		}
+		doc.RecipeInstructions = append(doc.RecipeInstructions, howTo)
+	}
+	return doc
+}
+
+// number formats a value with at most two decimals.
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// amount formats a nutrition value with its unit, or returns "" if it is
+// unknown.
+func amount(value float64, unit string) string {
+	if value <= 0 {
+		return ""
+	}
+	return number(value) + " " + unit
+}
@@ -0,0 +1,49 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+func TestFromRecipe(t *testing.T) {
+	recipe := domain.Recipe{
+		ID: "2", Name: "Chocolate Cake", Servings: 8, Tags: []string{"dessert", "baking"},
+		Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 200, Unit: "g"}, {Name: "Milk", Quantity: 1.5, Unit: "cups"}, {Name: "Salt"}},
+		Steps: []domain.RecipeStep{
+			{Name: "Mix", Instructions: "Mix everything. ", RecipeIllustration: []domain.RecipeIllustration{{Filepath: "/images/2/mix.jpg"}}},
+			{Name: "Bake"},
+		},
+	}
+	doc := FromRecipe(recipe, Links{
+		Recipe: func(recipe domain.Recipe) string { return "https://example.com/recipe/" + recipe.ID },
+		Image:  func(path string) string { return "https://example.com" + path },
+	})
+
+	if doc.Type != "Recipe" || doc.URL != "https://example.com/recipe/2" || doc.RecipeYield != "8 servings" || doc.Keywords != "dessert, baking" {
+		t.Errorf("unexpected document %+v", doc)
+	}
+	if want := []string{"200 g Flour", "1 ½ cups Milk", "Salt"}; !slices.Equal(doc.RecipeIngredient, want) {
+		t.Errorf("expected ingredients %q, got %q", want, doc.RecipeIngredient)
+	}
+	if len(doc.RecipeInstructions) != 2 || doc.RecipeInstructions[0].Text != "Mix everything." || doc.RecipeInstructions[1].Text != "Bake" {
+		t.Errorf("unexpected instructions %+v", doc.RecipeInstructions)
+	}
+	if want := []string{"https://example.com/images/2/mix.jpg"}; !slices.Equal(doc.Image, want) || len(doc.RecipeInstructions[0].Image) != 1 || doc.RecipeInstructions[0].Image[0].URL != want[0] {
+		t.Errorf("expected the illustration on the recipe and its step, got %+v", doc)
+	}
+	if doc.PrepTime != "" || doc.Nutrition != nil {
+		t.Errorf("expected unknown times and nutrition to be left out, got %+v", doc)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded["@context"] != "https://schema.org" {
+		t.Errorf("expected a schema.org document, got %s", data)
+	}
+}
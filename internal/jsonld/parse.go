@@ -0,0 +1,423 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// ErrNoRecipe is returned by Parse for documents without a schema.org Recipe.
+var ErrNoRecipe = errors.New("no schema.org Recipe in the document")
+
+// Parse reads the schema.org Recipes of a JSON-LD document: a Recipe, an
+// array of nodes, a @graph, or a page whose mainEntity is a Recipe, as
+// written by FromRecipe or found on recipe websites. Ingredients are split
+// into quantity, known unit and name; instructions may be text, a list of
+// texts, or HowToSteps grouped in HowToSections. imagePath maps image URLs
+// to the paths of illustrations, e.g. back to /images/2/step1.jpg; nil
+// keeps the URLs.
+func Parse(data []byte, imagePath func(url string) string) ([]domain.Recipe, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON-LD: %w", err)
+	}
+	if imagePath == nil {
+		imagePath = func(url string) string { return url }
+	}
+
+	var recipes []domain.Recipe
+	for _, node := range recipeNodes(doc) {
+		recipes = append(recipes, parseRecipe(node, imagePath))
+	}
+	if len(recipes) == 0 {
+		return nil, ErrNoRecipe
+	}
+	return recipes, nil
+}
+
+// recipeNodes finds the Recipe nodes of a document, depth first.
+func recipeNodes(value any) []map[string]any {
+	switch value := value.(type) {
+	case []any:
+		var nodes []map[string]any
+		for _, item := range value {
+			nodes = append(nodes, recipeNodes(item)...)
+		}
+		return nodes
+	case map[string]any:
+		if hasType(value, "Recipe") {
+			return []map[string]any{value}
+		}
+		var nodes []map[string]any
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage", "itemListElement", "item"} {
+			nodes = append(nodes, recipeNodes(value[key])...)
+		}
+		return nodes
+	}
+	return nil
+}
+
+// hasType tells whether a node has the type name, among others.
+func hasType(node map[string]any, name string) bool {
+	for _, t := range list(node["@type"]) {
+		if s, ok := t.(string); ok && (s == name || strings.HasSuffix(s, "/"+name) || strings.HasSuffix(s, ":"+name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRecipe(node map[string]any, imagePath func(string) string) domain.Recipe {
+	recipe := domain.Recipe{
+		ID:          identifier(node["identifier"]),
+		Name:        text(node["name"]),
+		Servings:    servings(node["recipeYield"]),
+		Tags:        keywords(node["keywords"], node["recipeCategory"]),
+		Ingredients: []domain.Ingredient{},
+		Steps:       []domain.RecipeStep{},
+	}
+
+	var times domain.Times
+	for key, minutes := range map[string]*int{"prepTime": &times.Prep, "cookTime": &times.Cook, "totalTime": &times.Total} {
+		if d, err := ParseDuration(text(node[key])); err == nil {
+			*minutes = d
+		}
+	}
+	if times != (domain.Times{}) {
+		recipe.Times = &times
+	}
+	if nutrition, ok := node["nutrition"].(map[string]any); ok {
+		recipe.Nutrition = parseNutrition(nutrition)
+	}
+
+	names := make(map[string]bool)
+	for _, line := range list(node["recipeIngredient"]) {
+		ingredient, ok := ParseIngredient(text(line))
+		if !ok || names[ingredient.Name] {
+			continue
+		}
+		names[ingredient.Name] = true
+		recipe.Ingredients = append(recipe.Ingredients, ingredient)
+	}
+
+	stepIDs, illustrationIDs := make(ids), make(ids)
+	for _, step := range instructions(node["recipeInstructions"]) {
+		s := domain.RecipeStep{ID: stepIDs.use(identifier(step["identifier"]), "step"), RecipeIllustration: []domain.RecipeIllustration{}}
+		s.Name, s.Instructions = text(step["name"]), text(step["text"])
+		if s.Instructions == s.Name {
+			s.Instructions = ""
+		}
+		for _, image := range list(step["image"]) {
+			url, caption, id := imageObject(image)
+			if url == "" {
+				continue
+			}
+			s.RecipeIllustration = append(s.RecipeIllustration, domain.RecipeIllustration{
+				ID: illustrationIDs.use(id, "illustration"), Description: caption, Filepath: imagePath(url),
+			})
+		}
+		recipe.Steps = append(recipe.Steps, s)
+	}
+	return recipe
+}
+
+// instructions flattens recipe instructions into steps with a name, a
+// text and images.
+func instructions(value any) []map[string]any {
+	switch value := value.(type) {
+	case string:
+		var steps []map[string]any
+		for _, line := range strings.Split(stripTags(value), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				steps = append(steps, map[string]any{"text": line})
+			}
+		}
+		return steps
+	case []any:
+		var steps []map[string]any
+		for _, item := range value {
+			steps = append(steps, instructions(item)...)
+		}
+		return steps
+	case map[string]any:
+		if items, ok := value["itemListElement"]; ok && !hasType(value, "HowToStep") {
+			return instructions(items)
+		}
+		if text(value["text"]) == "" && text(value["name"]) == "" {
+			return nil
+		}
+		return []map[string]any{value}
+	}
+	return nil
+}
+
+func parseNutrition(node map[string]any) *domain.Nutrition {
+	var n domain.Nutrition
+	for key, fact := range map[string]struct {
+		value *float64
+		unit  string
+	}{
+		"calories":            {&n.Calories, "kcal"},
+		"fatContent":          {&n.Fat, "g"},
+		"saturatedFatContent": {&n.SaturatedFat, "g"},
+		"carbohydrateContent": {&n.Carbohydrates, "g"},
+		"sugarContent":        {&n.Sugar, "g"},
+		"fiberContent":        {&n.Fiber, "g"},
+		"proteinContent":      {&n.Protein, "g"},
+		"sodiumContent":       {&n.Sodium, "mg"},
+		"cholesterolContent":  {&n.Cholesterol, "mg"},
+	} {
+		*fact.value = nutritionValue(text(node[key]), fact.unit)
+	}
+	if n == (domain.Nutrition{}) {
+		return nil
+	}
+	return &n
+}
+
+var (
+	nutritionPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*([a-zA-Z]*)`)
+	// thousands matches values such as 1,200, as opposed to 1,2.
+	thousands = regexp.MustCompile(`^\d{1,3},\d{3}$`)
+)
+
+// nutritionValue reads a value such as "9 g" or "1.2 g" in unit, converting
+// between grams and milligrams and from kilojoules. Unreadable values are
+// zero, as unknown.
+func nutritionValue(value, unit string) float64 {
+	match := nutritionPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0
+	}
+	digits := match[1]
+	if thousands.MatchString(digits) {
+		digits = strings.Replace(digits, ",", "", 1)
+	}
+	number, err := strconv.ParseFloat(strings.Replace(digits, ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	switch given := strings.ToLower(match[2]); {
+	case given == "kj":
+		number /= 4.184
+	case given == "mg" && unit == "g":
+		number /= 1000
+	case (given == "g" || given == "grams") && unit == "mg":
+		number *= 1000
+	}
+	return math.Round(number*100) / 100
+}
+
+// imageObject reads an image given as a URL or as an ImageObject.
+func imageObject(value any) (url, caption, id string) {
+	switch value := value.(type) {
+	case string:
+		return value, "", ""
+	case map[string]any:
+		url = text(value["url"])
+		if url == "" {
+			url = text(value["contentUrl"])
+		}
+		return url, text(value["caption"]), identifier(value["identifier"])
+	}
+	return "", "", ""
+}
+
+// identifier reads an identifier given as text, a number, or a
+// PropertyValue.
+func identifier(value any) string {
+	switch value := value.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case map[string]any:
+		return identifier(value["value"])
+	case []any:
+		if len(value) > 0 {
+			return identifier(value[0])
+		}
+	}
+	return text(value)
+}
+
+// servings reads a yield such as 4, "4", "4 servings" or ["4", "4 cakes"].
+func servings(value any) float64 {
+	for _, item := range list(value) {
+		if n, ok := item.(float64); ok && n > 0 {
+			return n
+		}
+		if q, _ := parseQuantity(text(item)); q > 0 {
+			return q
+		}
+	}
+	return 0
+}
+
+// keywords reads comma-separated keywords and categories as unique tags.
+func keywords(values ...any) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, item := range list(value) {
+			for _, tag := range strings.Split(text(item), ",") {
+				tag = strings.TrimSpace(tag)
+				if tag != "" && !seen[strings.ToLower(tag)] {
+					seen[strings.ToLower(tag)] = true
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// ParseIngredient splits an ingredient line such as "1 ½ cups milk",
+// "200g flour" or "2 cloves of garlic" into its quantity, its unit if it is
+// one of domain.KnownUnits, and its name. Lines without a name are refused.
+func ParseIngredient(line string) (domain.Ingredient, bool) {
+	quantity, rest := parseQuantity(strings.TrimSpace(line))
+	var ingredient domain.Ingredient
+	if quantity > 0 {
+		ingredient.Quantity = math.Round(quantity*1000) / 1000
+		ingredient.Unit, rest = parseUnit(rest)
+		if ingredient.Unit != "" {
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, "of "))
+		}
+	}
+	ingredient.Name = strings.TrimSpace(rest)
+	if ingredient.Name == "" {
+		return ingredient, false
+	}
+	return ingredient, true
+}
+
+var fractionGlyphs = map[rune]float64{
+	'¼': 1.0 / 4, '½': 1.0 / 2, '¾': 3.0 / 4, '⅓': 1.0 / 3, '⅔': 2.0 / 3,
+	'⅛': 1.0 / 8, '⅜': 3.0 / 8, '⅝': 5.0 / 8, '⅞': 7.0 / 8,
+}
+
+var numberPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)(?:/(\d+))?`)
+
+// parseQuantity reads the quantity at the start of s, e.g. 1.5, 1/2, ½,
+// 1½ or 1 1/2, and returns it with the rest of s. Of a range such as
+// "2-3", the lower bound is kept.
+func parseQuantity(s string) (float64, string) {
+	var quantity float64
+	rest := s
+	for parts := 0; parts < 2; parts++ {
+		trimmed := strings.TrimLeft(rest, " ")
+		if r, size := utf8.DecodeRuneInString(trimmed); fractionGlyphs[r] > 0 {
+			quantity += fractionGlyphs[r]
+			rest = trimmed[size:]
+			break
+		}
+		match := numberPattern.FindStringSubmatch(trimmed)
+		if match == nil || (parts > 0 && match[2] == "") {
+			break
+		}
+		value, _ := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+		if match[2] != "" {
+			denominator, _ := strconv.ParseFloat(match[2], 64)
+			if denominator == 0 {
+				break
+			}
+			value /= denominator
+		}
+		quantity += value
+		rest = trimmed[len(match[0]):]
+		if match[2] != "" {
+			break
+		}
+	}
+	if quantity > 0 {
+		if r := strings.TrimLeft(rest, " "); strings.HasPrefix(r, "-") || strings.HasPrefix(r, "–") {
+			if upper, after := parseQuantity(strings.TrimLeft(r, "-–")); upper > 0 {
+				rest = after
+			}
+		}
+	}
+	return quantity, strings.TrimSpace(rest)
+}
+
+// units are the known units by decreasing length, so that "fl oz" is
+// matched before "oz".
+var units = func() []string {
+	units := slices.DeleteFunc(slices.Clone(domain.KnownUnits), func(unit string) bool { return unit == "" })
+	slices.SortStableFunc(units, func(a, b string) int { return len(b) - len(a) })
+	return units
+}()
+
+// parseUnit reads a known unit, possibly abbreviated with a dot, at the start
+// of s, followed by a space.
+func parseUnit(s string) (string, string) {
+	for _, unit := range units {
+		if len(s) <= len(unit) || !strings.EqualFold(s[:len(unit)], unit) {
+			continue
+		}
+		rest := strings.TrimPrefix(s[len(unit):], ".")
+		if rest != "" && rest[0] == ' ' {
+			return unit, strings.TrimSpace(rest)
+		}
+	}
+	return "", s
+}
+
+var tagPattern = regexp.MustCompile(`<br\s*/?>|</p>|<[^>]*>`)
+
+// stripTags turns the HTML some websites put in texts into plain text,
+// keeping line breaks.
+func stripTags(s string) string {
+	s = tagPattern.ReplaceAllStringFunc(s, func(tag string) string {
+		if strings.HasPrefix(tag, "<br") || tag == "</p>" {
+			return "\n"
+		}
+		return ""
+	})
+	return html.UnescapeString(s)
+}
+
+// text reads a string value, or the first of a list, without HTML.
+func text(value any) string {
+	switch value := value.(type) {
+	case string:
+		return strings.TrimSpace(stripTags(value))
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		if len(value) > 0 {
+			return text(value[0])
+		}
+	}
+	return ""
+}
+
+// list reads a value that may be given once or as an array.
+func list(value any) []any {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return value
+	}
+	return []any{value}
+}
+
+// ids hands out identifiers unique in a recipe, keeping the given ones when
+// possible.
+type ids map[string]bool
+
+func (used ids) use(id, prefix string) string {
+	for n := len(used) + 1; id == "" || used[id]; n++ {
+		id = prefix + strconv.Itoa(n)
+	}
+	used[id] = true
+	return id
+}
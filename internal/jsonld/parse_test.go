@@ -0,0 +1,163 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+func TestParse_RoundTrip(t *testing.T) {
+	recipe := domain.Recipe{
+		ID: "2", Name: "Chocolate Cake", Servings: 8, Tags: []string{"dessert", "baking"},
+		Times:     &domain.Times{Prep: 20, Cook: 45, Total: 65},
+		Nutrition: &domain.Nutrition{Calories: 420, Fat: 21.5, Sugar: 35, Sodium: 180},
+		Ingredients: []domain.Ingredient{
+			{Name: "Flour", Quantity: 200, Unit: "g"},
+			{Name: "Milk", Quantity: 1.5, Unit: "cups"},
+			{Name: "Vanilla extract", Quantity: 0.25, Unit: "tsp"},
+			{Name: "Eggs", Quantity: 3},
+			{Name: "Salt"},
+		},
+		Steps: []domain.RecipeStep{
+			{ID: "mix", Name: "Mix", Instructions: "Mix everything.", RecipeIllustration: []domain.RecipeIllustration{
+				{ID: "batter", Description: "The batter", Filepath: "/images/2/mix.jpg"},
+			}},
+			{ID: "bake", Name: "Bake", RecipeIllustration: []domain.RecipeIllustration{}},
+		},
+	}
+	data, err := json.Marshal(FromRecipe(recipe, Links{
+		Image: func(path string) string { return "https://example.com" + path },
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipes, err := Parse(data, func(url string) string { return strings.TrimPrefix(url, "https://example.com") })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recipes) != 1 || !reflect.DeepEqual(recipes[0], recipe) {
+		t.Errorf("expected\n%+v\ngot\n%+v", recipe, recipes)
+	}
+}
+
+func TestParse_Websites(t *testing.T) {
+	// A page graph as written by common blog plugins.
+	doc := `{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "WebPage", "name": "Best pancakes"},
+			{
+				"@type": ["Recipe", "NewsArticle"],
+				"identifier": {"@type": "PropertyValue", "value": 1234},
+				"name": "Fluffy &amp; light pancakes",
+				"recipeYield": ["4", "4 servings"],
+				"prepTime": "PT10M",
+				"cookTime": "P0DT0H20M",
+				"keywords": "breakfast, quick",
+				"recipeCategory": ["Breakfast", "Brunch"],
+				"nutrition": {"@type": "NutritionInformation", "calories": "1,200 kJ", "sodiumContent": "0.4 g", "fatContent": "9,5 g"},
+				"recipeIngredient": ["1 ½ cups of flour", "2-3 large eggs", "1 1/4 cup milk", "200g butter", "2 Tbsp. sugar", "½ tsp salt", "1 fl oz rum", "Maple syrup, to serve"],
+				"recipeInstructions": [
+					{"@type": "HowToSection", "name": "Batter", "itemListElement": [
+						{"@type": "HowToStep", "name": "Whisk the eggs.", "text": "Whisk the eggs.", "image": "https://example.com/whisk.jpg"},
+						{"@type": "HowToStep", "text": "Add the flour<br>and the milk."}
+					]},
+					"Cook in a hot pan."
+				]
+			}
+		]
+	}`
+	recipes, err := Parse([]byte(doc), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recipes) != 1 {
+		t.Fatalf("expected one recipe, got %+v", recipes)
+	}
+	recipe := recipes[0]
+	if recipe.ID != "1234" || recipe.Name != "Fluffy & light pancakes" || recipe.Servings != 4 {
+		t.Errorf("unexpected recipe %+v", recipe)
+	}
+	if want := []string{"breakfast", "quick", "Brunch"}; !slices.Equal(recipe.Tags, want) {
+		t.Errorf("expected tags %q, got %q", want, recipe.Tags)
+	}
+	if recipe.Times == nil || *recipe.Times != (domain.Times{Prep: 10, Cook: 20}) {
+		t.Errorf("unexpected times %+v", recipe.Times)
+	}
+	if n := recipe.Nutrition; n == nil || *n != (domain.Nutrition{Calories: 286.81, Sodium: 400, Fat: 9.5}) {
+		t.Errorf("unexpected nutrition %+v", n)
+	}
+
+	want := []domain.Ingredient{
+		{Name: "flour", Quantity: 1.5, Unit: "cups"},
+		{Name: "large eggs", Quantity: 2},
+		{Name: "milk", Quantity: 1.25, Unit: "cup"},
+		{Name: "butter", Quantity: 200, Unit: "g"},
+		{Name: "sugar", Quantity: 2, Unit: "tbsp"},
+		{Name: "salt", Quantity: 0.5, Unit: "tsp"},
+		{Name: "rum", Quantity: 1, Unit: "fl oz"},
+		{Name: "Maple syrup, to serve"},
+	}
+	if !reflect.DeepEqual(recipe.Ingredients, want) {
+		t.Errorf("expected ingredients\n%+v\ngot\n%+v", want, recipe.Ingredients)
+	}
+
+	var steps []string
+	for _, step := range recipe.Steps {
+		steps = append(steps, step.ID+": "+step.Name+"|"+step.Instructions)
+	}
+	if want := []string{"step1: Whisk the eggs.|", "step2: |Add the flour\nand the milk.", "step3: |Cook in a hot pan."}; !slices.Equal(steps, want) {
+		t.Errorf("expected steps %q, got %q", want, steps)
+	}
+	if images := recipe.Steps[0].RecipeIllustration; len(images) != 1 || images[0].ID != "illustration1" || images[0].Filepath != "https://example.com/whisk.jpg" {
+		t.Errorf("unexpected illustrations %+v", images)
+	}
+}
+
+func TestParse_Instructions(t *testing.T) {
+	doc := `{"@type": "Recipe", "name": "Toast", "mainEntity": null, "recipeInstructions": "Slice the bread.\n\nToast it."}`
+	recipes, err := Parse([]byte(doc), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if steps := recipes[0].Steps; len(steps) != 2 || steps[1].Instructions != "Toast it." {
+		t.Errorf("expected a step per line, got %+v", steps)
+	}
+
+	// Pages may nest the recipe in their main entity, or list several.
+	recipes, err = Parse([]byte(`[{"@type": "WebPage", "mainEntity": {"@type": "Recipe", "name": "A"}}, {"@type": "Recipe", "name": "B"}]`), nil)
+	if err != nil || len(recipes) != 2 || recipes[0].Name != "A" || recipes[1].Name != "B" {
+		t.Errorf("expected both recipes, got %+v, %v", recipes, err)
+	}
+
+	if _, err := Parse([]byte(`{"@type": "WebPage"}`), nil); !errors.Is(err, ErrNoRecipe) {
+		t.Errorf("expected ErrNoRecipe, got %v", err)
+	}
+	if _, err := Parse([]byte(`{`), nil); err == nil {
+		t.Error("expected invalid JSON to be refused")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for minutes, want := range map[int]string{0: "", 45: "PT45M", 60: "PT1H", 90: "PT1H30M"} {
+		if got := FormatDuration(minutes); got != want {
+			t.Errorf("FormatDuration(%d) = %q, want %q", minutes, got, want)
+		}
+	}
+	for duration, want := range map[string]int{"PT1H30M": 90, "P1DT2H": 1560, "PT90S": 2, "pt0.5h": 30} {
+		if got, err := ParseDuration(duration); err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %d, %v, want %d", duration, got, err, want)
+		}
+	}
+	for _, duration := range []string{"", "P", "PT", "1H", "PT1X"} {
+		if _, err := ParseDuration(duration); err == nil {
+			t.Errorf("expected %q to be refused", duration)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger.Info("hidden")
+	logger.Warn("shown", "key", "value")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), `"key":"value"`) {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	buf.Reset()
+	logger, err = New(&buf, "DEBUG", "text")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger.Debug("shown", "key", "value")
+	if !strings.Contains(buf.String(), "key=value") {
+		t.Errorf("expected text output, got %q", buf.String())
+	}
+
+	if _, err := New(&buf, "verbose", "json"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if _, err := New(&buf, "info", "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("expected the default logger without a request logger")
+	}
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if FromContext(WithLogger(context.Background(), logger)) != logger {
+		t.Error("expected the logger stored in the context")
+	}
+}
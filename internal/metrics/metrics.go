@@ -0,0 +1,161 @@
+// Package metrics collects Prometheus metrics about HTTP traffic and the
+// recipe repository, and serves them in the Prometheus text format.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+)
+
+const namespace = "recipe_manager"
+
+// Metrics owns a registry with the application metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+	handler  http.Handler
+
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	reloads        prometheus.Counter
+	reloadFailures prometheus.Counter
+	scalingErrors  *prometheus.CounterVec
+}
+
+// New registers the request, repository and runtime metrics. The recipe and
+// ingredient gauges are computed from repo when scraped.
+func New(repo repository.RecipeRepository) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time spent serving HTTP requests, by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		reloads: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "repository_reloads_total",
+			Help:      "Reloads of the recipe files, successful or not.",
+		}),
+		reloadFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "repository_reload_failures_total",
+			Help:      "Reloads of the recipe files that failed and kept the previous recipes.",
+		}),
+		scalingErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scaling_errors_total",
+			Help:      "Recipes that could not be scaled, by reason.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests, m.duration, m.reloads, m.reloadFailures, m.scalingErrors,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "recipes_loaded",
+			Help:      "Recipes currently loaded.",
+		}, func() float64 {
+			recipes, _ := repo.ListAll(context.Background())
+			return float64(len(recipes))
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "ingredients_loaded",
+			Help:      "Distinct ingredient names used by the loaded recipes.",
+		}, func() float64 {
+			recipes, _ := repo.ListAll(context.Background())
+			names := make(map[string]bool)
+			for _, recipe := range recipes {
+				for _, ingredient := range recipe.Ingredients {
+					names[ingredient.Name] = true
+				}
+			}
+			return float64(len(names))
+		}),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	m.handler = promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+	return m
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.handler.ServeHTTP(w, r)
+}
+
+// ObserveRequest records a served HTTP request.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// InstrumentRepository counts the reloads of repo and their failures.
+func (m *Metrics) InstrumentRepository(repo repository.RecipeRepository) repository.RecipeRepository {
+	return &instrumentedRepository{RecipeRepository: repo, metrics: m}
+}
+
+type instrumentedRepository struct {
+	repository.RecipeRepository
+	metrics *Metrics
+}
+
+func (r *instrumentedRepository) Reload(ctx context.Context) error {
+	err := r.RecipeRepository.Reload(ctx)
+	r.metrics.reloads.Inc()
+	if err != nil {
+		r.metrics.reloadFailures.Inc()
+	}
+	return err
+}
+
+// InstrumentRecipeService counts the errors returned when scaling recipes.
+func (m *Metrics) InstrumentRecipeService(service domain.RecipeService) domain.RecipeService {
+	return &instrumentedRecipeService{RecipeService: service, metrics: m}
+}
+
+type instrumentedRecipeService struct {
+	domain.RecipeService
+	metrics *Metrics
+}
+
+func (s *instrumentedRecipeService) ComputeRatios(recipe *domain.Recipe, constraintName string, constraintQuantity float64) error {
+	err := s.RecipeService.ComputeRatios(recipe, constraintName, constraintQuantity)
+	s.metrics.observeScaling(err)
+	return err
+}
+
+func (s *instrumentedRecipeService) Scale(recipe *domain.Recipe, factor float64) error {
+	err := s.RecipeService.Scale(recipe, factor)
+	s.metrics.observeScaling(err)
+	return err
+}
+
+func (m *Metrics) observeScaling(err error) {
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, domain.ErrIngredientNotFound):
+		m.scalingErrors.WithLabelValues("ingredient_not_found").Inc()
+	case errors.Is(err, domain.ErrInvalidRatio):
+		m.scalingErrors.WithLabelValues("invalid_ratio").Inc()
+	default:
+		m.scalingErrors.WithLabelValues("other").Inc()
+	}
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+)
+
+// stubRepo provides the recipes for the gauges and a configurable reload error.
+type stubRepo struct {
+	repository.RecipeRepository
+	recipes   []domain.Recipe
+	reloadErr error
+}
+
+func (r *stubRepo) ListAll(ctx context.Context) ([]domain.Recipe, error) {
+	return r.recipes, nil
+}
+
+func (r *stubRepo) Reload(ctx context.Context) error {
+	return r.reloadErr
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from the metrics handler, got %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func expectLines(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected metrics to contain %q", line)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	repo := &stubRepo{recipes: []domain.Recipe{
+		{ID: "1", Ingredients: []domain.Ingredient{{Name: "Flour"}, {Name: "Milk"}}},
+		{ID: "2", Ingredients: []domain.Ingredient{{Name: "Flour"}}},
+	}}
+	m := New(repo)
+
+	m.ObserveRequest(http.MethodGet, "GET /recipes", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "GET /recipes", http.StatusOK, 30*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/recipe/", http.StatusNotFound, time.Millisecond)
+
+	instrumented := m.InstrumentRepository(repo)
+	instrumented.Reload(context.Background())
+	repo.reloadErr = errors.New("broken file")
+	instrumented.Reload(context.Background())
+
+	service := m.InstrumentRecipeService(domain.NewRecipeService())
+	recipe := domain.Recipe{Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 100}}}
+	service.ComputeRatios(&recipe, "Sugar", 50)
+	service.Scale(&recipe, -1)
+	service.Scale(&recipe, 2)
+
+	expectLines(t, scrape(t, m),
+		`recipe_manager_http_requests_total{method="GET",route="GET /recipes",status="200"} 2`,
+		`recipe_manager_http_requests_total{method="GET",route="/recipe/",status="404"} 1`,
+		`recipe_manager_http_request_duration_seconds_count{method="GET",route="GET /recipes"} 2`,
+		`recipe_manager_recipes_loaded 2`,
+		`recipe_manager_ingredients_loaded 2`,
+		`recipe_manager_repository_reloads_total 2`,
+		`recipe_manager_repository_reload_failures_total 1`,
+		`recipe_manager_scaling_errors_total{reason="ingredient_not_found"} 1`,
+		`recipe_manager_scaling_errors_total{reason="invalid_ratio"} 1`,
+	)
+}
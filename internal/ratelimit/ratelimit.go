@@ -0,0 +1,113 @@
+// Package ratelimit limits how often each client may do something, with one
+// token bucket per client.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is the rate a client may sustain and the burst it may spend at once.
+type Limit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// Decision is the outcome of a request against a limiter.
+type Decision struct {
+	Allowed bool
+	// Limit is the burst of the client, the most requests it may send at once.
+	Limit int
+	// Remaining is how many more requests the client may send right now.
+	Remaining int
+	// RetryAfter is how long a refused client must wait for its next request.
+	RetryAfter time.Duration
+	// Reset is how long until the client may send a full burst again.
+	Reset time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter keeps a token bucket per client key. Buckets start full, hold up to
+// Burst tokens and are refilled at RequestsPerMinute; each request spends a
+// token.
+type Limiter struct {
+	limit Limit
+	// perToken is how long the bucket takes to refill one token.
+	perToken time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New returns a limiter allowing each client limit. A zero burst defaults to
+// the rate of one minute.
+func New(limit Limit) *Limiter {
+	if limit.Burst <= 0 {
+		limit.Burst = limit.RequestsPerMinute
+	}
+	return &Limiter{
+		limit:    limit,
+		perToken: time.Minute / time.Duration(limit.RequestsPerMinute),
+		now:      time.Now,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Allow spends a token of the client identified by key, if it has one left.
+func (l *Limiter) Allow(key string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+
+	decision := Decision{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = l.duration(1 - b.tokens)
+	}
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.Reset = l.duration(float64(l.limit.Burst) - b.tokens)
+	return decision
+}
+
+// refill returns the tokens of b at now, capped at the burst.
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + float64(now.Sub(b.last))/float64(l.perToken)
+	return math.Min(tokens, float64(l.limit.Burst))
+}
+
+// duration returns how long the bucket takes to refill tokens.
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens * float64(l.perToken)))
+}
+
+// sweep forgets the clients whose bucket is full again, at most once per
+// minute, so that the limiter does not grow with every client ever seen.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(Limit{RequestsPerMinute: 60, Burst: 3})
+	limiter.now = func() time.Time { return now }
+
+	for i := 2; i >= 0; i-- {
+		decision := limiter.Allow("alice")
+		if !decision.Allowed || decision.Remaining != i || decision.Limit != 3 {
+			t.Fatalf("expected request to be allowed with %d remaining, got %+v", i, decision)
+		}
+	}
+	decision := limiter.Allow("alice")
+	if decision.Allowed || decision.RetryAfter != time.Second || decision.Reset != 3*time.Second {
+		t.Errorf("expected the fourth request to wait 1s, got %+v", decision)
+	}
+	if !limiter.Allow("bob").Allowed {
+		t.Error("expected clients to have their own bucket")
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if decision := limiter.Allow("alice"); !decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("expected a refilled token, got %+v", decision)
+	}
+
+	// Full buckets are forgotten.
+	now = now.Add(time.Hour)
+	limiter.Allow("carol")
+	if _, ok := limiter.buckets["alice"]; ok {
+		t.Error("expected the full bucket of alice to be swept")
+	}
+}
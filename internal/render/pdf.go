@@ -0,0 +1,447 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/imaging"
+)
+
+// PDFContentType is the media type of recipe cards and cookbooks.
+const PDFContentType = "application/pdf"
+
+// Images loads illustrations by their path in recipes, e.g.
+// /images/chocolate_cake/step1.jpg. Illustrations that fail to load are left
+// out of documents, and all of them are when Images is nil.
+type Images func(path string) ([]byte, error)
+
+// Layout of A4 pages, in millimetres.
+const (
+	pageMargin     = 18.0
+	lineHeight     = 6.0
+	rowHeight      = 7.0
+	quantityWidth  = 40.0
+	numberWidth    = 8.0
+	pageRefWidth   = 20.0
+	maxImageWidth  = 80.0
+	maxImageHeight = 60.0
+)
+
+// The core PDF fonts only cover Windows-1252; fractions missing from it are
+// spelled out.
+var pdfReplacer = strings.NewReplacer("⅓", "1/3", "⅔", "2/3")
+
+// pdfDocument lays recipes out on the pages of a PDF document.
+type pdfDocument struct {
+	pdf    *fpdf.Fpdf
+	encode func(string) string
+	images Images
+	// loaded holds the images registered in the document by path, nil for
+	// those that could not be loaded.
+	loaded map[string]*fpdf.ImageInfoType
+	// unnumbered is the number of leading pages without a page number.
+	unnumbered int
+}
+
+func newPDFDocument(title string, images Images) *pdfDocument {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("recipe-manager", false)
+	d := &pdfDocument{
+		pdf:    pdf,
+		encode: pdf.UnicodeTranslatorFromDescriptor(""),
+		images: images,
+		loaded: make(map[string]*fpdf.ImageInfoType),
+	}
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() <= d.unnumbered {
+			return
+		}
+		pdf.SetY(-pageMargin + 4)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, lineHeight, strconv.Itoa(pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	return d
+}
+
+// RecipeCard writes recipe as a PDF document: its name and yield, a table of
+// its ingredients and its numbered steps with their illustrations. A non-nil
+// scaling is noted under the name.
+func RecipeCard(w io.Writer, recipe domain.Recipe, scaling *Scaling, images Images) error {
+	d := newPDFDocument(recipe.Name, images)
+	d.pdf.AddPage()
+	d.recipe(recipe, scaling)
+	if err := d.pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render recipe %s as PDF: %w", recipe.ID, err)
+	}
+	return nil
+}
+
+// Cookbook writes recipes as a PDF book: a title page, a table of contents,
+// a recipe card per recipe starting on a new page, and an index of the
+// ingredients with the pages of the recipes using them.
+func Cookbook(w io.Writer, title string, recipes []domain.Recipe, images Images) error {
+	images = cached(images)
+
+	// The contents and the index refer to pages that are only known once the
+	// recipes are laid out: a first pass finds them, the second writes the book.
+	draft := newPDFDocument(title, images)
+	pages := draft.cookbook(title, recipes, make([]int, len(recipes)))
+	if err := draft.pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render cookbook %q as PDF: %w", title, err)
+	}
+
+	d := newPDFDocument(title, images)
+	d.cookbook(title, recipes, pages)
+	if err := d.pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render cookbook %q as PDF: %w", title, err)
+	}
+	return nil
+}
+
+// cached loads each image once, for documents laid out twice.
+func cached(images Images) Images {
+	if images == nil {
+		return nil
+	}
+	type result struct {
+		data []byte
+		err  error
+	}
+	results := make(map[string]result)
+	return func(path string) ([]byte, error) {
+		r, ok := results[path]
+		if !ok {
+			r.data, r.err = images(path)
+			results[path] = r
+		}
+		return r.data, r.err
+	}
+}
+
+// cookbook lays out a book whose recipes start on the given pages, and
+// returns the pages they actually start on.
+func (d *pdfDocument) cookbook(title string, recipes []domain.Recipe, pages []int) []int {
+	pdf := d.pdf
+	d.unnumbered = 1
+
+	pdf.AddPage()
+	pdf.SetY(100)
+	pdf.SetFont("Helvetica", "B", 28)
+	pdf.MultiCell(0, 12, d.text(title), "", "C", false)
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "I", 12)
+	count := fmt.Sprintf("%d recipes", len(recipes))
+	if len(recipes) == 1 {
+		count = "1 recipe"
+	}
+	pdf.CellFormat(0, lineHeight, count, "", 1, "C", false, 0, "")
+
+	pdf.AddPage()
+	d.title("Contents")
+	links := make([]int, len(recipes))
+	for i, recipe := range recipes {
+		links[i] = pdf.AddLink()
+		pdf.SetFont("Helvetica", "", 11)
+		d.leaderLine(recipe.Name, strconv.Itoa(pages[i]), links[i])
+	}
+
+	starts := make([]int, len(recipes))
+	for i, recipe := range recipes {
+		pdf.AddPage()
+		pdf.SetLink(links[i], 0, -1)
+		starts[i] = pdf.PageNo()
+		d.recipe(recipe, nil)
+	}
+
+	if index := ingredientIndex(recipes, pages); len(index) > 0 {
+		pdf.AddPage()
+		d.title("Index")
+		var letter rune
+		for _, entry := range index {
+			if first, _ := utf8.DecodeRuneInString(entry.name); unicode.ToUpper(first) != letter {
+				letter = unicode.ToUpper(first)
+				pdf.Ln(2)
+				pdf.SetFont("Helvetica", "B", 12)
+				pdf.CellFormat(0, rowHeight, d.text(string(letter)), "", 1, "L", false, 0, "")
+			}
+			pdf.SetFont("Helvetica", "", 10)
+			d.leaderLine(entry.name, joinPages(entry.pages), 0)
+		}
+	}
+	return starts
+}
+
+// indexEntry lists the pages of the recipes using an ingredient.
+type indexEntry struct {
+	name  string
+	pages []int
+}
+
+// ingredientIndex gathers the ingredients of recipes, compared
+// case-insensitively, sorted by name.
+func ingredientIndex(recipes []domain.Recipe, pages []int) []indexEntry {
+	entries := make(map[string]*indexEntry)
+	for i, recipe := range recipes {
+		for _, ingredient := range recipe.Ingredients {
+			name := strings.TrimSpace(ingredient.Name)
+			key := strings.ToLower(name)
+			entry, ok := entries[key]
+			if !ok {
+				entry = &indexEntry{name: name}
+				entries[key] = entry
+			}
+			if !slices.Contains(entry.pages, pages[i]) {
+				entry.pages = append(entry.pages, pages[i])
+			}
+		}
+	}
+	index := make([]indexEntry, 0, len(entries))
+	for _, entry := range entries {
+		slices.Sort(entry.pages)
+		index = append(index, *entry)
+	}
+	slices.SortFunc(index, func(a, b indexEntry) int {
+		return strings.Compare(strings.ToLower(a.name), strings.ToLower(b.name))
+	})
+	return index
+}
+
+func joinPages(pages []int) string {
+	refs := make([]string, len(pages))
+	for i, page := range pages {
+		refs[i] = strconv.Itoa(page)
+	}
+	return strings.Join(refs, ", ")
+}
+
+// recipe lays out a recipe card from the current position.
+func (d *pdfDocument) recipe(recipe domain.Recipe, scaling *Scaling) {
+	pdf := d.pdf
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.MultiCell(0, 9, d.text(recipe.Name), "", "L", false)
+	pdf.SetFont("Helvetica", "I", 11)
+	if yield := Yield(recipe); yield != "" {
+		pdf.MultiCell(0, lineHeight, d.text(yield), "", "L", false)
+	}
+	if scaling != nil {
+		pdf.MultiCell(0, lineHeight, d.text(scaling.String()), "", "L", false)
+	}
+
+	d.heading("Ingredients")
+	d.ingredients(recipe.Ingredients)
+	d.heading("Steps")
+	d.steps(recipe.Steps)
+}
+
+// title starts a part of a book, such as its contents.
+func (d *pdfDocument) title(s string) {
+	d.pdf.SetFont("Helvetica", "B", 20)
+	d.pdf.CellFormat(0, 12, d.text(s), "", 1, "L", false, 0, "")
+	d.pdf.Ln(4)
+}
+
+func (d *pdfDocument) heading(s string) {
+	d.pdf.Ln(4)
+	d.pdf.SetFont("Helvetica", "B", 14)
+	d.pdf.CellFormat(0, 8, d.text(s), "B", 1, "L", false, 0, "")
+	d.pdf.Ln(2)
+}
+
+// ingredients lays out a table of quantities and ingredient names, wrapping
+// long names over several lines of their row.
+func (d *pdfDocument) ingredients(ingredients []domain.Ingredient) {
+	pdf := d.pdf
+	if len(ingredients) == 0 {
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.CellFormat(0, lineHeight, "No ingredients.", "", 1, "L", false, 0, "")
+		return
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(225, 225, 225)
+	pdf.CellFormat(quantityWidth, rowHeight, "Quantity", "1", 0, "R", true, 0, "")
+	pdf.CellFormat(0, rowHeight, "Ingredient", "1", 1, "L", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetFillColor(245, 245, 245)
+	pageWidth, pageHeight := pdf.GetPageSize()
+	nameWidth := pageWidth - 2*pageMargin - quantityWidth
+	for i, ingredient := range ingredients {
+		name := d.text(ingredient.Name)
+		height := rowHeight * float64(d.lineCount(name, nameWidth))
+		if pdf.GetY()+height > pageHeight-pageMargin {
+			pdf.AddPage()
+		}
+		fill := i%2 == 1
+		pdf.CellFormat(quantityWidth, height, d.text(Amount(ingredient.Quantity, ingredient.Unit)), "1", 0, "R", fill, 0, "")
+		pdf.MultiCell(nameWidth, rowHeight, name, "1", "L", fill)
+	}
+}
+
+// steps lays out numbered steps, their illustrations under their
+// instructions.
+func (d *pdfDocument) steps(steps []domain.RecipeStep) {
+	pdf := d.pdf
+	if len(steps) == 0 {
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.CellFormat(0, lineHeight, "No steps.", "", 1, "L", false, 0, "")
+		return
+	}
+
+	left, _, _, _ := pdf.GetMargins()
+	for i, step := range steps {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(numberWidth, lineHeight, fmt.Sprintf("%d.", i+1), "", 0, "L", false, 0, "")
+		// Indent everything in the step, even across a page break, under its number.
+		pdf.SetLeftMargin(left + numberWidth)
+		if step.Name != "" {
+			pdf.MultiCell(0, lineHeight, d.text(step.Name), "", "L", false)
+		}
+		if instructions := strings.TrimSpace(step.Instructions); instructions != "" {
+			pdf.SetFont("Helvetica", "", 11)
+			pdf.MultiCell(0, lineHeight, d.text(instructions), "", "L", false)
+		}
+		for _, illustration := range step.RecipeIllustration {
+			d.illustration(illustration)
+		}
+		pdf.SetLeftMargin(left)
+		pdf.SetX(left)
+	}
+}
+
+// illustration places an image, scaled to fit maxImageWidth by
+// maxImageHeight, with its description as a caption.
+func (d *pdfDocument) illustration(illustration domain.RecipeIllustration) {
+	info := d.image(illustration.Filepath)
+	if info == nil {
+		return
+	}
+	pdf := d.pdf
+	width, height := info.Width(), info.Height()
+	ratio := min(maxImageWidth/width, maxImageHeight/height)
+	width, height = width*ratio, height*ratio
+
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+2+height+lineHeight > pageHeight-pageMargin {
+		pdf.AddPage()
+	} else {
+		pdf.Ln(2)
+	}
+	left, _, _, _ := pdf.GetMargins()
+	y := pdf.GetY()
+	pdf.ImageOptions(illustration.Filepath, left, y, width, height, false, fpdf.ImageOptions{}, 0, "")
+	pdf.SetY(y + height + 1)
+	if description := strings.TrimSpace(illustration.Description); description != "" {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.MultiCell(width, 5, d.text(description), "", "L", false)
+	}
+}
+
+// image registers the image at path in the document once, converted to JPEG
+// unless it already is one, and returns nil if it cannot be loaded.
+func (d *pdfDocument) image(path string) *fpdf.ImageInfoType {
+	if info, ok := d.loaded[path]; ok {
+		return info
+	}
+	d.loaded[path] = nil
+	if d.images == nil || path == "" {
+		return nil
+	}
+	data, err := d.images(path)
+	if err != nil {
+		return nil
+	}
+	if data, err = printable(data); err != nil {
+		return nil
+	}
+	info := d.pdf.RegisterImageOptionsReader(path, fpdf.ImageOptions{ImageType: "JPG"}, bytes.NewReader(data))
+	if d.pdf.Err() {
+		// An image the PDF library rejects is left out like a missing one.
+		d.pdf.ClearError()
+		return nil
+	}
+	d.loaded[path] = info
+	return info
+}
+
+// printable returns an image as a JPEG, the format PDF documents embed as is.
+// Transparent areas of other formats are turned white.
+func printable(data []byte) ([]byte, error) {
+	contentType, _, err := imaging.Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	if contentType == "image/jpeg" {
+		return data, nil
+	}
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+	return imaging.Encode(flat, "image/jpeg")
+}
+
+// leaderLine writes a line of a table of contents or an index: text on the
+// left, linked to a page if link is not 0, and ref on the right, joined by
+// dots.
+func (d *pdfDocument) leaderLine(text, ref string, link int) {
+	pdf := d.pdf
+	pageWidth, _ := pdf.GetPageSize()
+	width := pageWidth - 2*pageMargin - pageRefWidth
+	text = d.truncate(d.text(text), width-4)
+	dots := ""
+	if gap := width - pdf.GetStringWidth(text) - 2; gap > 0 {
+		dots = strings.Repeat(".", int(gap/pdf.GetStringWidth(".")))
+	}
+	pdf.CellFormat(width, rowHeight, text+" "+dots, "", 0, "L", false, link, "")
+	pdf.CellFormat(pageRefWidth, rowHeight, ref, "", 1, "R", false, link, "")
+}
+
+// text encodes s for the core fonts of the document.
+func (d *pdfDocument) text(s string) string {
+	return d.encode(pdfReplacer.Replace(s))
+}
+
+// truncate shortens encoded text to fit width, with an ellipsis.
+func (d *pdfDocument) truncate(s string, width float64) string {
+	if d.pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	ellipsis := d.text("…")
+	for len(s) > 0 && d.pdf.GetStringWidth(s+ellipsis) > width {
+		s = s[:len(s)-1]
+	}
+	return strings.TrimRight(s, " ") + ellipsis
+}
+
+// lineCount returns the number of lines MultiCell breaks encoded text into
+// at width.
+func (d *pdfDocument) lineCount(s string, width float64) int {
+	// SplitText reads UTF-8, while encoded text has a byte per character.
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+	return max(1, len(d.pdf.SplitText(string(runes), width)))
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+func testImages(t *testing.T) (Images, map[string]int) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	for x := 0; x < 40; x++ {
+		img.Set(x, x%30, color.NRGBA{R: 200, A: 128})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	loads := make(map[string]int)
+	return func(path string) ([]byte, error) {
+		loads[path]++
+		if path == "/images/1/step1.png" {
+			return buf.Bytes(), nil
+		}
+		return nil, errors.New("not found")
+	}, loads
+}
+
+func TestRecipeCard(t *testing.T) {
+	images, loads := testImages(t)
+	recipe := cake.Clone()
+	recipe.Steps[0].RecipeIllustration = []domain.RecipeIllustration{
+		{ID: "i1", Description: "The batter", Filepath: "/images/1/step1.png"},
+		{ID: "i2", Filepath: "/images/1/missing.jpg"},
+	}
+
+	var b bytes.Buffer
+	if err := RecipeCard(&b, recipe, &Scaling{Factor: 2}, images); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(b.Bytes(), []byte("%PDF-")) {
+		t.Errorf("expected a PDF document, got %q", b.Bytes()[:min(b.Len(), 16)])
+	}
+	if !bytes.Contains(b.Bytes(), []byte("/Subtype /Image")) {
+		t.Error("expected the illustration to be embedded")
+	}
+	if loads["/images/1/step1.png"] != 1 || loads["/images/1/missing.jpg"] != 1 {
+		t.Errorf("expected each illustration to be loaded once, got %v", loads)
+	}
+}
+
+func TestCookbook(t *testing.T) {
+	images, loads := testImages(t)
+	long := domain.Recipe{ID: "2", Name: "Apple pie"}
+	for i := 0; i < 60; i++ {
+		long.Steps = append(long.Steps, domain.RecipeStep{Instructions: strings.Repeat("Keep stirring. ", 10)})
+	}
+	long.Ingredients = []domain.Ingredient{{Name: "flour", Quantity: 250, Unit: "g"}, {Name: "Apples", Quantity: 4}}
+	recipe := cake.Clone()
+	recipe.Steps[0].RecipeIllustration = []domain.RecipeIllustration{{ID: "i1", Filepath: "/images/1/step1.png"}}
+	recipes := []domain.Recipe{long, recipe}
+
+	// The title page and the contents come first, and the long recipe
+	// takes several pages.
+	d := newPDFDocument("Family recipes", images)
+	pages := d.cookbook("Family recipes", recipes, make([]int, len(recipes)))
+	if pages[0] != 3 || pages[1] <= 4 {
+		t.Fatalf("unexpected pages %v", pages)
+	}
+
+	index := ingredientIndex(recipes, pages)
+	var names []string
+	for _, entry := range index {
+		names = append(names, entry.name)
+	}
+	if !slices.Equal(names, []string{"Apples", "Eggs", "flour", "Milk", "Salt"}) {
+		t.Errorf("unexpected index %v", names)
+	}
+	if !slices.Equal(index[2].pages, []int{pages[0], pages[1]}) {
+		t.Errorf("expected flour on pages %v, got %v", pages, index[2].pages)
+	}
+
+	var b bytes.Buffer
+	if err := Cookbook(&b, "Family recipes", recipes, images); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(b.Bytes(), []byte("%PDF-")) {
+		t.Error("expected a PDF document")
+	}
+	// Once by the layout above, once by the book for both of its passes.
+	if loads["/images/1/step1.png"] != 2 {
+		t.Errorf("expected the book to load the illustration once, got %d loads", loads["/images/1/step1.png"])
+	}
+}
+
+func TestPDFDocument_Text(t *testing.T) {
+	d := newPDFDocument("", nil)
+	if got := d.text("Crème brûlée, ⅓ cup"); got != "Cr\xe8me br\xfbl\xe9e, 1/3 cup" {
+		t.Errorf("unexpected encoding %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// metricUnits are written with decimals; other units, such as cups or
+// spoons, read better as fractions.
+var metricUnits = map[string]bool{
+	"mg": true, "g": true, "kg": true,
+	"ml": true, "cl": true, "dl": true, "l": true,
+}
+
+// fractions are the fractions cooks measure with, and their glyphs.
+var fractions = []struct {
+	value float64
+	glyph string
+}{
+	{1.0 / 4, "¼"},
+	{1.0 / 3, "⅓"},
+	{1.0 / 2, "½"},
+	{2.0 / 3, "⅔"},
+	{3.0 / 4, "¾"},
+}
+
+// Quantity formats a quantity of unit for cooks: without spurious decimals,
+// e.g. 300 rather than 300.00000001, and with fractions such as 1 ½ for
+// non-metric units. A zero quantity, as in "salt to taste", is empty.
+func Quantity(quantity float64, unit string) string {
+	if quantity <= 0 {
+		return ""
+	}
+	whole, fraction := math.Modf(quantity)
+	if !metricUnits[strings.ToLower(unit)] && quantity < 10 {
+		if fraction < 0.02 {
+			return strconv.FormatFloat(whole, 'f', 0, 64)
+		}
+		for _, f := range fractions {
+			if math.Abs(fraction-f.value) < 0.02 {
+				if whole == 0 {
+					return f.glyph
+				}
+				return strconv.FormatFloat(whole, 'f', 0, 64) + " " + f.glyph
+			}
+		}
+	}
+
+	// Keep fewer decimals the larger the quantity: 250, 12.5, 1.25.
+	decimals := 2
+	switch {
+	case quantity >= 100:
+		decimals = 0
+	case quantity >= 10:
+		decimals = 1
+	}
+	scale := math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Round(quantity*scale)/scale, 'f', -1, 64)
+}
+
+// Amount formats a quantity with its unit, e.g. "300 g", "1 ½ cups" or "2".
+func Amount(quantity float64, unit string) string {
+	formatted := Quantity(quantity, unit)
+	if formatted == "" || unit == "" {
+		return formatted
+	}
+	return formatted + " " + unit
+}
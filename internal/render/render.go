@@ -0,0 +1,148 @@
+// Package render writes recipes as Markdown or plain text, for sharing them
+// in chats and notes, and as PDF recipe cards and cookbooks for printing. It
+// is used by the HTTP API and by the CLI.
+package render
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// Format is a text representation of recipes.
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "text"
+)
+
+// ContentType returns the media type of documents in the format.
+func (f Format) ContentType() string {
+	if f == FormatMarkdown {
+		return "text/markdown; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// lineWidth is where plain text is wrapped.
+const lineWidth = 78
+
+//go:embed templates
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"amount":    Amount,
+	"yield":     Yield,
+	"number":    func(i int) int { return i + 1 },
+	"escape":    escapeMarkdown,
+	"indent":    indent,
+	"wrap":      wrap,
+	"underline": func(s string) string { return strings.Repeat("=", utf8.RuneCountInString(s)) },
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+// Scaling says how the quantities of a rendered recipe were scaled: so that
+// Ingredient reaches Quantity, or by Factor.
+type Scaling struct {
+	Ingredient string
+	Quantity   float64
+	Unit       string
+	Factor     float64
+}
+
+// ScaledTo describes a recipe scaled so that ingredient reaches quantity,
+// in the unit of the ingredient in recipe.
+func ScaledTo(recipe domain.Recipe, ingredient string, quantity float64) *Scaling {
+	scaling := &Scaling{Ingredient: ingredient, Quantity: quantity}
+	for _, i := range recipe.Ingredients {
+		if i.Name == ingredient {
+			scaling.Unit = i.Unit
+			break
+		}
+	}
+	return scaling
+}
+
+func (s Scaling) String() string {
+	if s.Ingredient != "" {
+		return fmt.Sprintf("Quantities scaled for %s of %s.", Amount(s.Quantity, s.Unit), s.Ingredient)
+	}
+	return fmt.Sprintf("Quantities scaled by %s.", strconv.FormatFloat(math.Round(s.Factor*100)/100, 'f', -1, 64))
+}
+
+// Yield tells how many people a recipe feeds, e.g. "Serves 4", or is empty
+// when the recipe does not say.
+func Yield(recipe domain.Recipe) string {
+	if recipe.Servings <= 0 {
+		return ""
+	}
+	return "Serves " + Quantity(recipe.Servings, "")
+}
+
+// Recipe writes recipe in format: its name, its ingredients with formatted
+// quantities and its numbered steps. A non-nil scaling is noted under the name.
+func Recipe(w io.Writer, format Format, recipe domain.Recipe, scaling *Scaling) error {
+	name := "recipe.txt.tmpl"
+	if format == FormatMarkdown {
+		name = "recipe.md.tmpl"
+	}
+	data := struct {
+		Recipe  domain.Recipe
+		Scaling *Scaling
+	}{recipe, scaling}
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		return fmt.Errorf("failed to render recipe %s as %s: %w", recipe.ID, format, err)
+	}
+	return nil
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, "#", `\#`,
+)
+
+// escapeMarkdown keeps names such as "Salt_and_pepper" from being read as
+// Markdown formatting.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// indent indents every line of s but the first by n spaces, so that it
+// continues the list item it starts in.
+func indent(n int, s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n"+strings.Repeat(" ", n))
+}
+
+// wrap breaks s into lines of at most lineWidth characters, indenting every
+// line but the first by n spaces. Existing line breaks are kept.
+func wrap(n int, s string) string {
+	prefix := strings.Repeat(" ", n)
+	var b strings.Builder
+	for i, paragraph := range strings.Split(strings.TrimSpace(s), "\n") {
+		if i > 0 {
+			b.WriteString("\n" + prefix)
+		}
+		width := n
+		for j, word := range strings.Fields(paragraph) {
+			length := utf8.RuneCountInString(word)
+			if j > 0 {
+				if width+1+length > lineWidth {
+					b.WriteString("\n" + prefix)
+					width = n
+				} else {
+					b.WriteString(" ")
+					width++
+				}
+			}
+			b.WriteString(word)
+			width += length
+		}
+	}
+	return b.String()
+}
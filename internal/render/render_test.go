@@ -0,0 +1,111 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+func TestQuantity(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		unit     string
+		want     string
+	}{
+		{0, "g", ""},
+		{300.0000001, "g", "300"},
+		{12.345, "g", "12.3"},
+		{1.255, "l", "1.25"},
+		{0.5, "g", "0.5"},
+		{1.5, "cups", "1 ½"},
+		{0.333, "cup", "⅓"},
+		{0.75, "", "¾"},
+		{2, "", "2"},
+		{2.2, "tbsp", "2.2"},
+		{12.5, "cups", "12.5"},
+	}
+	for _, tt := range tests {
+		if got := Quantity(tt.quantity, tt.unit); got != tt.want {
+			t.Errorf("Quantity(%v, %q) = %q, want %q", tt.quantity, tt.unit, got, tt.want)
+		}
+	}
+}
+
+var cake = domain.Recipe{
+	ID:       "1",
+	Name:     "Chocolate_Cake",
+	Servings: 8,
+	Ingredients: []domain.Ingredient{
+		{Name: "Flour", Quantity: 300, Unit: "g"},
+		{Name: "Milk", Quantity: 1.5, Unit: "cups"},
+		{Name: "Eggs", Quantity: 2},
+		{Name: "Salt"},
+	},
+	Steps: []domain.RecipeStep{
+		{Name: "Mix", Instructions: "Mix everything together in a large bowl until smooth and there are no lumps left.\nRest."},
+		{Instructions: "Bake for 30 minutes."},
+	},
+}
+
+func TestRecipe_Markdown(t *testing.T) {
+	var b strings.Builder
+	if err := Recipe(&b, FormatMarkdown, cake, &Scaling{Ingredient: "Flour", Quantity: 300, Unit: "g"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# Chocolate\_Cake
+
+Serves 8
+
+_Quantities scaled for 300 g of Flour._
+
+## Ingredients
+
+- **300 g** Flour
+- **1 ½ cups** Milk
+- **2** Eggs
+- Salt
+
+## Steps
+
+1. **Mix**
+
+   Mix everything together in a large bowl until smooth and there are no lumps left.
+   Rest.
+
+2. Bake for 30 minutes.
+`
+	if got := b.String(); got != want {
+		t.Errorf("unexpected Markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRecipe_Text(t *testing.T) {
+	var b strings.Builder
+	if err := Recipe(&b, FormatText, cake, &Scaling{Factor: 1.5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `Chocolate_Cake
+==============
+
+Serves 8
+
+Quantities scaled by 1.5.
+
+Ingredients
+- 300 g Flour
+- 1 ½ cups Milk
+- 2 Eggs
+- Salt
+
+Steps
+ 1. Mix
+    Mix everything together in a large bowl until smooth and there are no
+    lumps left.
+    Rest.
+ 2. Bake for 30 minutes.
+`
+	if got := b.String(); got != want {
+		t.Errorf("unexpected text:\n%s\nwant:\n%s", got, want)
+	}
+}
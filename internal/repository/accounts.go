@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUsernameTaken      = errors.New("username already taken")
+	ErrHouseholdNotFound  = errors.New("household not found")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrCollectionNotFound = errors.New("collection not found")
+)
+
+// AccountRepository stores users, households and sessions, who owns each
+// recipe and who may see it, the favorites and collections of each user, and
+// the reviews they write.
+// Every method returns ctx.Err() without doing any work once ctx is cancelled.
+type AccountRepository interface {
+	ReviewRepository
+
+	// CreateUser stores a new user, or returns ErrUsernameTaken.
+	CreateUser(ctx context.Context, user domain.User) error
+	// UpdateUser replaces a stored user, or returns ErrUserNotFound.
+	UpdateUser(ctx context.Context, user domain.User) error
+	FindUser(ctx context.Context, id string) (*domain.User, error)
+	FindUserByUsername(ctx context.Context, username string) (*domain.User, error)
+
+	CreateHousehold(ctx context.Context, household domain.Household) error
+	FindHousehold(ctx context.Context, id string) (*domain.Household, error)
+	// ListHouseholdMembers returns the users of a household, ordered by username.
+	ListHouseholdMembers(ctx context.Context, householdID string) ([]domain.User, error)
+
+	CreateSession(ctx context.Context, session domain.Session) error
+	// FindSession returns the session with the given token hash, or
+	// ErrSessionNotFound if there is none or it expired before now.
+	FindSession(ctx context.Context, tokenHash string, now time.Time) (*domain.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+
+	// RecipeAccess returns the owner and visibility of a recipe, or ErrNotFound
+	// for a recipe nobody owns.
+	RecipeAccess(ctx context.Context, recipeID string) (domain.RecipeAccess, error)
+	// ListRecipeAccess returns the access of every owned recipe by recipe ID.
+	ListRecipeAccess(ctx context.Context) (map[string]domain.RecipeAccess, error)
+	SaveRecipeAccess(ctx context.Context, access domain.RecipeAccess) error
+
+	// ListFavorites returns the IDs of the favorite recipes of a user, in the
+	// order they were added.
+	ListFavorites(ctx context.Context, userID string) ([]string, error)
+	// SetFavorite adds a recipe to, or removes it from, the favorites of a user.
+	SetFavorite(ctx context.Context, userID, recipeID string, favorite bool) error
+
+	// ListCollections returns the collections of a user, ordered by name.
+	ListCollections(ctx context.Context, userID string) ([]domain.Collection, error)
+	// FindCollection returns a collection of a user, or ErrCollectionNotFound.
+	FindCollection(ctx context.Context, userID, id string) (*domain.Collection, error)
+	// SaveCollection creates or replaces a collection.
+	SaveCollection(ctx context.Context, collection domain.Collection) error
+	DeleteCollection(ctx context.Context, userID, id string) error
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// testAccountRepository runs the same checks against every implementation.
+func testAccountRepository(t *testing.T, open func(t *testing.T) AccountRepository) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("users and households", func(t *testing.T) {
+		repo := open(t)
+		alice := domain.User{ID: "u1", Username: "alice", PasswordHash: "hash", CreatedAt: now}
+		if err := repo.CreateUser(ctx, alice); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.CreateUser(ctx, domain.User{ID: "u2", Username: "alice", CreatedAt: now}); !errors.Is(err, ErrUsernameTaken) {
+			t.Errorf("expected ErrUsernameTaken, got %v", err)
+		}
+
+		found, err := repo.FindUserByUsername(ctx, "alice")
+		if err != nil || !reflect.DeepEqual(*found, alice) {
+			t.Errorf("expected %+v, got %+v, %v", alice, found, err)
+		}
+		if _, err := repo.FindUser(ctx, "missing"); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+
+		household := domain.Household{ID: "h1", Name: "Home", CreatedAt: now}
+		if err := repo.CreateHousehold(ctx, household); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		alice.HouseholdID = "h1"
+		if err := repo.UpdateUser(ctx, alice); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		members, err := repo.ListHouseholdMembers(ctx, "h1")
+		if err != nil || len(members) != 1 || members[0].ID != "u1" {
+			t.Errorf("expected alice in the household, got %+v, %v", members, err)
+		}
+		if _, err := repo.ListHouseholdMembers(ctx, "missing"); !errors.Is(err, ErrHouseholdNotFound) {
+			t.Errorf("expected ErrHouseholdNotFound, got %v", err)
+		}
+		if err := repo.UpdateUser(ctx, domain.User{ID: "missing", Username: "bob"}); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		repo := open(t)
+		session := domain.Session{TokenHash: "t1", UserID: "u1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+		if err := repo.CreateSession(ctx, session); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		found, err := repo.FindSession(ctx, "t1", now)
+		if err != nil || found.UserID != "u1" || !found.ExpiresAt.Equal(session.ExpiresAt) {
+			t.Errorf("expected the session, got %+v, %v", found, err)
+		}
+		if _, err := repo.FindSession(ctx, "t1", now.Add(2*time.Hour)); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("expected an expired session to be missing, got %v", err)
+		}
+		if err := repo.DeleteSession(ctx, "t1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.FindSession(ctx, "t1", now); !errors.Is(err, ErrSessionNotFound) {
+			t.Errorf("expected a deleted session to be missing, got %v", err)
+		}
+	})
+
+	t.Run("recipe access", func(t *testing.T) {
+		repo := open(t)
+		if _, err := repo.RecipeAccess(ctx, "1"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unowned recipe, got %v", err)
+		}
+		owner := domain.User{ID: "u1", Username: "alice", HouseholdID: "h1", CreatedAt: now}
+		if err := repo.CreateUser(ctx, owner); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The household is the current one of the owner, not the one saved.
+		access := domain.RecipeAccess{RecipeID: "1", OwnerID: "u1", HouseholdID: "stale", Visibility: domain.VisibilityPrivate, UpdatedAt: now}
+		if err := repo.SaveRecipeAccess(ctx, access); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		access.Visibility, access.HouseholdID = domain.VisibilityPublic, "h1"
+		if err := repo.SaveRecipeAccess(ctx, access); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		all, err := repo.ListRecipeAccess(ctx)
+		if err != nil || len(all) != 1 || !reflect.DeepEqual(all["1"], access) {
+			t.Errorf("expected %+v, got %+v, %v", access, all, err)
+		}
+
+		owner.HouseholdID = "h2"
+		if err := repo.UpdateUser(ctx, owner); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if found, err := repo.RecipeAccess(ctx, "1"); err != nil || found.HouseholdID != "h2" {
+			t.Errorf("expected the recipe to follow its owner to h2, got %+v, %v", found, err)
+		}
+		if err := repo.SaveRecipeAccess(ctx, domain.RecipeAccess{RecipeID: "2", OwnerID: "gone", HouseholdID: "h2", Visibility: domain.VisibilityHousehold, UpdatedAt: now}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if found, err := repo.RecipeAccess(ctx, "2"); err != nil || found.HouseholdID != "" {
+			t.Errorf("expected no household for an owner without account, got %+v, %v", found, err)
+		}
+	})
+
+	t.Run("favorites", func(t *testing.T) {
+		repo := open(t)
+		for _, id := range []string{"3", "1", "2"} {
+			if err := repo.SetFavorite(ctx, "u1", id, true); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := repo.SetFavorite(ctx, "u1", "1", false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		favorites, err := repo.ListFavorites(ctx, "u1")
+		if err != nil || !reflect.DeepEqual(favorites, []string{"3", "2"}) {
+			t.Errorf("expected [3 2], got %v, %v", favorites, err)
+		}
+		if favorites, _ := repo.ListFavorites(ctx, "u2"); len(favorites) != 0 {
+			t.Errorf("expected no favorites for another user, got %v", favorites)
+		}
+	})
+
+	t.Run("collections", func(t *testing.T) {
+		repo := open(t)
+		collection := domain.Collection{ID: "c1", UserID: "u1", Name: "Weeknight", RecipeIDs: []string{"1", "2"}, CreatedAt: now, UpdatedAt: now}
+		if err := repo.SaveCollection(ctx, collection); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.SaveCollection(ctx, domain.Collection{ID: "c2", UserID: "u1", Name: "Baking", RecipeIDs: []string{}, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		collections, err := repo.ListCollections(ctx, "u1")
+		if err != nil || len(collections) != 2 || collections[0].Name != "Baking" {
+			t.Errorf("expected two collections ordered by name, got %+v, %v", collections, err)
+		}
+		found, err := repo.FindCollection(ctx, "u1", "c1")
+		if err != nil || !reflect.DeepEqual(*found, collection) {
+			t.Errorf("expected %+v, got %+v, %v", collection, found, err)
+		}
+		if _, err := repo.FindCollection(ctx, "u2", "c1"); !errors.Is(err, ErrCollectionNotFound) {
+			t.Errorf("expected collections of other users to be hidden, got %v", err)
+		}
+		if err := repo.SaveCollection(ctx, domain.Collection{ID: "c1", UserID: "u2", Name: "Stolen", CreatedAt: now, UpdatedAt: now}); !errors.Is(err, ErrCollectionNotFound) {
+			t.Errorf("expected collections of other users to be read-only, got %v", err)
+		}
+		if err := repo.DeleteCollection(ctx, "u1", "c1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.DeleteCollection(ctx, "u1", "c1"); !errors.Is(err, ErrCollectionNotFound) {
+			t.Errorf("expected ErrCollectionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("reviews", func(t *testing.T) {
+		repo := open(t)
+		reviews := []domain.Review{
+			{ID: "r1", RecipeID: "1", Author: "alice", Rating: 5, CookedOn: "2024-05-07", CreatedAt: now, UpdatedAt: now},
+			{ID: "r2", RecipeID: "1", Author: "bob", Rating: 2, Notes: "too salty", CreatedAt: now.Add(time.Hour), UpdatedAt: now.Add(time.Hour)},
+			{ID: "r3", RecipeID: "1", Author: "alice", CookedOn: "2024-06-01", Adjustments: []string{"5 more minutes"}, CreatedAt: now.Add(time.Millisecond), UpdatedAt: now},
+			{ID: "r4", RecipeID: "2", Author: "carol", Rating: 4, CreatedAt: now, UpdatedAt: now},
+		}
+		for _, review := range reviews {
+			if err := repo.SaveReview(ctx, review); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		listed, err := repo.ListReviews(ctx, "1")
+		if err != nil || len(listed) != 3 || listed[0].ID != "r2" || listed[1].ID != "r3" {
+			t.Errorf("expected the reviews of recipe 1 newest first, got %+v, %v", listed, err)
+		}
+		found, err := repo.FindReview(ctx, "1", "r3")
+		if err != nil || !reflect.DeepEqual(*found, reviews[2]) {
+			t.Errorf("expected %+v, got %+v, %v", reviews[2], found, err)
+		}
+		if _, err := repo.FindReview(ctx, "2", "r3"); !errors.Is(err, ErrReviewNotFound) {
+			t.Errorf("expected reviews of other recipes to be missing, got %v", err)
+		}
+
+		stats, err := repo.ReviewStats(ctx)
+		want := map[string]domain.RecipeStats{
+			"1": {AverageRating: 3.5, RatingCount: 2, TimesCooked: 2, LastCooked: "2024-06-01", UpdatedAt: now.Add(time.Hour)},
+			"2": {AverageRating: 4, RatingCount: 1, UpdatedAt: now},
+		}
+		if err != nil || !reflect.DeepEqual(stats, want) {
+			t.Errorf("expected %+v, got %+v, %v", want, stats, err)
+		}
+
+		if err := repo.DeleteReview(ctx, "1", "r1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.DeleteReview(ctx, "1", "r1"); !errors.Is(err, ErrReviewNotFound) {
+			t.Errorf("expected ErrReviewNotFound, got %v", err)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		repo := open(t)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := repo.FindUser(cancelled, "u1"); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if err := repo.SetFavorite(cancelled, "u1", "1", true); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestJSONAccountRepository(t *testing.T) {
+	testAccountRepository(t, func(t *testing.T) AccountRepository {
+		repo, err := NewJSONAccountRepository(filepath.Join(t.TempDir(), "accounts", "accounts.json"))
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		return repo
+	})
+}
+
+func TestJSONAccountRepository_Persists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	repo, err := NewJSONAccountRepository(path)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	if err := repo.CreateUser(ctx, domain.User{ID: "u1", Username: "alice", PasswordHash: "hash"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewJSONAccountRepository(path)
+	if err != nil {
+		t.Fatalf("failed to reopen repository: %v", err)
+	}
+	user, err := reopened.FindUser(ctx, "u1")
+	if err != nil || user.PasswordHash != "hash" {
+		t.Errorf("expected the user and its password hash to be stored, got %+v, %v", user, err)
+	}
+}
+
+func TestSQLiteAccountRepository(t *testing.T) {
+	testAccountRepository(t, func(t *testing.T) AccountRepository {
+		repo, err := NewSQLiteAccountRepository(filepath.Join(t.TempDir(), "accounts.db"))
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		return repo
+	})
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is a compiled .gitignore-style pattern.
+type ignorePattern struct {
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// ignoreRules decide which paths of the recipes directory are skipped. As in
+// .gitignore, the last matching pattern wins and a pattern starting with "!"
+// includes again what an earlier one excluded, except below an excluded
+// directory.
+type ignoreRules []ignorePattern
+
+// parseIgnore compiles .gitignore-style patterns. Blank lines and lines
+// starting with "#" are skipped. A pattern ending with "/" only matches
+// directories. A pattern without any other "/" matches a name at any depth,
+// otherwise it matches a path relative to the recipes directory. "*" and "?"
+// do not match "/", while "**" matches any number of directories.
+func parseIgnore(lines []string) (ignoreRules, error) {
+	var rules ignoreRules
+	for _, line := range lines {
+		pattern := strings.TrimSpace(line)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		var rule ignorePattern
+		if rule.negate = strings.HasPrefix(pattern, "!"); rule.negate {
+			pattern = pattern[1:]
+		}
+		if rule.dirOnly = strings.HasSuffix(pattern, "/"); rule.dirOnly {
+			pattern = strings.TrimRight(pattern, "/")
+		}
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("invalid ignore pattern %q", line)
+		}
+
+		expr := globToRegexp(pattern)
+		if !anchored {
+			expr = "(?:.*/)?" + expr
+		}
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", line, err)
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// globToRegexp translates a glob to a regular expression matching slash
+// separated paths.
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
+
+// ignored reports whether a path, relative to the recipes directory and
+// separated by "/", is skipped.
+func (rules ignoreRules) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,51 @@
+package repository
+
+import "testing"
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := parseIgnore([]string{
+		"# drafts are not published",
+		"drafts/",
+		"*.tmp.json",
+		"/archive/*.json",
+		"!/archive/keep.json",
+		"**/old/**",
+		"test?.json",
+		"[ab]c.json",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"drafts", true, true},
+		{"desserts/drafts", true, true},
+		{"drafts", false, false},
+		{"cake.tmp.json", false, true},
+		{"desserts/cake.tmp.json", false, true},
+		{"archive/cake.json", false, true},
+		{"archive/keep.json", false, false},
+		{"desserts/archive/cake.json", false, false},
+		{"archive/2020/cake.json", false, false},
+		{"old/cake.json", false, true},
+		{"desserts/old/cakes/cake.json", false, true},
+		{"test1.json", false, true},
+		{"test12.json", false, false},
+		{"ac.json", false, true},
+		{"cc.json", false, false},
+		{"cake.json", false, false},
+	}
+	for _, tt := range tests {
+		if got := rules.ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	if _, err := parseIgnore([]string{"/"}); err == nil {
+		t.Error("expected an empty pattern to be refused")
+	}
+}
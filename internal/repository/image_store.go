@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrImageNotFound = errors.New("image not found")
+	ErrInvalidName   = errors.New("invalid image name")
+)
+
+// ImageFile is an open stored image, ready to be served.
+type ImageFile struct {
+	io.ReadSeekCloser
+	Size    int64
+	ModTime time.Time
+}
+
+// ImageStore keeps illustration files. Names are slash-separated paths relative
+// to the root of the store, e.g. "chocolate_cake/step1.jpg".
+type ImageStore interface {
+	Save(ctx context.Context, name string, data []byte) error
+	Open(ctx context.Context, name string) (*ImageFile, error)
+	// Delete removes an image. Deleting a missing image is not an error.
+	Delete(ctx context.Context, name string) error
+}
+
+type localImageStore struct {
+	dirPath string
+}
+
+// NewLocalImageStore creates an image store backed by a local directory. The
+// directory is created on the first upload if it does not exist yet.
+func NewLocalImageStore(dirPath string) (ImageStore, error) {
+	info, err := os.Stat(dirPath)
+	if err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("path %s is not a directory", dirPath)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open image directory %s: %w", dirPath, err)
+	}
+	return &localImageStore{dirPath: dirPath}, nil
+}
+
+// resolve maps an image name to a file path, refusing names that would escape
+// the store directory.
+func (s *localImageStore) resolve(name string) (string, error) {
+	local := filepath.FromSlash(name)
+	if name == "" || !filepath.IsLocal(local) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return filepath.Join(s.dirPath, local), nil
+}
+
+// Save writes an image, replacing any existing file with the same name.
+func (s *localImageStore) Save(ctx context.Context, name string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for image %s: %w", name, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for image %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write image %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write image %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store image %s: %w", name, err)
+	}
+	return nil
+}
+
+// Open returns a stored image for reading.
+func (s *localImageStore) Open(ctx context.Context, name string) (*ImageFile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image %s: %w", name, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat image %s: %w", name, err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrImageNotFound
+	}
+	return &ImageFile{ReadSeekCloser: file, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Delete removes a stored image.
+func (s *localImageStore) Delete(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete image %s: %w", name, err)
+	}
+	return nil
+}
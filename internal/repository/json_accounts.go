@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// userRecord is a user as stored, including the password hash that
+// domain.User never encodes.
+type userRecord struct {
+	domain.User
+	PasswordHash string `json:"password_hash"`
+}
+
+// accountsState is the content of the accounts file. Maps are keyed by ID
+// (by token hash for sessions, by user ID for favorites).
+type accountsState struct {
+	Users        map[string]userRecord          `json:"users"`
+	Households   map[string]domain.Household    `json:"households"`
+	Sessions     map[string]domain.Session      `json:"sessions"`
+	RecipeAccess map[string]domain.RecipeAccess `json:"recipe_access"`
+	Favorites    map[string][]string            `json:"favorites"`
+	Collections  map[string]domain.Collection   `json:"collections"`
+	Reviews      map[string]domain.Review       `json:"reviews"`
+}
+
+type jsonAccountRepository struct {
+	path string
+
+	mu    sync.RWMutex
+	state accountsState
+}
+
+// NewJSONAccountRepository creates an account repository kept in a single
+// JSON file, which is created on the first change if it does not exist.
+func NewJSONAccountRepository(path string) (AccountRepository, error) {
+	repo := &jsonAccountRepository{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read accounts file %s: %w", path, err)
+	}
+	if err := repo.decode(data); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts file %s: %w", path, err)
+	}
+	return repo, nil
+}
+
+// decode replaces the state with the given file content; empty content
+// gives an empty state.
+func (r *jsonAccountRepository) decode(data []byte) error {
+	var state accountsState
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return err
+		}
+	}
+	if state.Users == nil {
+		state.Users = make(map[string]userRecord)
+	}
+	if state.Households == nil {
+		state.Households = make(map[string]domain.Household)
+	}
+	if state.Sessions == nil {
+		state.Sessions = make(map[string]domain.Session)
+	}
+	if state.RecipeAccess == nil {
+		state.RecipeAccess = make(map[string]domain.RecipeAccess)
+	}
+	if state.Favorites == nil {
+		state.Favorites = make(map[string][]string)
+	}
+	if state.Collections == nil {
+		state.Collections = make(map[string]domain.Collection)
+	}
+	if state.Reviews == nil {
+		state.Reviews = make(map[string]domain.Review)
+	}
+	r.state = state
+	return nil
+}
+
+// update applies change to the state and writes the file. If change or the
+// write fails, the state is restored so that memory and disk agree.
+func (r *jsonAccountRepository) update(ctx context.Context, change func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backup, err := json.Marshal(r.state)
+	if err != nil {
+		return fmt.Errorf("failed to encode accounts: %w", err)
+	}
+	restore := func() { _ = r.decode(backup) }
+
+	if err := change(); err != nil {
+		restore()
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		restore()
+		return fmt.Errorf("failed to create directory for accounts file %s: %w", r.path, err)
+	}
+	if err := writeJSONFile(r.path, r.state); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// read runs view with the state locked for reading.
+func (r *jsonAccountRepository) read(ctx context.Context, view func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return view()
+}
+
+func (r *jsonAccountRepository) CreateUser(ctx context.Context, user domain.User) error {
+	return r.update(ctx, func() error {
+		for _, existing := range r.state.Users {
+			if existing.Username == user.Username {
+				return ErrUsernameTaken
+			}
+		}
+		r.state.Users[user.ID] = userRecord{User: user, PasswordHash: user.PasswordHash}
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) UpdateUser(ctx context.Context, user domain.User) error {
+	return r.update(ctx, func() error {
+		if _, ok := r.state.Users[user.ID]; !ok {
+			return ErrUserNotFound
+		}
+		for id, existing := range r.state.Users {
+			if id != user.ID && existing.Username == user.Username {
+				return ErrUsernameTaken
+			}
+		}
+		r.state.Users[user.ID] = userRecord{User: user, PasswordHash: user.PasswordHash}
+		return nil
+	})
+}
+
+func (record userRecord) user() *domain.User {
+	user := record.User
+	user.PasswordHash = record.PasswordHash
+	return &user
+}
+
+func (r *jsonAccountRepository) FindUser(ctx context.Context, id string) (user *domain.User, err error) {
+	err = r.read(ctx, func() error {
+		record, ok := r.state.Users[id]
+		if !ok {
+			return ErrUserNotFound
+		}
+		user = record.user()
+		return nil
+	})
+	return user, err
+}
+
+func (r *jsonAccountRepository) FindUserByUsername(ctx context.Context, username string) (user *domain.User, err error) {
+	err = r.read(ctx, func() error {
+		for _, record := range r.state.Users {
+			if record.Username == username {
+				user = record.user()
+				return nil
+			}
+		}
+		return ErrUserNotFound
+	})
+	return user, err
+}
+
+func (r *jsonAccountRepository) CreateHousehold(ctx context.Context, household domain.Household) error {
+	return r.update(ctx, func() error {
+		r.state.Households[household.ID] = household
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) FindHousehold(ctx context.Context, id string) (household *domain.Household, err error) {
+	err = r.read(ctx, func() error {
+		found, ok := r.state.Households[id]
+		if !ok {
+			return ErrHouseholdNotFound
+		}
+		household = &found
+		return nil
+	})
+	return household, err
+}
+
+func (r *jsonAccountRepository) ListHouseholdMembers(ctx context.Context, householdID string) (members []domain.User, err error) {
+	err = r.read(ctx, func() error {
+		if _, ok := r.state.Households[householdID]; !ok {
+			return ErrHouseholdNotFound
+		}
+		members = []domain.User{}
+		for _, record := range r.state.Users {
+			if record.HouseholdID == householdID {
+				members = append(members, *record.user())
+			}
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Username < members[j].Username })
+		return nil
+	})
+	return members, err
+}
+
+func (r *jsonAccountRepository) CreateSession(ctx context.Context, session domain.Session) error {
+	return r.update(ctx, func() error {
+		// Drop expired sessions, so that the file does not grow forever.
+		for hash, existing := range r.state.Sessions {
+			if !existing.ExpiresAt.After(session.CreatedAt) {
+				delete(r.state.Sessions, hash)
+			}
+		}
+		r.state.Sessions[session.TokenHash] = session
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) FindSession(ctx context.Context, tokenHash string, now time.Time) (session *domain.Session, err error) {
+	err = r.read(ctx, func() error {
+		found, ok := r.state.Sessions[tokenHash]
+		if !ok || !found.ExpiresAt.After(now) {
+			return ErrSessionNotFound
+		}
+		session = &found
+		return nil
+	})
+	return session, err
+}
+
+func (r *jsonAccountRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	return r.update(ctx, func() error {
+		if _, ok := r.state.Sessions[tokenHash]; !ok {
+			return ErrSessionNotFound
+		}
+		delete(r.state.Sessions, tokenHash)
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) RecipeAccess(ctx context.Context, recipeID string) (access domain.RecipeAccess, err error) {
+	err = r.read(ctx, func() error {
+		found, ok := r.state.RecipeAccess[recipeID]
+		if !ok {
+			return ErrNotFound
+		}
+		access = r.withHousehold(found)
+		return nil
+	})
+	return access, err
+}
+
+func (r *jsonAccountRepository) ListRecipeAccess(ctx context.Context) (accesses map[string]domain.RecipeAccess, err error) {
+	err = r.read(ctx, func() error {
+		accesses = make(map[string]domain.RecipeAccess, len(r.state.RecipeAccess))
+		for id, access := range r.state.RecipeAccess {
+			accesses[id] = r.withHousehold(access)
+		}
+		return nil
+	})
+	return accesses, err
+}
+
+// withHousehold sets the household of access to the current household of its
+// owner. The caller must hold the lock.
+func (r *jsonAccountRepository) withHousehold(access domain.RecipeAccess) domain.RecipeAccess {
+	access.HouseholdID = r.state.Users[access.OwnerID].HouseholdID
+	return access
+}
+
+func (r *jsonAccountRepository) SaveRecipeAccess(ctx context.Context, access domain.RecipeAccess) error {
+	return r.update(ctx, func() error {
+		access.HouseholdID = ""
+		r.state.RecipeAccess[access.RecipeID] = access
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) ListFavorites(ctx context.Context, userID string) (recipeIDs []string, err error) {
+	err = r.read(ctx, func() error {
+		recipeIDs = append([]string{}, r.state.Favorites[userID]...)
+		return nil
+	})
+	return recipeIDs, err
+}
+
+func (r *jsonAccountRepository) SetFavorite(ctx context.Context, userID, recipeID string, favorite bool) error {
+	return r.update(ctx, func() error {
+		favorites := slices.DeleteFunc(r.state.Favorites[userID], func(id string) bool { return id == recipeID })
+		if favorite {
+			favorites = append(favorites, recipeID)
+		}
+		if len(favorites) == 0 {
+			delete(r.state.Favorites, userID)
+		} else {
+			r.state.Favorites[userID] = favorites
+		}
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) ListCollections(ctx context.Context, userID string) (collections []domain.Collection, err error) {
+	err = r.read(ctx, func() error {
+		collections = []domain.Collection{}
+		for _, collection := range r.state.Collections {
+			if collection.UserID == userID {
+				collection.RecipeIDs = append([]string{}, collection.RecipeIDs...)
+				collections = append(collections, collection)
+			}
+		}
+		sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+		return nil
+	})
+	return collections, err
+}
+
+func (r *jsonAccountRepository) FindCollection(ctx context.Context, userID, id string) (collection *domain.Collection, err error) {
+	err = r.read(ctx, func() error {
+		found, ok := r.state.Collections[id]
+		if !ok || found.UserID != userID {
+			return ErrCollectionNotFound
+		}
+		found.RecipeIDs = append([]string{}, found.RecipeIDs...)
+		collection = &found
+		return nil
+	})
+	return collection, err
+}
+
+func (r *jsonAccountRepository) SaveCollection(ctx context.Context, collection domain.Collection) error {
+	return r.update(ctx, func() error {
+		if existing, ok := r.state.Collections[collection.ID]; ok && existing.UserID != collection.UserID {
+			return ErrCollectionNotFound
+		}
+		collection.RecipeIDs = append([]string{}, collection.RecipeIDs...)
+		r.state.Collections[collection.ID] = collection
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) DeleteCollection(ctx context.Context, userID, id string) error {
+	return r.update(ctx, func() error {
+		if existing, ok := r.state.Collections[id]; !ok || existing.UserID != userID {
+			return ErrCollectionNotFound
+		}
+		delete(r.state.Collections, id)
+		return nil
+	})
+}
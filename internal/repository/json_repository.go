@@ -1,39 +1,102 @@
 package repository
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"log/slog"
 	"os"
-	"path/filepath"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/fromenjn/recipe-manager/internal/domain"
 )
 
 type jsonRepository struct {
-	dirPath string
-	recipes map[string]domain.Recipe
+	dirPath   string
+	validator domain.RecipeValidator
+	strict    bool
+	layout    Layout
+
+	mu       sync.RWMutex
+	recipes  map[string]domain.Recipe
+	versions map[string]Version
+	paths    map[string]string // recipe ID -> file the recipe is stored in
+	slugs    map[string]string // slug -> recipe ID
+	aliases  map[string]string // previous slug -> recipe ID
+	report   ValidationReport
+}
+
+// Option configures a JSON repository.
+type Option func(*jsonRepository)
+
+// WithValidator checks every recipe with validator when it is loaded or saved.
+// In strict mode an invalid or unreadable file aborts loading; in lenient mode
+// the file is skipped and listed in the validation report.
+func WithValidator(validator domain.RecipeValidator, strict bool) Option {
+	return func(r *jsonRepository) {
+		r.validator = validator
+		r.strict = strict
+	}
 }
 
-// NewJSONRepository creates a new repository that reads from all JSON files in a directory.
-func NewJSONRepository(dirPath string) (RecipeRepository, error) {
+// NewJSONRepository creates a new repository that reads from the JSON files in a directory.
+// Without options, recipes are not validated and any broken file or duplicate ID fails loading.
+func NewJSONRepository(dirPath string, opts ...Option) (RecipeRepository, error) {
 	repo := &jsonRepository{
-		dirPath: dirPath,
-		recipes: make(map[string]domain.Recipe),
+		dirPath:  dirPath,
+		strict:   true,
+		recipes:  make(map[string]domain.Recipe),
+		versions: make(map[string]Version),
+		paths:    make(map[string]string),
+		slugs:    make(map[string]string),
+		aliases:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(repo)
 	}
 
-	if err := repo.loadRecipes(); err != nil {
+	if err := repo.loadRecipes(context.Background()); err != nil {
 		return nil, err
 	}
 
 	return repo, nil
 }
 
-// loadRecipes reads all .json files in dirPath, accumulates them in a map by ID.
-func (r *jsonRepository) loadRecipes() error {
+// Reload reads the directory into fresh maps and swaps them in only when
+// loading succeeds, so readers never see a partially loaded collection.
+func (r *jsonRepository) Reload(ctx context.Context) error {
+	fresh := &jsonRepository{
+		dirPath:   r.dirPath,
+		validator: r.validator,
+		strict:    r.strict,
+		layout:    r.layout,
+		recipes:   make(map[string]domain.Recipe),
+		versions:  make(map[string]Version),
+		paths:     make(map[string]string),
+		slugs:     make(map[string]string),
+		aliases:   make(map[string]string),
+	}
+	if err := fresh.loadRecipes(ctx); err != nil {
+		return fmt.Errorf("failed to reload recipes: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recipes, r.versions, r.paths, r.report = fresh.recipes, fresh.versions, fresh.paths, fresh.report
+	r.slugs, r.aliases = fresh.slugs, fresh.aliases
+	slog.Info(fmt.Sprintf("Reloaded %d recipes from %s", len(r.recipes), r.dirPath))
+	return nil
+}
+
+// loadRecipes reads the .json files the layout includes, accumulates them in a map by ID.
+// It stops at the next file once ctx is cancelled.
+func (r *jsonRepository) loadRecipes(ctx context.Context) error {
 	// Verify the directory exists
 	info, err := os.Stat(r.dirPath)
 	if err != nil {
@@ -43,71 +106,199 @@ func (r *jsonRepository) loadRecipes() error {
 		return fmt.Errorf("path %s is not a directory", r.dirPath)
 	}
 
-	// Walk the directory for .json files
-	err = filepath.Walk(r.dirPath, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// If it's a directory, skip it (except the root)
-		if info.IsDir() && path != r.dirPath {
-			return filepath.SkipDir
-		}
+	r.report = ValidationReport{Strict: r.strict, Files: []FileReport{}}
 
-		// Only parse .json files
-		if filepath.Ext(path) == ".json" {
-			if loadErr := r.parseFile(path); loadErr != nil {
+	err = walkRecipeFiles(ctx, r.dirPath, r.layout, func(path string) error {
+		if fileReport := r.parseFile(path); fileReport != nil {
+			if r.strict {
 				// Return an error so we fail fast on a broken file
-				return loadErr
-			} else {
-				slog.Debug(fmt.Sprintf("Loaded recipe from file %s", path))
+				return fileReport
 			}
+			fileReport.Skipped = true
+			r.report.Files = append(r.report.Files, *fileReport)
+			slog.Warn(fmt.Sprintf("Skipped %v", fileReport))
+			return nil
 		}
+		slog.Debug(fmt.Sprintf("Loaded recipe from file %s", path))
 		return nil
 	})
 
 	if err != nil {
 		return fmt.Errorf("failed walking directory: %w", err)
 	}
+	if err := r.deriveSlugs(); err != nil {
+		return err
+	}
 
+	r.report.CheckedAt = time.Now().UTC()
+	r.report.Loaded = len(r.recipes)
 	return nil
 }
 
 // parseFile reads a single JSON file and accumulates recipe data into the repository map.
-func (r *jsonRepository) parseFile(path string) error {
+// It returns a report describing why the file could not be loaded, or nil on success.
+func (r *jsonRepository) parseFile(path string) *FileReport {
+	fail := func(fieldPath, format string, args ...any) *FileReport {
+		return &FileReport{
+			Path:   path,
+			Errors: []domain.FieldError{{Path: fieldPath, Message: fmt.Sprintf(format, args...)}},
+		}
+	}
+
 	file, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("failed to open file %s: %w", path, err)
+		return fail("", "failed to open file: %v", err)
 	}
 	defer file.Close()
 
+	info, err := file.Stat()
+	if err != nil {
+		return fail("", "failed to stat file: %v", err)
+	}
+
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return fmt.Errorf("failed to read file %s: %w", path, err)
+		return fail("", "failed to read file: %v", err)
 	}
 
-	var fileRecipe domain.Recipe
-	if err := json.Unmarshal(data, &fileRecipe); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON in file %s: %w", path, err)
+	fileRecipe, schemaVersion, err := decodeRecipeFile(data)
+	if errors.Is(err, ErrUnsupportedSchema) {
+		return fail(schemaVersionKey, "%v", err)
+	}
+	if err != nil {
+		return fail("", "failed to unmarshal JSON: %v", err)
+	}
+	if r.validator != nil {
+		if errs := r.validator.Validate(fileRecipe); len(errs) > 0 {
+			return &FileReport{Path: path, RecipeID: fileRecipe.ID, Errors: errs}
+		}
+	}
+	fileRecipe.ID = r.layout.namespace(r.dirPath, path) + fileRecipe.ID
+	if other, ok := r.paths[fileRecipe.ID]; ok {
+		report := fail("id", "recipe ID %q is used by both %s and %s", fileRecipe.ID, other, path)
+		report.RecipeID = fileRecipe.ID
+		return report
+	}
+	fileRecipe.Slug = domain.Slugify(fileRecipe.Slug)
+	if other, ok := r.slugs[fileRecipe.Slug]; ok {
+		report := fail("slug", "slug %q is used by both %s and %s", fileRecipe.Slug, r.paths[other], path)
+		report.RecipeID = fileRecipe.ID
+		return report
+	}
+	if category := r.layout.category(r.dirPath, path); category != "" {
+		fileRecipe.Category = category
+	}
+	etag, err := contentHash(fileRecipe)
+	if err != nil {
+		return fail("", "failed to hash recipe: %v", err)
 	}
 	r.recipes[fileRecipe.ID] = fileRecipe
+	r.versions[fileRecipe.ID] = Version{ETag: etag, LastModified: info.ModTime()}
+	r.paths[fileRecipe.ID] = path
+	r.indexSlugs(fileRecipe)
+	if schemaVersion < SchemaVersion {
+		r.report.Outdated++
+	}
 	return nil
 }
 
-func (r *jsonRepository) FindByID(id string) (*domain.Recipe, error) {
+// ValidationReport returns the problems found when the recipe files were loaded.
+func (r *jsonRepository) ValidationReport(ctx context.Context) (ValidationReport, error) {
+	if err := ctx.Err(); err != nil {
+		return ValidationReport{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := r.report
+	report.Files = append([]FileReport{}, r.report.Files...)
+	return report, nil
+}
+
+// contentHash returns a hash of the canonical JSON encoding of a recipe, so that
+// formatting-only changes to a file do not invalidate client caches.
+func contentHash(recipe domain.Recipe) (string, error) {
+	data, err := json.Marshal(recipe)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func (r *jsonRepository) FindByID(ctx context.Context, id string) (*domain.Recipe, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	recipe, ok := r.recipes[id]
 	if !ok {
-		return nil, errors.New("recipe not found")
+		return nil, ErrNotFound
 	}
 	// Return a copy to avoid side-effects on the in-memory map
-	return &recipe, nil
+	clone := recipe.Clone()
+	return &clone, nil
 }
 
 // ListAll returns all recipes in the repository.
-func (r *jsonRepository) ListAll() ([]domain.Recipe, error) {
+func (r *jsonRepository) ListAll(ctx context.Context) ([]domain.Recipe, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	recipes := make([]domain.Recipe, 0, len(r.recipes))
 	for _, rcp := range r.recipes {
-		recipes = append(recipes, rcp)
+		recipes = append(recipes, rcp.Clone())
 	}
 	return recipes, nil
 }
+
+// RecipeVersion returns the content hash and modification time of a single recipe.
+func (r *jsonRepository) RecipeVersion(ctx context.Context, id string) (Version, error) {
+	if err := ctx.Err(); err != nil {
+		return Version{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	version, ok := r.versions[id]
+	if !ok {
+		return Version{}, ErrNotFound
+	}
+	return version, nil
+}
+
+// CollectionVersion returns a version covering every recipe in the repository:
+// the hash changes whenever a recipe is added, removed or modified, and the
+// modification time is the most recent one across all recipes.
+func (r *jsonRepository) CollectionVersion(ctx context.Context) (Version, error) {
+	if err := ctx.Err(); err != nil {
+		return Version{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.versions))
+	for id := range r.versions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	hash := sha256.New()
+	var lastModified time.Time
+	for _, id := range ids {
+		version := r.versions[id]
+		fmt.Fprintf(hash, "%s:%s\n", id, version.ETag)
+		if version.LastModified.After(lastModified) {
+			lastModified = version.LastModified
+		}
+	}
+	return Version{
+		ETag:         hex.EncodeToString(hash.Sum(nil)[:16]),
+		LastModified: lastModified,
+	}, nil
+}
@@ -1,9 +1,13 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
 )
 
 func TestNewJSONRepository_SingleFile(t *testing.T) {
@@ -36,7 +40,7 @@ func TestNewJSONRepository_SingleFile(t *testing.T) {
 	}
 
 	// Check if we can retrieve the recipe
-	rcp, err := repo.FindByID("1")
+	rcp, err := repo.FindByID(context.Background(), "1")
 	if err != nil {
 		t.Errorf("expected to find recipe '1', got error %v", err)
 	}
@@ -96,7 +100,7 @@ func TestNewJSONRepository_MultipleFiles(t *testing.T) {
 	}
 
 	// Check first recipe
-	rcp1, err := repo.FindByID("1")
+	rcp1, err := repo.FindByID(context.Background(), "1")
 	if err != nil {
 		t.Errorf("expected to find recipe '1', got error %v", err)
 	}
@@ -105,7 +109,7 @@ func TestNewJSONRepository_MultipleFiles(t *testing.T) {
 	}
 
 	// Check second recipe
-	rcp2, err := repo.FindByID("2")
+	rcp2, err := repo.FindByID(context.Background(), "2")
 	if err != nil {
 		t.Errorf("expected to find recipe '2', got error %v", err)
 	}
@@ -113,3 +117,338 @@ func TestNewJSONRepository_MultipleFiles(t *testing.T) {
 		t.Errorf("expected 'Omelette', got %s", rcp2.Name)
 	}
 }
+
+func TestJSONRepository_Versions(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test-recipes-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := []byte(`{"id": "1", "name": "Pancakes"}`)
+	if err := os.WriteFile(filepath.Join(dir, "recipes1.json"), content, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	v1, err := repo.RecipeVersion(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("expected version for recipe '1', got error %v", err)
+	}
+	if v1.ETag == "" || v1.LastModified.IsZero() {
+		t.Errorf("expected ETag and LastModified to be set, got %+v", v1)
+	}
+	c1, err := repo.CollectionVersion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.RecipeVersion(context.Background(), "999"); err == nil {
+		t.Error("expected error for missing recipe, got none")
+	}
+
+	// Reformatting the file must not change the hash, editing it must.
+	reformatted := []byte("{\n  \"name\": \"Pancakes\",\n  \"id\": \"1\"\n}\n")
+	if err := os.WriteFile(filepath.Join(dir, "recipes1.json"), reformatted, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	repo, err = NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	if v2, _ := repo.RecipeVersion(context.Background(), "1"); v2.ETag != v1.ETag {
+		t.Errorf("expected ETag to survive reformatting, got %s and %s", v1.ETag, v2.ETag)
+	}
+
+	edited := []byte(`{"id": "1", "name": "Crepes"}`)
+	if err := os.WriteFile(filepath.Join(dir, "recipes1.json"), edited, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	repo, err = NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	if v3, _ := repo.RecipeVersion(context.Background(), "1"); v3.ETag == v1.ETag {
+		t.Error("expected ETag to change after editing the recipe")
+	}
+	if c3, _ := repo.CollectionVersion(context.Background()); c3.ETag == c1.ETag {
+		t.Error("expected collection ETag to change after editing a recipe")
+	}
+}
+
+func TestJSONRepository_SaveAndRevisions(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test-recipes-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := []byte(`{"id": "1", "name": "Pancakes", "ingredients": [{"name": "Flour", "quantity": 200, "unit": "grams"}]}`)
+	if err := os.WriteFile(filepath.Join(dir, "pancakes.json"), content, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	// Before any save, the file content is the only revision.
+	revisions, err := repo.ListRevisions(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Number != 1 {
+		t.Fatalf("expected a single initial revision, got %+v", revisions)
+	}
+
+	updated, _ := repo.FindByID(context.Background(), "1")
+	updated.Ingredients[0].Quantity = 250
+	revision, err := repo.Save(context.Background(), *updated, "alice")
+	if err != nil {
+		t.Fatalf("failed to save recipe: %v", err)
+	}
+	if revision.Number != 2 || revision.Author != "alice" {
+		t.Errorf("expected revision 2 by alice, got %d by %s", revision.Number, revision.Author)
+	}
+
+	created := domain.Recipe{ID: "new recipe", Name: "Omelette"}
+	if _, err := repo.Save(context.Background(), created, "bob"); err != nil {
+		t.Fatalf("failed to save new recipe: %v", err)
+	}
+
+	// Reload from disk: the saves and the history must have been persisted.
+	repo, err = NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to reload repository: %v", err)
+	}
+	rcp, err := repo.FindByID(context.Background(), "1")
+	if err != nil || rcp.Ingredients[0].Quantity != 250 {
+		t.Errorf("expected saved quantity 250, got %+v (err %v)", rcp, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new_recipe.json")); err != nil {
+		t.Errorf("expected new recipe file to be created: %v", err)
+	}
+
+	first, err := repo.FindRevision(context.Background(), "1", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Recipe.Ingredients[0].Quantity != 200 {
+		t.Errorf("expected initial revision quantity 200, got %v", first.Recipe.Ingredients[0].Quantity)
+	}
+	if _, err := repo.FindRevision(context.Background(), "1", 3); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound, got %v", err)
+	}
+	if _, err := repo.ListRevisions(context.Background(), "999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestJSONRepository_SaveFailureLeavesNoRevision(t *testing.T) {
+	dir := writeRecipeFiles(t, map[string]string{
+		"pancakes.json": `{"id": "1", "name": "Pancakes"}`,
+	})
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+
+	// Replace the recipe file with a directory, so that it cannot be written.
+	path := filepath.Join(dir, "pancakes.json")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	recipe, _ := repo.FindByID(ctx, "1")
+	recipe.Name = "Crêpes"
+	if _, err := repo.Save(ctx, *recipe, "alice"); err == nil {
+		t.Fatal("expected the save to fail")
+	}
+	if _, err := os.Stat(filepath.Join(dir, historyDir, "1.jsonl")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no history to be recorded, got %v", err)
+	}
+	if revisions, err := repo.ListRevisions(ctx, "1"); err != nil || len(revisions) != 1 || revisions[0].Recipe.Name != "Pancakes" {
+		t.Errorf("expected the initial revision only, got %+v, %v", revisions, err)
+	}
+}
+
+func TestJSONRepository_FindByIDReturnsCopy(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test-recipes-")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := []byte(`{"id": "1", "name": "Pancakes", "ingredients": [{"name": "Flour", "quantity": 200, "unit": "grams"}]}`)
+	if err := os.WriteFile(filepath.Join(dir, "pancakes.json"), content, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	rcp, _ := repo.FindByID(context.Background(), "1")
+	rcp.Ingredients[0].Quantity = 1000
+
+	again, _ := repo.FindByID(context.Background(), "1")
+	if again.Ingredients[0].Quantity != 200 {
+		t.Errorf("expected stored quantity to stay 200, got %v", again.Ingredients[0].Quantity)
+	}
+}
+
+func writeRecipeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestNewJSONRepository_Validation(t *testing.T) {
+	files := map[string]string{
+		"good.json":     `{"id": "1", "name": "Pancakes", "ingredients": [{"name": "Flour", "quantity": 200, "unit": "g"}]}`,
+		"negative.json": `{"id": "2", "name": "Omelette", "ingredients": [{"name": "Egg", "quantity": -3, "unit": "pc"}]}`,
+		"z_dup.json":    `{"id": "1", "name": "Crepes"}`,
+		"broken.json":   `{"id": `,
+	}
+	validator := domain.NewRecipeValidator()
+
+	t.Run("strict", func(t *testing.T) {
+		dir := writeRecipeFiles(t, files)
+		if _, err := NewJSONRepository(dir, WithValidator(validator, true)); err == nil {
+			t.Error("expected strict loading to fail")
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		dir := writeRecipeFiles(t, files)
+		repo, err := NewJSONRepository(dir, WithValidator(validator, false))
+		if err != nil {
+			t.Fatalf("expected lenient loading to succeed, got %v", err)
+		}
+
+		rcp, err := repo.FindByID(context.Background(), "1")
+		if err != nil || rcp.Name != "Pancakes" {
+			t.Errorf("expected the first file with ID '1' to be kept, got %+v (err %v)", rcp, err)
+		}
+		if _, err := repo.FindByID(context.Background(), "2"); err == nil {
+			t.Error("expected the invalid recipe to be skipped")
+		}
+
+		report, err := repo.ValidationReport(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Strict || report.Loaded != 1 || len(report.Files) != 3 {
+			t.Fatalf("unexpected report %+v", report)
+		}
+		for _, file := range report.Files {
+			if !file.Skipped || len(file.Errors) == 0 {
+				t.Errorf("expected %s to be skipped with errors, got %+v", file.Path, file)
+			}
+		}
+	})
+
+	t.Run("save", func(t *testing.T) {
+		dir := writeRecipeFiles(t, map[string]string{"good.json": files["good.json"]})
+		repo, err := NewJSONRepository(dir, WithValidator(validator, true))
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		invalid := domain.Recipe{ID: "1", Name: "Pancakes", Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 1, Unit: "bucket"}}}
+		_, err = repo.Save(context.Background(), invalid, "alice")
+		var validationErrs domain.ValidationErrors
+		if !errors.As(err, &validationErrs) || validationErrs[0].Path != "ingredients[0].unit" {
+			t.Errorf("expected a unit validation error, got %v", err)
+		}
+	})
+}
+
+func TestNewJSONRepository_DuplicateIDsWithoutValidator(t *testing.T) {
+	dir := writeRecipeFiles(t, map[string]string{
+		"a.json": `{"id": "1", "name": "Pancakes"}`,
+		"b.json": `{"id": "1", "name": "Crepes"}`,
+	})
+
+	_, err := NewJSONRepository(dir)
+	if err == nil {
+		t.Fatal("expected duplicate IDs to fail loading")
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+	write("a.json", `{"id": "1", "name": "Pancakes"}`)
+
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	write("b.json", `{"id": "2", "name": "Waffles"}`)
+	if err := repo.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	if _, err := repo.FindByID(context.Background(), "2"); err != nil {
+		t.Errorf("expected the new recipe after reload, got %v", err)
+	}
+
+	// A broken file fails a strict reload and keeps the loaded recipes.
+	write("c.json", `{"id": `)
+	if err := repo.Reload(context.Background()); err == nil {
+		t.Fatal("expected a reload error for a broken file")
+	}
+	recipes, _ := repo.ListAll(context.Background())
+	if len(recipes) != 2 {
+		t.Errorf("expected the 2 previously loaded recipes to be kept, got %d", len(recipes))
+	}
+}
+
+func TestCancelledContext(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"id": "1", "name": "Pancakes"}`), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.FindByID(ctx, "1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected FindByID to be cancelled, got %v", err)
+	}
+	if _, err := repo.ListAll(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected ListAll to be cancelled, got %v", err)
+	}
+	if _, err := repo.Save(ctx, domain.Recipe{ID: "2", Name: "Waffles"}, "alice"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Save to be cancelled, got %v", err)
+	}
+	if _, err := repo.FindByID(context.Background(), "2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a cancelled save to store nothing, got %v", err)
+	}
+	if err := repo.Reload(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Reload to be cancelled, got %v", err)
+	}
+	if _, err := repo.FindByID(context.Background(), "1"); err != nil {
+		t.Errorf("expected a cancelled reload to keep the recipes, got %v", err)
+	}
+}
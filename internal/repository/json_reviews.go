@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// cloneReview copies a review, so that callers cannot change the stored one.
+func cloneReview(review domain.Review) domain.Review {
+	if review.Adjustments != nil {
+		review.Adjustments = append([]string{}, review.Adjustments...)
+	}
+	return review
+}
+
+// sortReviews orders reviews newest first.
+func sortReviews(reviews []domain.Review) {
+	sort.Slice(reviews, func(i, j int) bool {
+		if !reviews[i].CreatedAt.Equal(reviews[j].CreatedAt) {
+			return reviews[i].CreatedAt.After(reviews[j].CreatedAt)
+		}
+		return reviews[i].ID < reviews[j].ID
+	})
+}
+
+func (r *jsonAccountRepository) ListReviews(ctx context.Context, recipeID string) (reviews []domain.Review, err error) {
+	err = r.read(ctx, func() error {
+		reviews = []domain.Review{}
+		for _, review := range r.state.Reviews {
+			if review.RecipeID == recipeID {
+				reviews = append(reviews, cloneReview(review))
+			}
+		}
+		sortReviews(reviews)
+		return nil
+	})
+	return reviews, err
+}
+
+func (r *jsonAccountRepository) FindReview(ctx context.Context, recipeID, id string) (review *domain.Review, err error) {
+	err = r.read(ctx, func() error {
+		found, ok := r.state.Reviews[id]
+		if !ok || found.RecipeID != recipeID {
+			return ErrReviewNotFound
+		}
+		found = cloneReview(found)
+		review = &found
+		return nil
+	})
+	return review, err
+}
+
+func (r *jsonAccountRepository) SaveReview(ctx context.Context, review domain.Review) error {
+	return r.update(ctx, func() error {
+		r.state.Reviews[review.ID] = cloneReview(review)
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) DeleteReview(ctx context.Context, recipeID, id string) error {
+	return r.update(ctx, func() error {
+		if existing, ok := r.state.Reviews[id]; !ok || existing.RecipeID != recipeID {
+			return ErrReviewNotFound
+		}
+		delete(r.state.Reviews, id)
+		return nil
+	})
+}
+
+func (r *jsonAccountRepository) ReviewStats(ctx context.Context) (stats map[string]domain.RecipeStats, err error) {
+	err = r.read(ctx, func() error {
+		byRecipe := make(map[string][]domain.Review)
+		for _, review := range r.state.Reviews {
+			byRecipe[review.RecipeID] = append(byRecipe[review.RecipeID], review)
+		}
+		stats = make(map[string]domain.RecipeStats, len(byRecipe))
+		for recipeID, reviews := range byRecipe {
+			stats[recipeID] = domain.SummarizeReviews(reviews)
+		}
+		return nil
+	})
+	return stats, err
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// historyDir is the directory, relative to the recipes directory, holding one
+// append-only JSON Lines file of revisions per recipe.
+const historyDir = ".history"
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// Save writes the recipe to the file it was loaded from (or to a new file named
+// after its ID at the top of the directory) and appends a revision to its
+// history. The first time an existing recipe is saved, its content on disk is
+// recorded as revision 1. With folder categories, the category of the folder
+// the file is in replaces the one of the recipe. The recipe gets a unique
+// slug, as described by assignSlug.
+// Cancelling ctx has no effect once the files are being written.
+func (r *jsonRepository) Save(ctx context.Context, recipe domain.Recipe, author string) (*domain.Revision, error) {
+	if recipe.ID == "" {
+		return nil, errors.New("recipe ID is required")
+	}
+	if r.validator != nil {
+		if errs := r.validator.Validate(recipe); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The request may have been cancelled while waiting for the lock.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	history, err := r.readHistory(recipe.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	path, exists := r.paths[recipe.ID]
+	if !exists {
+		if err := r.checkNewID(recipe.ID); err != nil {
+			return nil, err
+		}
+		if path, err = r.newRecipePath(recipe.ID); err != nil {
+			return nil, err
+		}
+	}
+	if category := r.layout.category(r.dirPath, path); category != "" {
+		recipe.Category = category
+	}
+	var previous *domain.Recipe
+	if stored, ok := r.recipes[recipe.ID]; ok {
+		previous = &stored
+	}
+	r.assignSlug(&recipe, previous)
+	var pending []domain.Revision
+	if len(history) == 0 && exists {
+		initial := r.initialRevision(recipe.ID)
+		pending = append(pending, initial)
+		history = append(history, initial)
+	}
+	revision := domain.Revision{
+		Number:    len(history) + 1,
+		Timestamp: time.Now().UTC(),
+		Author:    author,
+		Recipe:    recipe.Clone(),
+	}
+	pending = append(pending, revision)
+
+	// The recipe file is written first, so that a failed write leaves no
+	// revision behind; it is put back if the history cannot be appended.
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read recipe %s: %w", recipe.ID, err)
+	}
+	// The file holds the ID without the namespace given by its folder.
+	stored := recipe
+	stored.ID = strings.TrimPrefix(recipe.ID, r.layout.namespace(r.dirPath, path))
+	if err := writeJSONFile(path, recipeFile{SchemaVersion, stored}); err != nil {
+		return nil, err
+	}
+	if err := r.appendHistory(recipe.ID, pending); err != nil {
+		if original != nil {
+			err = errors.Join(err, writeFile(path, original))
+		} else {
+			err = errors.Join(err, os.Remove(path))
+		}
+		return nil, err
+	}
+
+	etag, err := contentHash(recipe)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash recipe %s: %w", recipe.ID, err)
+	}
+	if previous != nil {
+		r.unindexSlugs(*previous)
+	}
+	r.indexSlugs(recipe)
+	r.recipes[recipe.ID] = recipe.Clone()
+	r.versions[recipe.ID] = Version{ETag: etag, LastModified: revision.Timestamp}
+	r.paths[recipe.ID] = path
+
+	return &revision, nil
+}
+
+// ListRevisions returns every revision of a recipe, oldest first. A recipe that
+// has never been saved through the repository has a single initial revision.
+func (r *jsonRepository) ListRevisions(ctx context.Context, id string) ([]domain.Revision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history, err := r.readHistory(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		if _, ok := r.recipes[id]; !ok {
+			return nil, ErrNotFound
+		}
+		history = []domain.Revision{r.initialRevision(id)}
+	}
+	return history, nil
+}
+
+// FindRevision returns a single revision of a recipe by number (starting at 1).
+func (r *jsonRepository) FindRevision(ctx context.Context, id string, number int) (*domain.Revision, error) {
+	history, err := r.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if number < 1 || number > len(history) {
+		return nil, ErrRevisionNotFound
+	}
+	revision := history[number-1]
+	return &revision, nil
+}
+
+// initialRevision describes the current in-memory state of a recipe that has
+// no recorded history yet. The caller must hold the lock.
+func (r *jsonRepository) initialRevision(id string) domain.Revision {
+	return domain.Revision{
+		Number:    1,
+		Timestamp: r.versions[id].LastModified.UTC(),
+		Recipe:    r.recipes[id].Clone(),
+	}
+}
+
+func (r *jsonRepository) historyPath(id string) string {
+	return filepath.Join(r.dirPath, historyDir, url.PathEscape(id)+".jsonl")
+}
+
+// readHistory loads the recorded revisions of a recipe. A missing history file
+// is not an error.
+func (r *jsonRepository) readHistory(id string) ([]domain.Revision, error) {
+	file, err := os.Open(r.historyPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history of recipe %s: %w", id, err)
+	}
+	defer file.Close()
+
+	var history []domain.Revision
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		revision, _, err := decodeHistoryEntry(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal history of recipe %s: %w", id, err)
+		}
+		history = append(history, revision)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history of recipe %s: %w", id, err)
+	}
+	return history, nil
+}
+
+func (r *jsonRepository) appendHistory(id string, revisions []domain.Revision) error {
+	if err := os.MkdirAll(filepath.Join(r.dirPath, historyDir), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	file, err := os.OpenFile(r.historyPath(id), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history of recipe %s: %w", id, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat history of recipe %s: %w", id, err)
+	}
+
+	// A failed append is cut off, so that the history does not end with a
+	// partial line.
+	var data []byte
+	for _, revision := range revisions {
+		line, err := json.Marshal(historyEntry{SchemaVersion, revision})
+		if err != nil {
+			return fmt.Errorf("failed to encode history of recipe %s: %w", id, err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if _, err := file.Write(data); err != nil {
+		return errors.Join(fmt.Errorf("failed to append history of recipe %s: %w", id, err), file.Truncate(info.Size()))
+	}
+	return file.Sync()
+}
+
+// newRecipePath picks a file name for a recipe that is not stored yet, derived
+// from its ID and not clashing with any existing file. Namespaced IDs are
+// stored in their folder, which must be one the layout reads.
+func (r *jsonRepository) newRecipePath(id string) (string, error) {
+	folder, fileID := r.layout.splitID(id)
+	dir := r.dirPath
+	if folder != "" {
+		if !fs.ValidPath(folder) || strings.Count(folder, "/")+1 > r.layout.MaxDepth || strings.Contains("/"+folder, "/.") {
+			return "", domain.ValidationErrors{{Path: "id", Message: fmt.Sprintf("folder %q of the recipe ID is not read", folder)}}
+		}
+		dir = filepath.Join(r.dirPath, filepath.FromSlash(folder))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create folder %s: %w", folder, err)
+		}
+	}
+	base := unsafeFileChars.ReplaceAllString(fileID, "_")
+	path := filepath.Join(dir, base+".json")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.json", base, i))
+	}
+}
+
+// writeJSONFile atomically replaces path with the indented JSON encoding of v.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	return writeFile(path, append(data, '\n'))
+}
+
+// writeFile atomically replaces path with data.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
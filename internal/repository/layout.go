@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFile is the file of the recipes directory holding ignore patterns,
+// one per line, read in addition to those of the Layout.
+const ignoreFile = ".recipeignore"
+
+// Layout describes which files of the recipes directory hold recipes.
+type Layout struct {
+	// MaxDepth is how many levels of subdirectories are read; 0 only reads
+	// the recipes directory itself.
+	MaxDepth int
+	// Ignore lists .gitignore-style patterns of files and directories to
+	// skip, relative to the recipes directory.
+	Ignore []string
+	// FolderCategories sets the category of the recipes in a subdirectory
+	// to its path relative to the recipes directory, e.g. "desserts/cakes".
+	FolderCategories bool
+	// NamespaceIDs prefixes the IDs of the recipes in a subdirectory with
+	// its path, e.g. "desserts/cake" for the recipe "cake" of desserts/, so
+	// that recipes of different folders may share an ID. Recipes saved with
+	// such an ID are stored in its folder.
+	NamespaceIDs bool
+}
+
+// WithLayout reads the recipes directory as described by layout. Without it,
+// only the recipes directory itself is read.
+func WithLayout(layout Layout) Option {
+	return func(r *jsonRepository) {
+		r.layout = layout
+	}
+}
+
+// ignoreRules compiles the ignore patterns of the layout and of the ignore
+// file in dirPath, if any.
+func (l Layout) ignoreRules(dirPath string) (ignoreRules, error) {
+	patterns := append([]string{}, l.Ignore...)
+	data, err := os.ReadFile(filepath.Join(dirPath, ignoreFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", ignoreFile, err)
+	}
+	patterns = append(patterns, strings.Split(string(data), "\n")...)
+	return parseIgnore(patterns)
+}
+
+// category returns the category of the recipe stored in path, or "" for the
+// recipes at the top of dirPath or when categories are not derived from
+// folders.
+func (l Layout) category(dirPath, path string) string {
+	if !l.FolderCategories {
+		return ""
+	}
+	return folder(dirPath, path)
+}
+
+// namespace returns the prefix of the IDs of the recipes stored in path,
+// e.g. "desserts/", or "" for the recipes at the top of dirPath or when IDs
+// are not namespaced.
+func (l Layout) namespace(dirPath, path string) string {
+	if !l.NamespaceIDs {
+		return ""
+	}
+	if dir := folder(dirPath, path); dir != "" {
+		return dir + "/"
+	}
+	return ""
+}
+
+// splitID returns the folder a recipe ID names, if IDs are namespaced, and
+// the ID stored in the recipe file.
+func (l Layout) splitID(id string) (dir, fileID string) {
+	if !l.NamespaceIDs {
+		return "", id
+	}
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+	return "", id
+}
+
+// folder returns the slash-separated directory of path relative to dirPath,
+// or "" for files at the top of dirPath.
+func folder(dirPath, path string) string {
+	rel, err := filepath.Rel(dirPath, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// walkRecipeFiles calls fn with every .json file of dirPath the layout
+// includes, in lexical order. Hidden directories, such as the history and the
+// backups, are never read. It stops at the next file once ctx is cancelled.
+func walkRecipeFiles(ctx context.Context, dirPath string, layout Layout, fn func(path string) error) error {
+	rules, err := layout.ignoreRules(dirPath)
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(dirPath, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if path == dirPath {
+			return nil
+		}
+		rel, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			depth := strings.Count(rel, "/") + 1
+			if strings.HasPrefix(info.Name(), ".") || depth > layout.MaxDepth || rules.ignored(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".json" || rules.ignored(rel, false) {
+			return nil
+		}
+		return fn(path)
+	})
+}
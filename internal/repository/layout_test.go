@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// writeRecipeTree writes files to a temporary directory, creating the
+// directories in their paths.
+func writeRecipeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestNewJSONRepository_Layout(t *testing.T) {
+	dir := writeRecipeTree(t, map[string]string{
+		"pancakes.json":                    `{"id": "1", "name": "Pancakes", "category": "breakfast"}`,
+		"desserts/cake.json":               `{"id": "2", "name": "Cake", "category": "baking"}`,
+		"desserts/cakes/sponge.json":       `{"id": "3", "name": "Sponge"}`,
+		"desserts/cakes/deep/too_far.json": `{"id": "4", "name": "Too far"}`,
+		"drafts/soup.json":                 `{"id": "5", "name": "Soup"}`,
+		"mains/stew.tmp.json":              `{"id": "6", "name": "Stew"}`,
+		".history/1.jsonl":                 ``,
+		".hidden/secret.json":              `{"id": "7", "name": "Secret"}`,
+		".recipeignore":                    "# work in progress\ndrafts/\n",
+	})
+	ctx := context.Background()
+
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	if recipes, _ := repo.ListAll(ctx); len(recipes) != 1 {
+		t.Errorf("expected only the top directory to be read by default, got %d recipes", len(recipes))
+	}
+
+	repo, err = NewJSONRepository(dir, WithLayout(Layout{MaxDepth: 2, Ignore: []string{"*.tmp.json"}, FolderCategories: true}))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	recipes, _ := repo.ListAll(ctx)
+	categories := make(map[string]string)
+	for _, recipe := range recipes {
+		categories[recipe.ID] = recipe.Category
+	}
+	want := map[string]string{"1": "breakfast", "2": "desserts", "3": "desserts/cakes"}
+	if len(categories) != len(want) {
+		t.Fatalf("expected recipes %v, got %v", want, categories)
+	}
+	for id, category := range want {
+		if categories[id] != category {
+			t.Errorf("expected recipe %s in category %q, got %q", id, category, categories[id])
+		}
+	}
+
+	// Saving keeps the recipe in its folder and category.
+	sponge, _ := repo.FindByID(ctx, "3")
+	sponge.Category = "other"
+	if _, err := repo.Save(ctx, *sponge, "alice"); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	if saved, _ := repo.FindByID(ctx, "3"); saved.Category != "desserts/cakes" {
+		t.Errorf("expected the folder category to win, got %q", saved.Category)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "desserts", "cakes", "sponge.json")); !strings.Contains(string(data), `"desserts/cakes"`) {
+		t.Errorf("expected the recipe to be saved in its folder, got %s", data)
+	}
+}
+
+func TestNewJSONRepository_LayoutCollision(t *testing.T) {
+	dir := writeRecipeTree(t, map[string]string{
+		"desserts/cake.json": `{"id": "2", "name": "Cake"}`,
+		"mains/cake.json":    `{"id": "2", "name": "Fish cake"}`,
+	})
+	_, err := NewJSONRepository(dir, WithLayout(Layout{MaxDepth: 1}))
+	if err == nil {
+		t.Fatal("expected duplicate IDs across folders to fail loading")
+	}
+	first, second := filepath.Join(dir, "desserts", "cake.json"), filepath.Join(dir, "mains", "cake.json")
+	if !strings.Contains(err.Error(), `recipe ID "2" is used by both `+first+" and "+second) {
+		t.Errorf("expected both paths in the error, got %v", err)
+	}
+}
+
+func TestNewJSONRepository_NamespaceIDs(t *testing.T) {
+	dir := writeRecipeTree(t, map[string]string{
+		"cake.json":          `{"id": "2", "name": "Cake"}`,
+		"desserts/cake.json": `{"id": "2", "name": "Chocolate cake"}`,
+		"mains/cake.json":    `{"id": "2", "name": "Fish cake"}`,
+	})
+	repo, err := NewJSONRepository(dir, WithLayout(Layout{MaxDepth: 1, NamespaceIDs: true}))
+	if err != nil {
+		t.Fatalf("expected namespaced IDs not to collide, got %v", err)
+	}
+	ctx := context.Background()
+	for id, name := range map[string]string{"2": "Cake", "desserts/2": "Chocolate cake", "mains/2": "Fish cake"} {
+		if recipe, err := repo.FindByID(ctx, id); err != nil || recipe.Name != name {
+			t.Errorf("expected recipe %s to be %s, got %+v, %v", id, name, recipe, err)
+		}
+	}
+
+	// Saving keeps the file ID without the namespace.
+	cake, _ := repo.FindByID(ctx, "desserts/2")
+	cake.Name = "Dark chocolate cake"
+	if _, err := repo.Save(ctx, *cake, "alice"); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "desserts", "cake.json")); !strings.Contains(string(data), `"id": "2"`) {
+		t.Errorf("expected the file to keep its own ID, got %s", data)
+	}
+
+	// New recipes with a namespaced ID are stored in its folder.
+	if _, err := repo.Save(ctx, domain.Recipe{ID: "sides/salad", Name: "Salad"}, "alice"); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "sides", "salad.json")); err != nil || !strings.Contains(string(data), `"id": "salad"`) {
+		t.Errorf("expected the recipe to be stored in its folder, got %s, %v", data, err)
+	}
+	if _, err := repo.Save(ctx, domain.Recipe{ID: "sides/green/salad", Name: "Green salad"}, "alice"); !strings.Contains(fmt.Sprint(err), "is not read") {
+		t.Errorf("expected folders beyond the depth to be refused, got %v", err)
+	}
+
+	if err := repo.Reload(ctx); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if recipe, err := repo.FindByID(ctx, "sides/salad"); err != nil || recipe.Name != "Salad" {
+		t.Errorf("expected the new recipe to load under its namespaced ID, got %+v, %v", recipe, err)
+	}
+	if recipe, err := repo.FindByID(ctx, "desserts/2"); err != nil || recipe.Name != "Dark chocolate cake" {
+		t.Errorf("expected the saved recipe to load under its namespaced ID, got %+v, %v", recipe, err)
+	}
+}
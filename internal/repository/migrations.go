@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// SchemaVersion is the version of the recipe file format written by this
+// build. Files without a schemaVersion field are version 0.
+const SchemaVersion = 1
+
+const schemaVersionKey = "schemaVersion"
+
+// backupDir is the directory, relative to the recipes directory, where
+// MigrateFiles copies the files it rewrites by default.
+const backupDir = ".backup"
+
+// ErrUnsupportedSchema is returned for files written by a newer build.
+var ErrUnsupportedSchema = errors.New("unsupported recipe schema version")
+
+// migration upgrades a decoded recipe document by one schema version, in place.
+type migration func(doc map[string]any) error
+
+// migrations[i] upgrades a recipe document from version i to version i+1, so
+// there is one per version. Any change to the JSON encoding of domain.Recipe
+// must bump SchemaVersion and append a migration here, so that older files
+// keep loading.
+var migrations = []migration{
+	// Version 1 adds the schemaVersion field; the recipe is unchanged.
+	func(map[string]any) error { return nil },
+}
+
+// recipeFile is the encoding of a recipe file.
+type recipeFile struct {
+	SchemaVersion int `json:"schemaVersion"`
+	domain.Recipe
+}
+
+// historyEntry is the encoding of a revision in a history file.
+type historyEntry struct {
+	SchemaVersion int `json:"schemaVersion"`
+	domain.Revision
+}
+
+// decodeRecipeFile decodes a recipe file of any supported version into the
+// current format. It returns the version the file was written in.
+func decodeRecipeFile(data []byte) (domain.Recipe, int, error) {
+	var file recipeFile
+	version, err := upgrade(data, "", &file)
+	return file.Recipe, version, err
+}
+
+// decodeHistoryEntry decodes a line of a history file of any supported
+// version into the current format. It returns the version the line was
+// written in.
+func decodeHistoryEntry(data []byte) (domain.Revision, int, error) {
+	var entry historyEntry
+	version, err := upgrade(data, "recipe", &entry)
+	return entry.Revision, version, err
+}
+
+// upgrade decodes the JSON object in data into v after migrating the recipe
+// it holds, which is the object itself or its recipeKey member.
+func upgrade(data []byte, recipeKey string, v any) (int, error) {
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return 0, err
+	}
+	version, err := schemaVersion(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	if version < SchemaVersion {
+		recipe := doc
+		if recipeKey != "" {
+			var ok bool
+			if recipe, ok = doc[recipeKey].(map[string]any); !ok {
+				return version, fmt.Errorf("missing %q object", recipeKey)
+			}
+		}
+		for from := version; from < SchemaVersion; from++ {
+			if err := migrations[from](recipe); err != nil {
+				return version, fmt.Errorf("failed to migrate from schema version %d: %w", from, err)
+			}
+		}
+		doc[schemaVersionKey] = SchemaVersion
+		if data, err = json.Marshal(doc); err != nil {
+			return version, err
+		}
+	}
+	return version, json.Unmarshal(data, v)
+}
+
+// schemaVersion reads the schema version of a document.
+func schemaVersion(doc map[string]any) (int, error) {
+	value, ok := doc[schemaVersionKey]
+	if !ok {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%w %v", ErrUnsupportedSchema, value)
+	}
+	version, err := number.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%w %s", ErrUnsupportedSchema, number)
+	}
+	if version > SchemaVersion {
+		return 0, fmt.Errorf("%w %d, this build reads versions up to %d", ErrUnsupportedSchema, version, SchemaVersion)
+	}
+	return int(version), nil
+}
+
+// MigrateOptions configures MigrateFiles.
+type MigrateOptions struct {
+	// Layout selects the recipe files, as when loading them.
+	Layout Layout
+	// BackupDir receives a copy of every file before it is rewritten, under
+	// its path relative to the recipes directory. It defaults to a directory
+	// named after the current time in .backup in the recipes directory.
+	BackupDir string
+	// DryRun reports the files to migrate without changing anything.
+	DryRun bool
+}
+
+// MigratedFile is a file written in an older schema version.
+type MigratedFile struct {
+	Path string `json:"path"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+}
+
+// MigrationReport lists the files MigrateFiles rewrote, or would rewrite.
+type MigrationReport struct {
+	DryRun    bool           `json:"dry_run"`
+	BackupDir string         `json:"backup_dir,omitempty"`
+	Files     []MigratedFile `json:"files"`
+	// UpToDate counts the files already in the current schema version.
+	UpToDate int `json:"up_to_date"`
+}
+
+// pendingFile is a file to rewrite, with its content before and after.
+type pendingFile struct {
+	MigratedFile
+	original []byte
+	migrated []byte
+}
+
+// MigrateFiles rewrites the recipe files and history files in dirPath that
+// are in an older schema version to SchemaVersion, after copying them to the
+// backup directory. Every file is decoded before anything is written, so a
+// broken file leaves the directory untouched. It must not run while a server
+// is writing to the directory.
+func MigrateFiles(ctx context.Context, dirPath string, opts MigrateOptions) (*MigrationReport, error) {
+	report := &MigrationReport{DryRun: opts.DryRun, Files: []MigratedFile{}}
+
+	var recipePaths []string
+	err := walkRecipeFiles(ctx, dirPath, opts.Layout, func(path string) error {
+		recipePaths = append(recipePaths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	historyPaths, err := filepath.Glob(filepath.Join(dirPath, historyDir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []pendingFile
+	for _, path := range append(recipePaths, historyPaths...) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		file, err := planMigration(path)
+		if err != nil {
+			return nil, err
+		}
+		if file == nil {
+			report.UpToDate++
+			continue
+		}
+		pending = append(pending, *file)
+		report.Files = append(report.Files, file.MigratedFile)
+	}
+	if opts.DryRun || len(pending) == 0 {
+		return report, nil
+	}
+
+	report.BackupDir = opts.BackupDir
+	if report.BackupDir == "" {
+		report.BackupDir = filepath.Join(dirPath, backupDir, time.Now().UTC().Format("20060102T150405Z"))
+	}
+	for _, file := range pending {
+		rel, err := filepath.Rel(dirPath, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		backup := filepath.Join(report.BackupDir, rel)
+		if err := os.MkdirAll(filepath.Dir(backup), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		if err := os.WriteFile(backup, file.original, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", file.Path, err)
+		}
+	}
+	for _, file := range pending {
+		if err := writeFile(file.Path, file.migrated); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// planMigration reads a recipe or history file and returns its migrated
+// content, or nil if it is already in the current schema version.
+func planMigration(path string) (*pendingFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	file := &pendingFile{MigratedFile: MigratedFile{Path: path, From: SchemaVersion, To: SchemaVersion}, original: data}
+
+	if filepath.Ext(path) == ".json" {
+		recipe, version, err := decodeRecipeFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if version == SchemaVersion {
+			return nil, nil
+		}
+		file.From = version
+		if file.migrated, err = json.MarshalIndent(recipeFile{SchemaVersion, recipe}, "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", path, err)
+		}
+		file.migrated = append(file.migrated, '\n')
+		return file, nil
+	}
+
+	var migrated bytes.Buffer
+	encoder := json.NewEncoder(&migrated)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		revision, version, err := decodeHistoryEntry(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode line %d of %s: %w", line, path, err)
+		}
+		file.From = min(file.From, version)
+		if err := encoder.Encode(historyEntry{SchemaVersion, revision}); err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", path, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if file.From == SchemaVersion {
+		return nil, nil
+	}
+	file.migrated = migrated.Bytes()
+	return file, nil
+}
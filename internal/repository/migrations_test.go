@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewJSONRepository_SchemaVersions(t *testing.T) {
+	dir := writeRecipeFiles(t, map[string]string{
+		"old.json":     `{"id": "1", "name": "Pancakes", "ingredients": [{"name": "Flour", "quantity": 200, "unit": "g"}]}`,
+		"current.json": `{"schemaVersion": 1, "id": "2", "name": "Omelette"}`,
+		"future.json":  `{"schemaVersion": 99, "id": "3", "name": "Waffles"}`,
+	})
+
+	if _, err := NewJSONRepository(dir); err == nil || !strings.Contains(err.Error(), "unsupported recipe schema version 99") {
+		t.Fatalf("expected files of a newer version to be refused, got %v", err)
+	}
+
+	os.Remove(filepath.Join(dir, "future.json"))
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+	report, _ := repo.ValidationReport(ctx)
+	if report.Loaded != 2 || report.Outdated != 1 {
+		t.Errorf("expected 2 recipes with 1 outdated, got %+v", report)
+	}
+
+	recipe, _ := repo.FindByID(ctx, "1")
+	if _, err := repo.Save(ctx, *recipe, "alice"); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "old.json"))
+	if !strings.HasPrefix(string(data), "{\n  \"schemaVersion\": 1,\n  \"id\": \"1\"") {
+		t.Errorf("expected saved files to carry the schema version, got %s", data)
+	}
+	history, _ := os.ReadFile(filepath.Join(dir, historyDir, "1.jsonl"))
+	if strings.Count(string(history), `"schemaVersion":1`) != 2 {
+		t.Errorf("expected every revision to carry the schema version, got %s", history)
+	}
+}
+
+func TestMigrateFiles(t *testing.T) {
+	old := `{"id": "1", "name": "Pancakes"}`
+	dir := writeRecipeFiles(t, map[string]string{
+		"old.json":     old,
+		"current.json": `{"schemaVersion": 1, "id": "2", "name": "Omelette"}`,
+	})
+	oldHistory := `{"number":1,"timestamp":"2024-05-01T12:00:00Z","author":"alice","recipe":{"id":"1","name":"Pancakes"}}` + "\n"
+	os.Mkdir(filepath.Join(dir, historyDir), 0o755)
+	os.WriteFile(filepath.Join(dir, historyDir, "1.jsonl"), []byte(oldHistory), 0o644)
+	ctx := context.Background()
+
+	report, err := MigrateFiles(ctx, dir, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Files) != 2 || report.UpToDate != 1 || report.BackupDir != "" {
+		t.Errorf("expected 2 files to migrate, got %+v", report)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "old.json")); string(data) != old {
+		t.Errorf("expected a dry run to change nothing, got %s", data)
+	}
+
+	backup := filepath.Join(t.TempDir(), "backup")
+	report, err = MigrateFiles(ctx, dir, MigrateOptions{BackupDir: backup})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Files) != 2 || report.Files[0].From != 0 || report.Files[0].To != SchemaVersion {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if data, _ := os.ReadFile(filepath.Join(backup, "old.json")); string(data) != old {
+		t.Errorf("expected the original to be backed up, got %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(backup, historyDir, "1.jsonl")); string(data) != oldHistory {
+		t.Errorf("expected the original history to be backed up, got %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "old.json")); !strings.Contains(string(data), `"schemaVersion": 1`) {
+		t.Errorf("expected the file to be rewritten, got %s", data)
+	}
+
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to load migrated files: %v", err)
+	}
+	if report, _ := repo.ValidationReport(ctx); report.Outdated != 0 {
+		t.Errorf("expected no outdated files after migrating, got %+v", report)
+	}
+	if revisions, err := repo.ListRevisions(ctx, "1"); err != nil || len(revisions) != 1 || revisions[0].Author != "alice" {
+		t.Errorf("expected the history to survive, got %+v, %v", revisions, err)
+	}
+
+	if report, err := MigrateFiles(ctx, dir, MigrateOptions{}); err != nil || len(report.Files) != 0 || report.UpToDate != 3 {
+		t.Errorf("expected nothing left to migrate, got %+v, %v", report, err)
+	}
+}
+
+func TestMigrateFiles_Broken(t *testing.T) {
+	dir := writeRecipeFiles(t, map[string]string{
+		"a.json": `{"id": "1", "name": "Pancakes"}`,
+		"b.json": `{"schemaVersion": 2, "id": "2"}`,
+	})
+	if _, err := MigrateFiles(context.Background(), dir, MigrateOptions{}); !errors.Is(err, ErrUnsupportedSchema) {
+		t.Errorf("expected ErrUnsupportedSchema, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.json")); strings.Contains(string(data), "schemaVersion") {
+		t.Errorf("expected nothing to be rewritten, got %s", data)
+	}
+}
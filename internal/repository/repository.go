@@ -1,8 +1,77 @@
 package repository
 
-import "github.com/fromenjn/recipe-manager/internal/domain"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// Version identifies the stored state of a recipe or of the whole collection.
+// ETag is an opaque content hash (without the surrounding quotes) and
+// LastModified the time the underlying data last changed.
+type Version struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// FileReport describes why a recipe file could not be loaded.
+type FileReport struct {
+	Path     string              `json:"path"`
+	RecipeID string              `json:"recipe_id,omitempty"`
+	Errors   []domain.FieldError `json:"errors"`
+	Skipped  bool                `json:"skipped"`
+}
+
+func (f *FileReport) Error() string {
+	messages := make([]string, len(f.Errors))
+	for i, fieldErr := range f.Errors {
+		messages[i] = fieldErr.Error()
+	}
+	return fmt.Sprintf("invalid recipe file %s: %s", f.Path, strings.Join(messages, "; "))
+}
+
+// ValidationReport summarises the validation of the recipe files at load time.
+// Files only lists the files that had problems.
+type ValidationReport struct {
+	Strict    bool      `json:"strict"`
+	CheckedAt time.Time `json:"checked_at"`
+	Loaded    int       `json:"loaded"`
+	// Outdated counts the loaded files in an older schema version, which
+	// were migrated in memory and are rewritten by the migrate command.
+	Outdated int          `json:"outdated"`
+	Files    []FileReport `json:"files"`
+}
+
+var (
+	ErrNotFound         = errors.New("recipe not found")
+	ErrRevisionNotFound = errors.New("revision not found")
+)
+
+// RecipeRepository stores recipes and their history. Every method returns
+// ctx.Err() without doing any work once ctx is cancelled.
 type RecipeRepository interface {
-	FindByID(id string) (*domain.Recipe, error)
-	ListAll() ([]domain.Recipe, error)
+	FindByID(ctx context.Context, id string) (*domain.Recipe, error)
+	ListAll(ctx context.Context) ([]domain.Recipe, error)
+	// ResolveSlug returns the ID of the recipe with a slug, and whether the
+	// slug is an alias left by renaming the recipe.
+	ResolveSlug(ctx context.Context, slug string) (id string, alias bool, err error)
+	RecipeVersion(ctx context.Context, id string) (Version, error)
+	CollectionVersion(ctx context.Context) (Version, error)
+
+	// Save creates or replaces a recipe and appends a new revision to its history.
+	Save(ctx context.Context, recipe domain.Recipe, author string) (*domain.Revision, error)
+	// ListRevisions returns the history of a recipe, oldest first.
+	ListRevisions(ctx context.Context, id string) ([]domain.Revision, error)
+	FindRevision(ctx context.Context, id string, number int) (*domain.Revision, error)
+
+	// ValidationReport lists the recipe files that failed validation when loading.
+	ValidationReport(ctx context.Context) (ValidationReport, error)
+
+	// Reload reads the recipe files again, e.g. after they were edited on
+	// disk. On failure the previously loaded recipes are kept.
+	Reload(ctx context.Context) error
 }
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+var ErrReviewNotFound = errors.New("review not found")
+
+// ReviewRepository stores the reviews and cook-log entries of recipes.
+// Every method returns ctx.Err() without doing any work once ctx is cancelled.
+type ReviewRepository interface {
+	// ListReviews returns the reviews of a recipe, newest first.
+	ListReviews(ctx context.Context, recipeID string) ([]domain.Review, error)
+	// FindReview returns a review of a recipe, or ErrReviewNotFound.
+	FindReview(ctx context.Context, recipeID, id string) (*domain.Review, error)
+	// SaveReview creates or replaces a review.
+	SaveReview(ctx context.Context, review domain.Review) error
+	DeleteReview(ctx context.Context, recipeID, id string) error
+	// ReviewStats returns the stats of every reviewed recipe by recipe ID.
+	ReviewStats(ctx context.Context) (map[string]domain.RecipeStats, error)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// ResolveSlug returns the ID of the recipe with a slug. When the slug is a
+// previous slug of a renamed recipe, alias is true.
+func (r *jsonRepository) ResolveSlug(ctx context.Context, slug string) (id string, alias bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if id, ok := r.slugs[slug]; ok {
+		return id, false, nil
+	}
+	if id, ok := r.aliases[slug]; ok {
+		return id, true, nil
+	}
+	return "", false, ErrNotFound
+}
+
+// indexSlugs makes a recipe reachable by its slug and aliases. Aliases taken
+// by another recipe stay with it. The caller must hold the lock.
+func (r *jsonRepository) indexSlugs(recipe domain.Recipe) {
+	if recipe.Slug != "" {
+		r.slugs[recipe.Slug] = recipe.ID
+	}
+	for _, alias := range recipe.Aliases {
+		if _, taken := r.aliases[alias]; !taken {
+			r.aliases[alias] = recipe.ID
+		}
+	}
+}
+
+// unindexSlugs forgets the slug and aliases of a recipe. The caller must hold
+// the lock.
+func (r *jsonRepository) unindexSlugs(recipe domain.Recipe) {
+	if r.slugs[recipe.Slug] == recipe.ID {
+		delete(r.slugs, recipe.Slug)
+	}
+	for _, alias := range recipe.Aliases {
+		if r.aliases[alias] == recipe.ID {
+			delete(r.aliases, alias)
+		}
+	}
+}
+
+// deriveSlugs gives the loaded recipes without a slug one derived from their
+// name, in the order of their files so that it does not change between loads.
+func (r *jsonRepository) deriveSlugs() error {
+	var ids []string
+	for id, recipe := range r.recipes {
+		if recipe.Slug == "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return r.paths[ids[i]] < r.paths[ids[j]] })
+
+	for _, id := range ids {
+		recipe := r.recipes[id]
+		recipe.Slug = r.freeSlug(domain.Slugify(recipe.Name), id)
+		etag, err := contentHash(recipe)
+		if err != nil {
+			return fmt.Errorf("failed to hash recipe %s: %w", id, err)
+		}
+		r.recipes[id] = recipe
+		r.versions[id] = Version{ETag: etag, LastModified: r.versions[id].LastModified}
+		r.slugs[recipe.Slug] = id
+	}
+	return nil
+}
+
+// assignSlug sets the slug of a recipe about to be saved: the one asked for,
+// or else the current one unless the recipe was renamed, or else one derived
+// from its name, made unique. A replaced slug is kept as an alias. previous
+// is the stored recipe, if any. The caller must hold the lock.
+func (r *jsonRepository) assignSlug(recipe *domain.Recipe, previous *domain.Recipe) {
+	wanted := domain.Slugify(recipe.Slug)
+	aliases := recipe.Aliases
+	if previous != nil {
+		aliases = previous.Aliases
+		if wanted == "" || wanted == previous.Slug {
+			wanted = previous.Slug
+			if recipe.Name != previous.Name {
+				wanted = ""
+			}
+		}
+	}
+	if wanted == "" {
+		wanted = domain.Slugify(recipe.Name)
+	}
+	recipe.Slug = r.freeSlug(wanted, recipe.ID)
+
+	if previous != nil && previous.Slug != "" && previous.Slug != recipe.Slug {
+		aliases = append(slices.Clone(aliases), previous.Slug)
+	}
+	recipe.Aliases = nil
+	for _, alias := range aliases {
+		alias = domain.Slugify(alias)
+		if alias != "" && alias != recipe.Slug && !slices.Contains(recipe.Aliases, alias) {
+			recipe.Aliases = append(recipe.Aliases, alias)
+		}
+	}
+}
+
+// checkNewID refuses the ID of a new recipe when it is the slug or an alias
+// of another recipe, which would then no longer be found by it. The caller
+// must hold the lock.
+func (r *jsonRepository) checkNewID(id string) error {
+	owner, ok := r.slugs[id]
+	if !ok {
+		owner, ok = r.aliases[id]
+	}
+	if ok && owner != id {
+		return domain.ValidationErrors{{Path: "id", Message: fmt.Sprintf("recipe ID %q is a slug of recipe %s", id, owner)}}
+	}
+	return nil
+}
+
+// freeSlug returns slug, or slug-2, slug-3 and so on, whichever is neither
+// the slug nor the ID of another recipe. The caller must hold the lock.
+func (r *jsonRepository) freeSlug(slug, id string) string {
+	if slug == "" {
+		if slug = domain.Slugify(id); slug == "" {
+			slug = "recipe"
+		}
+	}
+	candidate := slug
+	for i := 2; ; i++ {
+		owner, taken := r.slugs[candidate]
+		_, isID := r.recipes[candidate]
+		if (!taken || owner == id) && (!isID || candidate == id) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+func TestNewJSONRepository_Slugs(t *testing.T) {
+	dir := writeRecipeFiles(t, map[string]string{
+		"a.json": `{"id": "1", "name": "Crème brûlée"}`,
+		"b.json": `{"id": "2", "name": "Crème Brûlée!"}`,
+		"c.json": `{"id": "3", "name": "Tarte", "slug": "creme-brulee", "aliases": ["old-tarte"]}`,
+	})
+	repo, err := NewJSONRepository(dir)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+
+	// Explicit slugs win over derived ones, which are made unique in file order.
+	for slug, want := range map[string]string{"creme-brulee": "3", "creme-brulee-2": "1", "creme-brulee-3": "2"} {
+		if id, alias, err := repo.ResolveSlug(ctx, slug); err != nil || id != want || alias {
+			t.Errorf("expected %s to be recipe %s, got %s, %v, %v", slug, want, id, alias, err)
+		}
+	}
+	if id, alias, err := repo.ResolveSlug(ctx, "old-tarte"); err != nil || id != "3" || !alias {
+		t.Errorf("expected old-tarte to be an alias of recipe 3, got %s, %v, %v", id, alias, err)
+	}
+	if _, _, err := repo.ResolveSlug(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	dup := writeRecipeFiles(t, map[string]string{
+		"a.json": `{"id": "1", "name": "Cake", "slug": "cake"}`,
+		"b.json": `{"id": "2", "name": "Pie", "slug": "cake"}`,
+	})
+	if _, err := NewJSONRepository(dup); err == nil || !strings.Contains(err.Error(), `slug "cake" is used by both`) {
+		t.Errorf("expected duplicate slugs to fail loading, got %v", err)
+	}
+}
+
+func TestJSONRepository_SaveSlugs(t *testing.T) {
+	repo, err := NewJSONRepository(writeRecipeFiles(t, map[string]string{
+		"a.json": `{"id": "1", "name": "Pancakes"}`,
+	}))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	ctx := context.Background()
+	save := func(id, name, slug string) ([]string, string) {
+		t.Helper()
+		recipe, err := repo.FindByID(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			recipe, err = &domain.Recipe{ID: id}, nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipe.Name, recipe.Slug = name, slug
+		revision, err := repo.Save(ctx, *recipe, "alice")
+		if err != nil {
+			t.Fatalf("failed to save: %v", err)
+		}
+		return revision.Recipe.Aliases, revision.Recipe.Slug
+	}
+
+	if aliases, slug := save("2", "Pancakes", ""); slug != "pancakes-2" || aliases != nil {
+		t.Errorf("expected a new recipe to get a free slug, got %s, %v", slug, aliases)
+	}
+	if aliases, slug := save("1", "Pancakes", "pancakes"); slug != "pancakes" || aliases != nil {
+		t.Errorf("expected an unchanged recipe to keep its slug, got %s, %v", slug, aliases)
+	}
+	if aliases, slug := save("1", "Crêpes", "pancakes"); slug != "crepes" || !reflect.DeepEqual(aliases, []string{"pancakes"}) {
+		t.Errorf("expected a renamed recipe to keep its slug as an alias, got %s, %v", slug, aliases)
+	}
+	if id, alias, _ := repo.ResolveSlug(ctx, "pancakes"); id != "1" || !alias {
+		t.Errorf("expected pancakes to redirect to recipe 1, got %s, %v", id, alias)
+	}
+	if aliases, slug := save("1", "Crêpes", "thin-pancakes"); slug != "thin-pancakes" || !reflect.DeepEqual(aliases, []string{"pancakes", "crepes"}) {
+		t.Errorf("expected the chosen slug, got %s, %v", slug, aliases)
+	}
+	if aliases, slug := save("1", "Pancakes", "thin-pancakes"); slug != "pancakes" || !reflect.DeepEqual(aliases, []string{"crepes", "thin-pancakes"}) {
+		t.Errorf("expected renaming back to reuse the alias, got %s, %v", slug, aliases)
+	}
+	// A slug may not shadow the ID of another recipe.
+	if _, slug := save("3", "2", ""); slug != "2-2" {
+		t.Errorf("expected IDs to be avoided, got %s", slug)
+	}
+
+	// Nor may a new recipe take the slug or an alias of another one.
+	for _, id := range []string{"pancakes", "crepes"} {
+		var validationErrs domain.ValidationErrors
+		if _, err := repo.Save(ctx, domain.Recipe{ID: id, Name: "Waffles"}, "alice"); !errors.As(err, &validationErrs) {
+			t.Errorf("expected the ID %s to be refused, got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+// sqliteSchema creates the account tables. Times are stored as RFC 3339
+// text, and the recipe IDs of a collection and the adjustments of a review
+// as JSON arrays.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS households (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS users (
+	id            TEXT PRIMARY KEY,
+	username      TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	household_id  TEXT NOT NULL DEFAULT '',
+	created_at    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sessions (
+	token_hash TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	expires_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS recipe_access (
+	recipe_id    TEXT PRIMARY KEY,
+	owner_id     TEXT NOT NULL,
+	household_id TEXT NOT NULL DEFAULT '',
+	visibility   TEXT NOT NULL,
+	updated_at   TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS favorites (
+	user_id   TEXT NOT NULL,
+	recipe_id TEXT NOT NULL,
+	position  INTEGER NOT NULL,
+	PRIMARY KEY (user_id, recipe_id)
+);
+CREATE TABLE IF NOT EXISTS collections (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	name       TEXT NOT NULL,
+	recipe_ids TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS reviews (
+	id          TEXT PRIMARY KEY,
+	recipe_id   TEXT NOT NULL,
+	user_id     TEXT NOT NULL DEFAULT '',
+	author      TEXT NOT NULL,
+	rating      INTEGER NOT NULL DEFAULT 0,
+	notes       TEXT NOT NULL DEFAULT '',
+	cooked_on   TEXT NOT NULL DEFAULT '',
+	servings    INTEGER NOT NULL DEFAULT 0,
+	adjustments TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS reviews_recipe_id ON reviews (recipe_id);
+`
+
+type sqliteAccountRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAccountRepository opens, and creates if needed, an account
+// repository in the SQLite database file at path.
+func NewSQLiteAccountRepository(path string) (AccountRepository, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open accounts database %s: %w", path, err)
+	}
+	// SQLite serializes writes anyway; a single connection avoids busy errors.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create accounts schema in %s: %w", path, err)
+	}
+	return &sqliteAccountRepository{db: db}, nil
+}
+
+// sqliteTimeFormat is RFC 3339 with a fixed number of fractional digits,
+// so that stored times sort as text.
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func (r *sqliteAccountRepository) CreateUser(ctx context.Context, user domain.User) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var taken bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE username = ?)`, user.Username).Scan(&taken); err != nil {
+			return err
+		}
+		if taken {
+			return ErrUsernameTaken
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO users (id, username, password_hash, household_id, created_at) VALUES (?, ?, ?, ?, ?)`,
+			user.ID, user.Username, user.PasswordHash, user.HouseholdID, formatTime(user.CreatedAt))
+		return err
+	})
+}
+
+func (r *sqliteAccountRepository) UpdateUser(ctx context.Context, user domain.User) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var taken bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE username = ? AND id <> ?)`, user.Username, user.ID).Scan(&taken); err != nil {
+			return err
+		}
+		if taken {
+			return ErrUsernameTaken
+		}
+		result, err := tx.ExecContext(ctx,
+			`UPDATE users SET username = ?, password_hash = ?, household_id = ? WHERE id = ?`,
+			user.Username, user.PasswordHash, user.HouseholdID, user.ID)
+		if err != nil {
+			return err
+		}
+		return requireRow(result, ErrUserNotFound)
+	})
+}
+
+const userColumns = `id, username, password_hash, household_id, created_at`
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var (
+		user      domain.User
+		createdAt string
+	)
+	if err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.HouseholdID, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	user.CreatedAt, err = parseTime(createdAt)
+	return &user, err
+}
+
+func (r *sqliteAccountRepository) FindUser(ctx context.Context, id string) (*domain.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	return user, notFound(err, ErrUserNotFound)
+}
+
+func (r *sqliteAccountRepository) FindUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE username = ?`, username))
+	return user, notFound(err, ErrUserNotFound)
+}
+
+func (r *sqliteAccountRepository) CreateHousehold(ctx context.Context, household domain.Household) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO households (id, name, created_at) VALUES (?, ?, ?)`,
+		household.ID, household.Name, formatTime(household.CreatedAt))
+	return wrapSQLite(err)
+}
+
+func (r *sqliteAccountRepository) FindHousehold(ctx context.Context, id string) (*domain.Household, error) {
+	var (
+		household domain.Household
+		createdAt string
+	)
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, created_at FROM households WHERE id = ?`, id).
+		Scan(&household.ID, &household.Name, &createdAt)
+	if err != nil {
+		return nil, notFound(err, ErrHouseholdNotFound)
+	}
+	household.CreatedAt, err = parseTime(createdAt)
+	return &household, wrapSQLite(err)
+}
+
+func (r *sqliteAccountRepository) ListHouseholdMembers(ctx context.Context, householdID string) ([]domain.User, error) {
+	if _, err := r.FindHousehold(ctx, householdID); err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users WHERE household_id = ? ORDER BY username`, householdID)
+	if err != nil {
+		return nil, wrapSQLite(err)
+	}
+	defer rows.Close()
+
+	members := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, wrapSQLite(err)
+		}
+		members = append(members, *user)
+	}
+	return members, wrapSQLite(rows.Err())
+}
+
+func (r *sqliteAccountRepository) CreateSession(ctx context.Context, session domain.Session) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		// Drop expired sessions, so that the table does not grow forever.
+		if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at <= ?`, formatTime(session.CreatedAt)); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO sessions (token_hash, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)`,
+			session.TokenHash, session.UserID, formatTime(session.CreatedAt), formatTime(session.ExpiresAt))
+		return err
+	})
+}
+
+func (r *sqliteAccountRepository) FindSession(ctx context.Context, tokenHash string, now time.Time) (*domain.Session, error) {
+	var (
+		session              domain.Session
+		createdAt, expiresAt string
+	)
+	err := r.db.QueryRowContext(ctx, `SELECT token_hash, user_id, created_at, expires_at FROM sessions WHERE token_hash = ?`, tokenHash).
+		Scan(&session.TokenHash, &session.UserID, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, notFound(err, ErrSessionNotFound)
+	}
+	if session.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, wrapSQLite(err)
+	}
+	if session.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, wrapSQLite(err)
+	}
+	if !session.ExpiresAt.After(now) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *sqliteAccountRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE token_hash = ?`, tokenHash)
+	if err != nil {
+		return wrapSQLite(err)
+	}
+	return requireRow(result, ErrSessionNotFound)
+}
+
+// selectRecipeAccess reads recipe access with the current household of the
+// owner; the household_id column of recipe_access is no longer written.
+const selectRecipeAccess = `
+	SELECT a.recipe_id, a.owner_id, COALESCE(u.household_id, ''), a.visibility, a.updated_at
+	FROM recipe_access a LEFT JOIN users u ON u.id = a.owner_id`
+
+func scanRecipeAccess(row rowScanner) (domain.RecipeAccess, error) {
+	var (
+		access     domain.RecipeAccess
+		visibility string
+		updatedAt  string
+	)
+	if err := row.Scan(&access.RecipeID, &access.OwnerID, &access.HouseholdID, &visibility, &updatedAt); err != nil {
+		return access, err
+	}
+	access.Visibility = domain.Visibility(visibility)
+	var err error
+	access.UpdatedAt, err = parseTime(updatedAt)
+	return access, err
+}
+
+func (r *sqliteAccountRepository) RecipeAccess(ctx context.Context, recipeID string) (domain.RecipeAccess, error) {
+	access, err := scanRecipeAccess(r.db.QueryRowContext(ctx, selectRecipeAccess+` WHERE a.recipe_id = ?`, recipeID))
+	return access, notFound(err, ErrNotFound)
+}
+
+func (r *sqliteAccountRepository) ListRecipeAccess(ctx context.Context) (map[string]domain.RecipeAccess, error) {
+	rows, err := r.db.QueryContext(ctx, selectRecipeAccess)
+	if err != nil {
+		return nil, wrapSQLite(err)
+	}
+	defer rows.Close()
+
+	accesses := make(map[string]domain.RecipeAccess)
+	for rows.Next() {
+		access, err := scanRecipeAccess(rows)
+		if err != nil {
+			return nil, wrapSQLite(err)
+		}
+		accesses[access.RecipeID] = access
+	}
+	return accesses, wrapSQLite(rows.Err())
+}
+
+func (r *sqliteAccountRepository) SaveRecipeAccess(ctx context.Context, access domain.RecipeAccess) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO recipe_access (recipe_id, owner_id, visibility, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (recipe_id) DO UPDATE SET
+			owner_id = excluded.owner_id, visibility = excluded.visibility, updated_at = excluded.updated_at`,
+		access.RecipeID, access.OwnerID, string(access.Visibility), formatTime(access.UpdatedAt))
+	return wrapSQLite(err)
+}
+
+func (r *sqliteAccountRepository) ListFavorites(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT recipe_id FROM favorites WHERE user_id = ? ORDER BY position`, userID)
+	if err != nil {
+		return nil, wrapSQLite(err)
+	}
+	defer rows.Close()
+
+	recipeIDs := []string{}
+	for rows.Next() {
+		var recipeID string
+		if err := rows.Scan(&recipeID); err != nil {
+			return nil, wrapSQLite(err)
+		}
+		recipeIDs = append(recipeIDs, recipeID)
+	}
+	return recipeIDs, wrapSQLite(rows.Err())
+}
+
+func (r *sqliteAccountRepository) SetFavorite(ctx context.Context, userID, recipeID string, favorite bool) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM favorites WHERE user_id = ? AND recipe_id = ?`, userID, recipeID); err != nil {
+			return err
+		}
+		if !favorite {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO favorites (user_id, recipe_id, position)
+			SELECT ?, ?, COALESCE(MAX(position), 0) + 1 FROM favorites WHERE user_id = ?`,
+			userID, recipeID, userID)
+		return err
+	})
+}
+
+const collectionColumns = `id, user_id, name, recipe_ids, created_at, updated_at`
+
+func scanCollection(row rowScanner) (*domain.Collection, error) {
+	var (
+		collection                      domain.Collection
+		recipeIDs, createdAt, updatedAt string
+	)
+	if err := row.Scan(&collection.ID, &collection.UserID, &collection.Name, &recipeIDs, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(recipeIDs), &collection.RecipeIDs); err != nil {
+		return nil, err
+	}
+	var err error
+	if collection.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	collection.UpdatedAt, err = parseTime(updatedAt)
+	return &collection, err
+}
+
+func (r *sqliteAccountRepository) ListCollections(ctx context.Context, userID string) ([]domain.Collection, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+collectionColumns+` FROM collections WHERE user_id = ? ORDER BY name`, userID)
+	if err != nil {
+		return nil, wrapSQLite(err)
+	}
+	defer rows.Close()
+
+	collections := []domain.Collection{}
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, wrapSQLite(err)
+		}
+		collections = append(collections, *collection)
+	}
+	return collections, wrapSQLite(rows.Err())
+}
+
+func (r *sqliteAccountRepository) FindCollection(ctx context.Context, userID, id string) (*domain.Collection, error) {
+	collection, err := scanCollection(r.db.QueryRowContext(ctx,
+		`SELECT `+collectionColumns+` FROM collections WHERE id = ? AND user_id = ?`, id, userID))
+	return collection, notFound(err, ErrCollectionNotFound)
+}
+
+func (r *sqliteAccountRepository) SaveCollection(ctx context.Context, collection domain.Collection) error {
+	recipeIDs, err := json.Marshal(append([]string{}, collection.RecipeIDs...))
+	if err != nil {
+		return err
+	}
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		var owner string
+		err := tx.QueryRowContext(ctx, `SELECT user_id FROM collections WHERE id = ?`, collection.ID).Scan(&owner)
+		if err == nil && owner != collection.UserID {
+			return ErrCollectionNotFound
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO collections (`+collectionColumns+`) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				name = excluded.name, recipe_ids = excluded.recipe_ids, updated_at = excluded.updated_at`,
+			collection.ID, collection.UserID, collection.Name, string(recipeIDs),
+			formatTime(collection.CreatedAt), formatTime(collection.UpdatedAt))
+		return err
+	})
+}
+
+func (r *sqliteAccountRepository) DeleteCollection(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM collections WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return wrapSQLite(err)
+	}
+	return requireRow(result, ErrCollectionNotFound)
+}
+
+// inTx runs fn in a transaction, committed if fn succeeds.
+func (r *sqliteAccountRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapSQLite(err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return wrapSQLite(err)
+	}
+	return wrapSQLite(tx.Commit())
+}
+
+// requireRow returns notFoundErr if a statement changed no row.
+func requireRow(result sql.Result, notFoundErr error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return wrapSQLite(err)
+	}
+	if n == 0 {
+		return notFoundErr
+	}
+	return nil
+}
+
+// notFound maps sql.ErrNoRows to the repository error for the missing entity.
+func notFound(err, notFoundErr error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundErr
+	}
+	return wrapSQLite(err)
+}
+
+// wrapSQLite adds context to database errors, and passes through nil, the
+// repository errors and context errors so that callers can test for them.
+func wrapSQLite(err error) error {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrUserNotFound), errors.Is(err, ErrUsernameTaken),
+		errors.Is(err, ErrHouseholdNotFound), errors.Is(err, ErrSessionNotFound), errors.Is(err, ErrCollectionNotFound),
+		errors.Is(err, ErrReviewNotFound):
+		return err
+	}
+	return fmt.Errorf("accounts database: %w", err)
+}
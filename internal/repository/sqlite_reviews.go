@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+)
+
+const reviewColumns = `id, recipe_id, user_id, author, rating, notes, cooked_on, servings, adjustments, created_at, updated_at`
+
+func scanReview(row rowScanner) (*domain.Review, error) {
+	var (
+		review                            domain.Review
+		adjustments, createdAt, updatedAt string
+	)
+	if err := row.Scan(&review.ID, &review.RecipeID, &review.UserID, &review.Author, &review.Rating, &review.Notes,
+		&review.CookedOn, &review.Servings, &adjustments, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(adjustments), &review.Adjustments); err != nil {
+		return nil, err
+	}
+	var err error
+	if review.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	review.UpdatedAt, err = parseTime(updatedAt)
+	return &review, err
+}
+
+func (r *sqliteAccountRepository) ListReviews(ctx context.Context, recipeID string) ([]domain.Review, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+reviewColumns+` FROM reviews WHERE recipe_id = ? ORDER BY created_at DESC, id`, recipeID)
+	if err != nil {
+		return nil, wrapSQLite(err)
+	}
+	defer rows.Close()
+
+	reviews := []domain.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, wrapSQLite(err)
+		}
+		reviews = append(reviews, *review)
+	}
+	return reviews, wrapSQLite(rows.Err())
+}
+
+func (r *sqliteAccountRepository) FindReview(ctx context.Context, recipeID, id string) (*domain.Review, error) {
+	review, err := scanReview(r.db.QueryRowContext(ctx,
+		`SELECT `+reviewColumns+` FROM reviews WHERE id = ? AND recipe_id = ?`, id, recipeID))
+	return review, notFound(err, ErrReviewNotFound)
+}
+
+func (r *sqliteAccountRepository) SaveReview(ctx context.Context, review domain.Review) error {
+	adjustments, err := json.Marshal(review.Adjustments)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO reviews (`+reviewColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			rating = excluded.rating, notes = excluded.notes, cooked_on = excluded.cooked_on,
+			servings = excluded.servings, adjustments = excluded.adjustments, updated_at = excluded.updated_at`,
+		review.ID, review.RecipeID, review.UserID, review.Author, review.Rating, review.Notes,
+		review.CookedOn, review.Servings, string(adjustments),
+		formatTime(review.CreatedAt), formatTime(review.UpdatedAt))
+	return wrapSQLite(err)
+}
+
+func (r *sqliteAccountRepository) DeleteReview(ctx context.Context, recipeID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reviews WHERE id = ? AND recipe_id = ?`, id, recipeID)
+	if err != nil {
+		return wrapSQLite(err)
+	}
+	return requireRow(result, ErrReviewNotFound)
+}
+
+func (r *sqliteAccountRepository) ReviewStats(ctx context.Context) (map[string]domain.RecipeStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT recipe_id,
+			COALESCE(AVG(NULLIF(rating, 0)), 0),
+			COUNT(NULLIF(rating, 0)),
+			COUNT(NULLIF(cooked_on, '')),
+			COALESCE(MAX(NULLIF(cooked_on, '')), ''),
+			MAX(updated_at)
+		FROM reviews GROUP BY recipe_id`)
+	if err != nil {
+		return nil, wrapSQLite(err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]domain.RecipeStats)
+	for rows.Next() {
+		var (
+			recipeID, updatedAt string
+			s                   domain.RecipeStats
+		)
+		if err := rows.Scan(&recipeID, &s.AverageRating, &s.RatingCount, &s.TimesCooked, &s.LastCooked, &updatedAt); err != nil {
+			return nil, wrapSQLite(err)
+		}
+		if s.UpdatedAt, err = parseTime(updatedAt); err != nil {
+			return nil, wrapSQLite(err)
+		}
+		stats[recipeID] = s
+	}
+	return stats, wrapSQLite(rows.Err())
+}
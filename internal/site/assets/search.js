@@ -0,0 +1,41 @@
+// Filters the recipes of the index page with the words typed in the search
+// box, matched against the names, ingredients and tags in search.json.
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var recipes = document.getElementById("recipes");
+  if (!input || !window.fetch) {
+    return;
+  }
+
+  function normalize(s) {
+    return s.normalize("NFD").replace(/[\u0300-\u036f]/g, "").toLowerCase();
+  }
+
+  fetch("search.json")
+    .then(function (response) { return response.json(); })
+    .then(function (index) {
+      index.forEach(function (entry) {
+        entry.text = normalize([entry.name].concat(entry.ingredients, entry.tags).join(" "));
+      });
+      input.hidden = false;
+      input.addEventListener("input", function () {
+        var words = normalize(input.value).split(/\s+/).filter(Boolean);
+        recipes.hidden = words.length > 0;
+        results.hidden = words.length === 0;
+        results.textContent = "";
+        index
+          .filter(function (entry) {
+            return words.every(function (word) { return entry.text.indexOf(word) >= 0; });
+          })
+          .forEach(function (entry) {
+            var item = document.createElement("li");
+            var link = document.createElement("a");
+            link.href = entry.url;
+            link.textContent = entry.name;
+            item.appendChild(link);
+            results.appendChild(item);
+          });
+      });
+    });
+})();
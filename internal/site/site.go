@@ -0,0 +1,409 @@
+// Package site renders recipes as a static website: a page per recipe with
+// its schema.org JSON-LD, index pages of ingredients and tags, the
+// illustrations and a search index read by a small script. The result can be
+// served by any web server, without the recipe manager.
+package site
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/jsonld"
+	"github.com/fromenjn/recipe-manager/internal/render"
+)
+
+// SearchIndex is the name of the search index file, at the root of the site.
+const SearchIndex = "search.json"
+
+// imagePrefix is the path of illustrations in recipes, and imageDir where
+// they are copied in the site.
+const (
+	imagePrefix = "/images/"
+	imageDir    = "images"
+)
+
+//go:embed templates assets
+var files embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"amount": render.Amount,
+}).ParseFS(files, "templates/*.html"))
+
+// Options configure the generated site.
+type Options struct {
+	// Title is the name of the site, shown on every page.
+	Title string
+	// BaseURL is the absolute URL the site is published at, e.g.
+	// https://recipes.example.com/. The JSON-LD of recipes then links to
+	// their pages and illustrations with absolute URLs, as search engines
+	// prefer; otherwise the links are relative.
+	BaseURL string
+	// Images loads illustrations by their path in recipes. Nil leaves them out.
+	Images render.Images
+}
+
+// Stats counts what was written.
+type Stats struct {
+	Recipes int
+	Images  int
+	// MissingImages counts the illustrations that could not be loaded,
+	// and are left out of the pages.
+	MissingImages int
+}
+
+// Build writes the site of recipes to dir, creating it if needed. Existing
+// files with the names of generated files are replaced; other files are
+// left alone.
+func Build(dir string, recipes []domain.Recipe, opts Options) (Stats, error) {
+	b := &builder{
+		dir:     dir,
+		opts:    opts,
+		copied:  make(map[string]bool),
+		baseURL: strings.TrimSuffix(opts.BaseURL, "/") + "/",
+	}
+	if opts.BaseURL == "" {
+		b.baseURL = ""
+	}
+
+	recipes = slices.Clone(recipes)
+	slices.SortFunc(recipes, func(a, b domain.Recipe) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	b.pages = pageNames(recipes)
+
+	if err := b.assets(); err != nil {
+		return b.stats, err
+	}
+	for _, recipe := range recipes {
+		if err := b.recipe(recipe); err != nil {
+			return b.stats, err
+		}
+	}
+	if err := b.indexes(recipes); err != nil {
+		return b.stats, err
+	}
+	return b.stats, nil
+}
+
+type builder struct {
+	dir     string
+	opts    Options
+	baseURL string
+	// pages maps recipe IDs to the paths of their pages, e.g. recipes/2.html.
+	pages map[string]string
+	// copied tells whether each illustration path was copied.
+	copied map[string]bool
+	stats  Stats
+}
+
+// layout is what every page shows.
+type layout struct {
+	Site  string
+	Title string
+	// Root is the relative path from the page to the root of the site.
+	Root   string
+	JSONLD *jsonld.Recipe
+}
+
+// link is a named link relative to the page it is on.
+type link struct {
+	Name string
+	Href string
+}
+
+type recipeEntry struct {
+	link
+	Yield string
+	Tags  []link
+}
+
+type recipePage struct {
+	layout
+	Recipe domain.Recipe
+	Yield  string
+	Tags   []link
+	Steps  []stepView
+}
+
+type stepView struct {
+	Name          string
+	Paragraphs    []string
+	Illustrations []illustrationView
+}
+
+type illustrationView struct {
+	Src         string
+	WebP        string
+	Description string
+}
+
+type indexPage struct {
+	layout
+	Recipes []recipeEntry
+}
+
+type termsPage struct {
+	layout
+	Terms []term
+}
+
+// term is an entry of the ingredient or tag index.
+type term struct {
+	Name    string
+	Anchor  string
+	Recipes []link
+}
+
+// searchEntry is a recipe in the search index.
+type searchEntry struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Ingredients []string `json:"ingredients"`
+	Tags        []string `json:"tags"`
+}
+
+var unsafePageChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// pageNames gives each recipe a page named after its ID, made unique.
+func pageNames(recipes []domain.Recipe) map[string]string {
+	pages := make(map[string]string, len(recipes))
+	used := make(map[string]bool, len(recipes))
+	for _, recipe := range recipes {
+		base := strings.ToLower(unsafePageChars.ReplaceAllString(recipe.ID, "_"))
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[name] = true
+		pages[recipe.ID] = "recipes/" + name + ".html"
+	}
+	return pages
+}
+
+func (b *builder) assets() error {
+	assets, err := fs.Sub(files, "assets")
+	if err != nil {
+		return err
+	}
+	return fs.WalkDir(assets, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+		return b.write(name, data)
+	})
+}
+
+func (b *builder) recipe(recipe domain.Recipe) error {
+	page := recipePage{
+		layout: layout{Site: b.opts.Title, Title: recipe.Name, Root: "../"},
+		Recipe: recipe,
+		Yield:  render.Yield(recipe),
+		Tags:   tagLinks(recipe.Tags, "../"),
+	}
+	for _, step := range recipe.Steps {
+		view := stepView{Name: step.Name}
+		for _, paragraph := range strings.Split(step.Instructions, "\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				view.Paragraphs = append(view.Paragraphs, paragraph)
+			}
+		}
+		for _, illustration := range step.RecipeIllustration {
+			if !b.copy(illustration.Filepath) {
+				continue
+			}
+			image := illustrationView{Src: "../" + b.imagePath(illustration.Filepath), Description: illustration.Description}
+			if webp := illustration.Variants[domain.VariantWebP]; webp != "" && b.copy(webp) {
+				image.WebP = "../" + b.imagePath(webp)
+			}
+			view.Illustrations = append(view.Illustrations, image)
+		}
+		page.Steps = append(page.Steps, view)
+	}
+
+	doc := jsonld.FromRecipe(recipe, jsonld.Links{
+		Recipe: func(recipe domain.Recipe) string { return b.url(b.pages[recipe.ID], "../") },
+		Image: func(path string) string {
+			if !b.copy(path) {
+				return ""
+			}
+			return b.url(b.imagePath(path), "../")
+		},
+	})
+	page.JSONLD = &doc
+
+	if err := b.render(b.pages[recipe.ID], "recipe.html", page); err != nil {
+		return err
+	}
+	b.stats.Recipes++
+	return nil
+}
+
+// indexes writes the list of recipes, the ingredient and tag indexes, and
+// the search index.
+func (b *builder) indexes(recipes []domain.Recipe) error {
+	index := indexPage{layout: layout{Site: b.opts.Title}}
+	ingredients := make(map[string]*term)
+	tags := make(map[string]*term)
+	search := make([]searchEntry, 0, len(recipes))
+	for _, recipe := range recipes {
+		page := link{Name: recipe.Name, Href: b.pages[recipe.ID]}
+		index.Recipes = append(index.Recipes, recipeEntry{link: page, Yield: render.Yield(recipe), Tags: tagLinks(recipe.Tags, "")})
+
+		entry := searchEntry{Name: recipe.Name, URL: page.Href, Ingredients: []string{}, Tags: []string{}}
+		for _, ingredient := range recipe.Ingredients {
+			addTerm(ingredients, ingredient.Name, page)
+			entry.Ingredients = append(entry.Ingredients, ingredient.Name)
+		}
+		for _, tag := range recipe.Tags {
+			addTerm(tags, tag, page)
+			entry.Tags = append(entry.Tags, tag)
+		}
+		search = append(search, entry)
+	}
+
+	if err := b.render("index.html", "index.html", index); err != nil {
+		return err
+	}
+	if err := b.render("ingredients.html", "terms.html", termsPage{layout: layout{Site: b.opts.Title, Title: "Ingredients"}, Terms: sortedTerms(ingredients)}); err != nil {
+		return err
+	}
+	if err := b.render("tags.html", "terms.html", termsPage{layout: layout{Site: b.opts.Title, Title: "Tags"}, Terms: sortedTerms(tags)}); err != nil {
+		return err
+	}
+	data, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+	return b.write(SearchIndex, data)
+}
+
+// addTerm adds a recipe to the index entry of name, compared
+// case-insensitively.
+func addTerm(terms map[string]*term, name string, recipe link) {
+	name = strings.TrimSpace(name)
+	key := strings.ToLower(name)
+	t, ok := terms[key]
+	if !ok {
+		t = &term{Name: name, Anchor: anchor(name)}
+		terms[key] = t
+	}
+	if !slices.Contains(t.Recipes, recipe) {
+		t.Recipes = append(t.Recipes, recipe)
+	}
+}
+
+func sortedTerms(terms map[string]*term) []term {
+	sorted := make([]term, 0, len(terms))
+	for _, t := range terms {
+		sorted = append(sorted, *t)
+	}
+	slices.SortFunc(sorted, func(a, b term) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return sorted
+}
+
+func tagLinks(tags []string, root string) []link {
+	links := make([]link, len(tags))
+	for i, tag := range tags {
+		links[i] = link{Name: tag, Href: root + "tags.html#" + anchor(tag)}
+	}
+	return links
+}
+
+// anchor turns a name into a fragment identifier, e.g. "Main course" into
+// "main-course".
+func anchor(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// imagePath returns where an illustration is copied in the site, relative
+// to its root, e.g. images/chocolate_cake/step1.jpg.
+func (b *builder) imagePath(filepath string) string {
+	return imageDir + "/" + strings.TrimPrefix(filepath, imagePrefix)
+}
+
+// url returns the URL of a file of the site in JSON-LD, absolute when the
+// site has a base URL, and otherwise relative to a page at root.
+func (b *builder) url(name, root string) string {
+	if b.baseURL != "" {
+		return b.baseURL + name
+	}
+	return root + name
+}
+
+// copy copies an illustration into the site once, and tells whether it could.
+func (b *builder) copy(filepath string) bool {
+	if copied, ok := b.copied[filepath]; ok {
+		return copied
+	}
+	b.copied[filepath] = false
+	name, ok := strings.CutPrefix(filepath, imagePrefix)
+	if !ok || !fs.ValidPath(name) || b.opts.Images == nil {
+		b.stats.MissingImages++
+		return false
+	}
+	data, err := b.opts.Images(filepath)
+	if err != nil {
+		b.stats.MissingImages++
+		return false
+	}
+	if err := b.write(path.Join(imageDir, name), data); err != nil {
+		b.stats.MissingImages++
+		return false
+	}
+	b.copied[filepath] = true
+	b.stats.Images++
+	return true
+}
+
+func (b *builder) render(name, tmpl string, data any) error {
+	var out strings.Builder
+	if err := templates.ExecuteTemplate(&out, tmpl, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return b.write(name, []byte(out.String()))
+}
+
+// write writes a file of the site, by its slash-separated name.
+func (b *builder) write(name string, data []byte) error {
+	path := filepath.Join(b.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
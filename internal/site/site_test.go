@@ -0,0 +1,111 @@
+package site
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/jsonld"
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	recipes := []domain.Recipe{
+		{
+			ID: "2", Name: "Chocolate Cake", Servings: 8, Tags: []string{"Dessert"},
+			Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 200, Unit: "g"}},
+			Steps: []domain.RecipeStep{{ID: "s1", Name: "Bake", Instructions: "Bake <for> 30 minutes.", RecipeIllustration: []domain.RecipeIllustration{
+				{ID: "i1", Description: "Golden", Filepath: "/images/2/s1.jpg", Variants: map[string]string{domain.VariantWebP: "/images/2/s1.webp"}},
+				{ID: "i2", Filepath: "/images/2/missing.jpg"},
+			}}},
+		},
+		{ID: "a b", Name: "apple pie", Tags: []string{"dessert"}, Ingredients: []domain.Ingredient{{Name: "flour", Quantity: 250, Unit: "g"}}},
+	}
+	images := func(path string) ([]byte, error) {
+		if path == "/images/2/missing.jpg" {
+			return nil, errors.New("not found")
+		}
+		return []byte("image " + path), nil
+	}
+
+	stats, err := Build(dir, recipes, Options{Title: "Family", BaseURL: "https://recipes.example.com", Images: images})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats != (Stats{Recipes: 2, Images: 2, MissingImages: 1}) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", name, err)
+		}
+		return string(data)
+	}
+	if got := read("images/2/s1.webp"); got != "image /images/2/s1.webp" {
+		t.Errorf("unexpected copy of the WebP variant %q", got)
+	}
+	read("style.css")
+	read("search.js")
+
+	page := read("recipes/2.html")
+	for _, want := range []string{
+		`<title>Chocolate Cake · Family</title>`,
+		`<p class="yield">Serves 8</p>`,
+		`<a class="tag" href="../tags.html#dessert">Dessert</a>`,
+		`<li><strong>200 g</strong> Flour</li>`,
+		`<p>Bake &lt;for&gt; 30 minutes.</p>`,
+		`<source srcset="../images/2/s1.webp" type="image/webp"><img src="../images/2/s1.jpg" alt="Golden" loading="lazy">`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected the recipe page to contain %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "missing.jpg") {
+		t.Error("expected missing illustrations to be left out")
+	}
+
+	match := regexp.MustCompile(`<script type="application/ld\+json">(.*)</script>`).FindStringSubmatch(page)
+	if match == nil {
+		t.Fatal("expected JSON-LD in the recipe page")
+	}
+	var doc jsonld.Recipe
+	if err := json.Unmarshal([]byte(match[1]), &doc); err != nil {
+		t.Fatalf("invalid JSON-LD: %v", err)
+	}
+	if doc.URL != "https://recipes.example.com/recipes/2.html" || len(doc.Image) != 1 || doc.Image[0] != "https://recipes.example.com/images/2/s1.jpg" {
+		t.Errorf("unexpected links in JSON-LD %+v", doc)
+	}
+
+	// Terms are merged case-insensitively and recipes sorted by name.
+	if tags := read("tags.html"); strings.Count(tags, "<section") != 1 || !strings.Contains(tags, `<li><a href="recipes/a_b.html">apple pie</a></li>`) {
+		t.Errorf("unexpected tag index:\n%s", tags)
+	}
+	index := read("index.html")
+	if strings.Index(index, "apple pie") > strings.Index(index, "Chocolate Cake") {
+		t.Errorf("expected recipes sorted by name:\n%s", index)
+	}
+
+	var search []searchEntry
+	if err := json.Unmarshal([]byte(read(SearchIndex)), &search); err != nil {
+		t.Fatalf("invalid search index: %v", err)
+	}
+	if len(search) != 2 || search[1].URL != "recipes/2.html" || search[1].Ingredients[0] != "Flour" {
+		t.Errorf("unexpected search index %+v", search)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	for name, want := range map[string]string{"Main course": "main-course", " Crème brûlée!": "crème-brûlée", "?": "_"} {
+		if got := anchor(name); got != want {
+			t.Errorf("anchor(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
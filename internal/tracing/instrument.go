@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+)
+
+var tracer = otel.Tracer("github.com/fromenjn/recipe-manager/internal/tracing")
+
+// InstrumentRepository records a span for every call to repo.
+func InstrumentRepository(repo repository.RecipeRepository) repository.RecipeRepository {
+	return &tracedRepository{next: repo}
+}
+
+type tracedRepository struct {
+	next repository.RecipeRepository
+}
+
+func (r *tracedRepository) FindByID(ctx context.Context, id string) (_ *domain.Recipe, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.FindByID", trace.WithAttributes(attribute.String("recipe.id", id)))
+	defer func() { End(span, err) }()
+	return r.next.FindByID(ctx, id)
+}
+
+func (r *tracedRepository) ListAll(ctx context.Context) (_ []domain.Recipe, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.ListAll")
+	defer func() { End(span, err) }()
+	recipes, err := r.next.ListAll(ctx)
+	span.SetAttributes(attribute.Int("recipe.count", len(recipes)))
+	return recipes, err
+}
+
+func (r *tracedRepository) ResolveSlug(ctx context.Context, slug string) (_ string, _ bool, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.ResolveSlug", trace.WithAttributes(attribute.String("recipe.slug", slug)))
+	defer func() { End(span, err) }()
+	return r.next.ResolveSlug(ctx, slug)
+}
+
+func (r *tracedRepository) RecipeVersion(ctx context.Context, id string) (_ repository.Version, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.RecipeVersion", trace.WithAttributes(attribute.String("recipe.id", id)))
+	defer func() { End(span, err) }()
+	return r.next.RecipeVersion(ctx, id)
+}
+
+func (r *tracedRepository) CollectionVersion(ctx context.Context) (_ repository.Version, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.CollectionVersion")
+	defer func() { End(span, err) }()
+	return r.next.CollectionVersion(ctx)
+}
+
+func (r *tracedRepository) Save(ctx context.Context, recipe domain.Recipe, author string) (_ *domain.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.Save", trace.WithAttributes(attribute.String("recipe.id", recipe.ID)))
+	defer func() { End(span, err) }()
+	revision, err := r.next.Save(ctx, recipe, author)
+	if revision != nil {
+		span.SetAttributes(attribute.Int("revision.number", revision.Number))
+	}
+	return revision, err
+}
+
+func (r *tracedRepository) ListRevisions(ctx context.Context, id string) (_ []domain.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.ListRevisions", trace.WithAttributes(attribute.String("recipe.id", id)))
+	defer func() { End(span, err) }()
+	revisions, err := r.next.ListRevisions(ctx, id)
+	span.SetAttributes(attribute.Int("revision.count", len(revisions)))
+	return revisions, err
+}
+
+func (r *tracedRepository) FindRevision(ctx context.Context, id string, number int) (_ *domain.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.FindRevision", trace.WithAttributes(
+		attribute.String("recipe.id", id), attribute.Int("revision.number", number),
+	))
+	defer func() { End(span, err) }()
+	return r.next.FindRevision(ctx, id, number)
+}
+
+func (r *tracedRepository) ValidationReport(ctx context.Context) (_ repository.ValidationReport, err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.ValidationReport")
+	defer func() { End(span, err) }()
+	return r.next.ValidationReport(ctx)
+}
+
+func (r *tracedRepository) Reload(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "RecipeRepository.Reload")
+	defer func() { End(span, err) }()
+	return r.next.Reload(ctx)
+}
+
+// InstrumentImageStore records a span for every call to images.
+func InstrumentImageStore(images repository.ImageStore) repository.ImageStore {
+	return &tracedImageStore{next: images}
+}
+
+type tracedImageStore struct {
+	next repository.ImageStore
+}
+
+func (s *tracedImageStore) Save(ctx context.Context, name string, data []byte) (err error) {
+	ctx, span := tracer.Start(ctx, "ImageStore.Save", trace.WithAttributes(
+		attribute.String("image.name", name), attribute.Int("image.size", len(data)),
+	))
+	defer func() { End(span, err) }()
+	return s.next.Save(ctx, name, data)
+}
+
+func (s *tracedImageStore) Open(ctx context.Context, name string) (_ *repository.ImageFile, err error) {
+	ctx, span := tracer.Start(ctx, "ImageStore.Open", trace.WithAttributes(attribute.String("image.name", name)))
+	defer func() { End(span, err) }()
+	return s.next.Open(ctx, name)
+}
+
+func (s *tracedImageStore) Delete(ctx context.Context, name string) (err error) {
+	ctx, span := tracer.Start(ctx, "ImageStore.Delete", trace.WithAttributes(attribute.String("image.name", name)))
+	defer func() { End(span, err) }()
+	return s.next.Delete(ctx, name)
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+)
+
+type stubRepo struct {
+	repository.RecipeRepository
+}
+
+func (r *stubRepo) FindByID(ctx context.Context, id string) (*domain.Recipe, error) {
+	if id != "1" {
+		return nil, repository.ErrNotFound
+	}
+	return &domain.Recipe{ID: id}, nil
+}
+
+func (r *stubRepo) ListAll(ctx context.Context) ([]domain.Recipe, error) {
+	return []domain.Recipe{{ID: "1"}, {ID: "2"}}, nil
+}
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	values := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestInstrumentRepository(t *testing.T) {
+	recorder := recordSpans(t)
+	repo := InstrumentRepository(&stubRepo{})
+	ctx := context.Background()
+
+	repo.FindByID(ctx, "1")
+	repo.FindByID(ctx, "missing")
+	repo.ListAll(ctx)
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "RecipeRepository.FindByID" || attributes(spans[0])["recipe.id"].AsString() != "1" {
+		t.Errorf("unexpected span %s %v", spans[0].Name(), spans[0].Attributes())
+	}
+	if spans[0].Status().Code == codes.Error {
+		t.Error("expected a successful lookup not to be marked as an error")
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("expected the failed lookup to be marked as an error, got %v", spans[1].Status())
+	}
+	if got := attributes(spans[2])["recipe.count"].AsInt64(); got != 2 {
+		t.Errorf("expected recipe.count 2, got %d", got)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ExporterNone, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected shutdown error: %v", err)
+	}
+	if _, err := Setup(context.Background(), "jaeger", ""); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
@@ -0,0 +1,337 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/auth"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+var (
+	// ErrInvalidLogin hides whether the username or the password was wrong.
+	ErrInvalidLogin = errors.New("invalid username or password")
+	// ErrForbidden is returned when the caller may see a resource but not change it.
+	ErrForbidden = errors.New("forbidden")
+	// ErrInvalidAccount is wrapped by errors about malformed usernames,
+	// passwords, household or collection names and recipe owners.
+	ErrInvalidAccount = errors.New("invalid account data")
+)
+
+var validUsername = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,31}$`)
+
+// newID returns a random identifier for users, households and collections.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type RegisterUserUseCase interface {
+	Execute(ctx context.Context, username, password string) (*domain.User, error)
+}
+
+type registerUserUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewRegisterUserUseCase(accounts repository.AccountRepository) RegisterUserUseCase {
+	return &registerUserUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute creates a user with a hashed password. Usernames are lowercased.
+func (uc *registerUserUseCase) Execute(ctx context.Context, username, password string) (_ *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "RegisterUser")
+	defer func() { tracing.End(span, err) }()
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !validUsername.MatchString(username) {
+		return nil, fmt.Errorf("%w: username must be 3 to 32 letters, digits, '.', '_' or '-'", ErrInvalidAccount)
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAccount, err)
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	user := domain.User{ID: id, Username: username, PasswordHash: hash, CreatedAt: time.Now().UTC()}
+	if err := uc.accounts.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("user.id", user.ID))
+	return &user, nil
+}
+
+// LoginResult is a new session: the token to present as a bearer token, and
+// the logged in user.
+type LoginResult struct {
+	Token     string      `json:"token"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	User      domain.User `json:"user"`
+}
+
+type LoginUseCase interface {
+	Execute(ctx context.Context, username, password string) (*LoginResult, error)
+}
+
+type loginUseCase struct {
+	accounts repository.AccountRepository
+	ttl      time.Duration
+}
+
+// NewLoginUseCase creates sessions valid for ttl.
+func NewLoginUseCase(accounts repository.AccountRepository, ttl time.Duration) LoginUseCase {
+	return &loginUseCase{
+		accounts: accounts,
+		ttl:      ttl,
+	}
+}
+
+// Execute checks the password of a user and opens a session.
+func (uc *loginUseCase) Execute(ctx context.Context, username, password string) (_ *LoginResult, err error) {
+	ctx, span := tracer.Start(ctx, "Login")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := uc.accounts.FindUserByUsername(ctx, strings.ToLower(strings.TrimSpace(username)))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		auth.CheckPassword("", password)
+		return nil, ErrInvalidLogin
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		return nil, ErrInvalidLogin
+	}
+
+	token, hash, err := auth.NewSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	session := domain.Session{TokenHash: hash, UserID: user.ID, CreatedAt: now, ExpiresAt: now.Add(uc.ttl)}
+	if err := uc.accounts.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("user.id", user.ID))
+	return &LoginResult{Token: token, ExpiresAt: session.ExpiresAt, User: *user}, nil
+}
+
+type LogoutUseCase interface {
+	Execute(ctx context.Context, token string) error
+}
+
+type logoutUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewLogoutUseCase(accounts repository.AccountRepository) LogoutUseCase {
+	return &logoutUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute ends the session of a token.
+func (uc *logoutUseCase) Execute(ctx context.Context, token string) (err error) {
+	ctx, span := tracer.Start(ctx, "Logout")
+	defer func() { tracing.End(span, err) }()
+
+	return uc.accounts.DeleteSession(ctx, auth.HashSessionToken(token))
+}
+
+type AuthenticateSessionUseCase interface {
+	Execute(ctx context.Context, token string) (*domain.User, error)
+}
+
+type authenticateSessionUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewAuthenticateSessionUseCase(accounts repository.AccountRepository) AuthenticateSessionUseCase {
+	return &authenticateSessionUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute returns the user logged in with a session token, or
+// repository.ErrSessionNotFound if the token is unknown or expired.
+func (uc *authenticateSessionUseCase) Execute(ctx context.Context, token string) (_ *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "AuthenticateSession")
+	defer func() { tracing.End(span, err) }()
+
+	session, err := uc.accounts.FindSession(ctx, auth.HashSessionToken(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	user, err := uc.accounts.FindUser(ctx, session.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, repository.ErrSessionNotFound
+	}
+	return user, err
+}
+
+// Profile describes a user and their household.
+type Profile struct {
+	User      domain.User       `json:"user"`
+	Household *domain.Household `json:"household,omitempty"`
+	Members   []domain.User     `json:"members,omitempty"`
+}
+
+type GetProfileUseCase interface {
+	Execute(ctx context.Context, userID string) (*Profile, error)
+}
+
+type getProfileUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewGetProfileUseCase(accounts repository.AccountRepository) GetProfileUseCase {
+	return &getProfileUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute returns a user with their household and its members.
+func (uc *getProfileUseCase) Execute(ctx context.Context, userID string) (_ *Profile, err error) {
+	ctx, span := tracer.Start(ctx, "GetProfile", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := uc.accounts.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile := &Profile{User: *user}
+	if user.HouseholdID == "" {
+		return profile, nil
+	}
+	if profile.Household, err = uc.accounts.FindHousehold(ctx, user.HouseholdID); err != nil {
+		return nil, err
+	}
+	if profile.Members, err = uc.accounts.ListHouseholdMembers(ctx, user.HouseholdID); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+type CreateHouseholdUseCase interface {
+	Execute(ctx context.Context, userID, name string) (*domain.Household, error)
+}
+
+type createHouseholdUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewCreateHouseholdUseCase(accounts repository.AccountRepository) CreateHouseholdUseCase {
+	return &createHouseholdUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute creates a household and moves the user into it.
+func (uc *createHouseholdUseCase) Execute(ctx context.Context, userID, name string) (_ *domain.Household, err error) {
+	ctx, span := tracer.Start(ctx, "CreateHousehold", trace.WithAttributes(attribute.String("user.id", userID)))
+	defer func() { tracing.End(span, err) }()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: household name must not be empty", ErrInvalidAccount)
+	}
+	user, err := uc.accounts.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	household := domain.Household{ID: id, Name: name, CreatedAt: time.Now().UTC()}
+	if err := uc.accounts.CreateHousehold(ctx, household); err != nil {
+		return nil, err
+	}
+	if err := moveToHousehold(ctx, uc.accounts, user, household.ID); err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+type AddHouseholdMemberUseCase interface {
+	Execute(ctx context.Context, userID, householdID, username string) (*domain.User, error)
+}
+
+type addHouseholdMemberUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewAddHouseholdMemberUseCase(accounts repository.AccountRepository) AddHouseholdMemberUseCase {
+	return &addHouseholdMemberUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute moves the user named username into a household, on behalf of a
+// member of that household.
+func (uc *addHouseholdMemberUseCase) Execute(ctx context.Context, userID, householdID, username string) (_ *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "AddHouseholdMember", trace.WithAttributes(
+		attribute.String("user.id", userID),
+		attribute.String("household.id", householdID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	caller, err := uc.accounts.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if caller.HouseholdID != householdID {
+		// Do not tell strangers which households exist.
+		return nil, repository.ErrHouseholdNotFound
+	}
+	member, err := uc.accounts.FindUserByUsername(ctx, strings.ToLower(strings.TrimSpace(username)))
+	if err != nil {
+		return nil, err
+	}
+	if err := moveToHousehold(ctx, uc.accounts, member, householdID); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// moveToHousehold changes the household of a user, and of the recipes they
+// own, so that household members keep seeing each other's recipes.
+func moveToHousehold(ctx context.Context, accounts repository.AccountRepository, user *domain.User, householdID string) error {
+	user.HouseholdID = householdID
+	if err := accounts.UpdateUser(ctx, *user); err != nil {
+		return err
+	}
+	accesses, err := accounts.ListRecipeAccess(ctx)
+	if err != nil {
+		return err
+	}
+	for _, access := range accesses {
+		if access.OwnerID != user.ID || access.HouseholdID == householdID {
+			continue
+		}
+		access.HouseholdID = householdID
+		access.UpdatedAt = time.Now().UTC()
+		if err := accounts.SaveRecipeAccess(ctx, access); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+// requireUser returns ErrForbidden for viewers that are not logged in as a
+// user, such as anonymous clients and API keys.
+func requireUser(viewer domain.Viewer) error {
+	if viewer.UserID == "" {
+		return fmt.Errorf("%w: favorites and collections need a user session", ErrForbidden)
+	}
+	return nil
+}
+
+// checkVisible returns repository.ErrNotFound unless every recipe exists and
+// the viewer may see it.
+func checkVisible(ctx context.Context, repo repository.RecipeRepository, accounts repository.AccountRepository, viewer domain.Viewer, recipeIDs ...string) error {
+	for _, id := range recipeIDs {
+		if _, err := repo.RecipeVersion(ctx, id); err != nil {
+			return fmt.Errorf("recipe %s: %w", id, err)
+		}
+		access, err := recipeAccess(ctx, accounts, id)
+		if err != nil {
+			return err
+		}
+		if err := authorize(viewer, access, false); err != nil {
+			return fmt.Errorf("recipe %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+type ListFavoritesUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer) ([]domain.Recipe, error)
+}
+
+type listFavoritesUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewListFavoritesUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) ListFavoritesUseCase {
+	return &listFavoritesUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute returns the favorite recipes of the viewer in the order they were
+// added, leaving out recipes that were removed or are no longer visible.
+func (uc *listFavoritesUseCase) Execute(ctx context.Context, viewer domain.Viewer) (_ []domain.Recipe, err error) {
+	ctx, span := tracer.Start(ctx, "ListFavorites", trace.WithAttributes(attribute.String("user.id", viewer.UserID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := requireUser(viewer); err != nil {
+		return nil, err
+	}
+	ids, err := uc.accounts.ListFavorites(ctx, viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+	favorites := make([]domain.Recipe, 0, len(ids))
+	for _, id := range ids {
+		recipe, err := uc.repo.FindByID(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, *recipe)
+	}
+	return visibleRecipes(ctx, uc.accounts, viewer, favorites)
+}
+
+type SetFavoriteUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, recipeID string, favorite bool) error
+}
+
+type setFavoriteUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewSetFavoriteUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) SetFavoriteUseCase {
+	return &setFavoriteUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute adds a recipe the viewer may see to their favorites, or removes
+// one from them.
+func (uc *setFavoriteUseCase) Execute(ctx context.Context, viewer domain.Viewer, recipeID string, favorite bool) (err error) {
+	ctx, span := tracer.Start(ctx, "SetFavorite", trace.WithAttributes(
+		attribute.String("user.id", viewer.UserID),
+		attribute.String("recipe.id", recipeID),
+		attribute.Bool("favorite", favorite),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := requireUser(viewer); err != nil {
+		return err
+	}
+	if favorite {
+		if err := checkVisible(ctx, uc.repo, uc.accounts, viewer, recipeID); err != nil {
+			return err
+		}
+	}
+	return uc.accounts.SetFavorite(ctx, viewer.UserID, recipeID, favorite)
+}
+
+type ListCollectionsUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer) ([]domain.Collection, error)
+}
+
+type listCollectionsUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewListCollectionsUseCase(accounts repository.AccountRepository) ListCollectionsUseCase {
+	return &listCollectionsUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute returns the collections of the viewer, ordered by name.
+func (uc *listCollectionsUseCase) Execute(ctx context.Context, viewer domain.Viewer) (_ []domain.Collection, err error) {
+	ctx, span := tracer.Start(ctx, "ListCollections", trace.WithAttributes(attribute.String("user.id", viewer.UserID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := requireUser(viewer); err != nil {
+		return nil, err
+	}
+	return uc.accounts.ListCollections(ctx, viewer.UserID)
+}
+
+type GetCollectionUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, collectionID string) (*domain.Collection, error)
+}
+
+type getCollectionUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewGetCollectionUseCase(accounts repository.AccountRepository) GetCollectionUseCase {
+	return &getCollectionUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute returns a collection of the viewer.
+func (uc *getCollectionUseCase) Execute(ctx context.Context, viewer domain.Viewer, collectionID string) (_ *domain.Collection, err error) {
+	ctx, span := tracer.Start(ctx, "GetCollection", trace.WithAttributes(
+		attribute.String("user.id", viewer.UserID),
+		attribute.String("collection.id", collectionID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := requireUser(viewer); err != nil {
+		return nil, err
+	}
+	return uc.accounts.FindCollection(ctx, viewer.UserID, collectionID)
+}
+
+type SaveCollectionUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, collection domain.Collection) (*domain.Collection, error)
+}
+
+type saveCollectionUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewSaveCollectionUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) SaveCollectionUseCase {
+	return &saveCollectionUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute creates a collection of the viewer when collection has no ID, and
+// replaces the name and recipes of an existing one otherwise. Every recipe
+// must be visible to the viewer.
+func (uc *saveCollectionUseCase) Execute(ctx context.Context, viewer domain.Viewer, collection domain.Collection) (_ *domain.Collection, err error) {
+	ctx, span := tracer.Start(ctx, "SaveCollection", trace.WithAttributes(
+		attribute.String("user.id", viewer.UserID),
+		attribute.String("collection.id", collection.ID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := requireUser(viewer); err != nil {
+		return nil, err
+	}
+	collection.Name = strings.TrimSpace(collection.Name)
+	if collection.Name == "" {
+		return nil, fmt.Errorf("%w: collection name must not be empty", ErrInvalidAccount)
+	}
+	if collection.RecipeIDs == nil {
+		collection.RecipeIDs = []string{}
+	}
+	if err := checkVisible(ctx, uc.repo, uc.accounts, viewer, collection.RecipeIDs...); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if collection.ID == "" {
+		if collection.ID, err = newID(); err != nil {
+			return nil, err
+		}
+		collection.CreatedAt = now
+	} else {
+		existing, err := uc.accounts.FindCollection(ctx, viewer.UserID, collection.ID)
+		if err != nil {
+			return nil, err
+		}
+		collection.CreatedAt = existing.CreatedAt
+	}
+	collection.UserID = viewer.UserID
+	collection.UpdatedAt = now
+	if err := uc.accounts.SaveCollection(ctx, collection); err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+type DeleteCollectionUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, collectionID string) error
+}
+
+type deleteCollectionUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewDeleteCollectionUseCase(accounts repository.AccountRepository) DeleteCollectionUseCase {
+	return &deleteCollectionUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute deletes a collection of the viewer.
+func (uc *deleteCollectionUseCase) Execute(ctx context.Context, viewer domain.Viewer, collectionID string) (err error) {
+	ctx, span := tracer.Start(ctx, "DeleteCollection", trace.WithAttributes(
+		attribute.String("user.id", viewer.UserID),
+		attribute.String("collection.id", collectionID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := requireUser(viewer); err != nil {
+		return err
+	}
+	return uc.accounts.DeleteCollection(ctx, viewer.UserID, collectionID)
+}
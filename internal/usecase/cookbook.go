@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+// DefaultCookbookTitle is the title of cookbooks that are not made from a
+// collection.
+const DefaultCookbookTitle = "Cookbook"
+
+// Cookbook is a selection of recipes bound together, in order.
+type Cookbook struct {
+	Title   string
+	Recipes []domain.Recipe
+}
+
+// CookbookSelection chooses the recipes of a cookbook: those of a collection
+// of the viewer, or the given recipes, or else every recipe the viewer may see.
+type CookbookSelection struct {
+	CollectionID string
+	RecipeIDs    []string
+}
+
+type GetCookbookUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, selection CookbookSelection) (*Cookbook, error)
+}
+
+type getCookbookUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewGetCookbookUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) GetCookbookUseCase {
+	return &getCookbookUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute gathers the recipes of a cookbook. Recipes named explicitly must
+// exist and be visible, and keep the order they were given in; recipes of a
+// collection keep its order, leaving out those removed or hidden since.
+// Without a selection, every visible recipe is included, sorted by name.
+func (uc *getCookbookUseCase) Execute(ctx context.Context, viewer domain.Viewer, selection CookbookSelection) (_ *Cookbook, err error) {
+	ctx, span := tracer.Start(ctx, "GetCookbook", trace.WithAttributes(
+		attribute.String("collection.id", selection.CollectionID),
+		attribute.StringSlice("recipe.ids", selection.RecipeIDs),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	cookbook := &Cookbook{Title: DefaultCookbookTitle}
+	switch {
+	case selection.CollectionID != "":
+		if err := requireUser(viewer); err != nil {
+			return nil, err
+		}
+		collection, err := uc.accounts.FindCollection(ctx, viewer.UserID, selection.CollectionID)
+		if err != nil {
+			return nil, err
+		}
+		cookbook.Title = collection.Name
+		recipes, err := uc.find(ctx, collection.RecipeIDs, true)
+		if err != nil {
+			return nil, err
+		}
+		if cookbook.Recipes, err = visibleRecipes(ctx, uc.accounts, viewer, recipes); err != nil {
+			return nil, err
+		}
+	case len(selection.RecipeIDs) > 0:
+		ids := uniqueIDs(selection.RecipeIDs)
+		if err := checkVisible(ctx, uc.repo, uc.accounts, viewer, ids...); err != nil {
+			return nil, err
+		}
+		if cookbook.Recipes, err = uc.find(ctx, ids, false); err != nil {
+			return nil, err
+		}
+	default:
+		recipes, err := uc.repo.ListAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if recipes, err = visibleRecipes(ctx, uc.accounts, viewer, recipes); err != nil {
+			return nil, err
+		}
+		slices.SortFunc(recipes, func(a, b domain.Recipe) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		})
+		cookbook.Recipes = recipes
+	}
+	span.SetAttributes(attribute.Int("recipe.count", len(cookbook.Recipes)))
+	return cookbook, nil
+}
+
+// find loads recipes in the order of ids, skipping missing ones if asked to.
+func (uc *getCookbookUseCase) find(ctx context.Context, ids []string, skipMissing bool) ([]domain.Recipe, error) {
+	recipes := make([]domain.Recipe, 0, len(ids))
+	for _, id := range ids {
+		recipe, err := uc.repo.FindByID(ctx, id)
+		if skipMissing && errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, *recipe)
+	}
+	return recipes, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence of each.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -1,29 +1,43 @@
 package usecase
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
 	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
 )
 
 type GetAllIngredientsUseCase interface {
-	Execute() ([]string, error)
+	Execute(ctx context.Context, viewer domain.Viewer) ([]string, error)
 }
 
 type getAllIngredientsUseCase struct {
-	repo repository.RecipeRepository
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
 }
 
-func NewGetAllIngredientsUseCase(repo repository.RecipeRepository) GetAllIngredientsUseCase {
+func NewGetAllIngredientsUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) GetAllIngredientsUseCase {
 	return &getAllIngredientsUseCase{
-		repo: repo,
+		repo:     repo,
+		accounts: accounts,
 	}
 }
 
-// Execute returns all Ingredients from the repository.
-func (uc *getAllIngredientsUseCase) Execute() ([]string, error) {
-	recipes, err := uc.repo.ListAll()
+// Execute returns all Ingredients of the recipes the viewer may see.
+func (uc *getAllIngredientsUseCase) Execute(ctx context.Context, viewer domain.Viewer) (_ []string, err error) {
+	ctx, span := tracer.Start(ctx, "GetAllIngredients")
+	defer func() { tracing.End(span, err) }()
+
+	recipes, err := uc.repo.ListAll(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if recipes, err = visibleRecipes(ctx, uc.accounts, viewer, recipes); err != nil {
+		return nil, err
+	}
 	allIngredients := make([]string, 0)
 	for _, recipe := range recipes {
 		for _, ingredient := range recipe.Ingredients {
@@ -38,5 +52,6 @@ func (uc *getAllIngredientsUseCase) Execute() ([]string, error) {
 			}
 		}
 	}
+	span.SetAttributes(attribute.Int("ingredient.count", len(allIngredients)))
 	return allIngredients, nil
 }
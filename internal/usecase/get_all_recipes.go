@@ -1,40 +1,53 @@
 package usecase
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/fromenjn/recipe-manager/internal/domain"
 	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
 )
 
 type GetAllRecipesUseCase interface {
-	Execute(ingredientConstraint string) ([]domain.Recipe, error)
+	Execute(ctx context.Context, viewer domain.Viewer, ingredientConstraint string) ([]domain.Recipe, error)
 }
 
 type getAllRecipesUseCase struct {
-	repo repository.RecipeRepository
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
 }
 
-func NewGetAllRecipesUseCase(repo repository.RecipeRepository) GetAllRecipesUseCase {
+func NewGetAllRecipesUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) GetAllRecipesUseCase {
 	return &getAllRecipesUseCase{
-		repo: repo,
+		repo:     repo,
+		accounts: accounts,
 	}
 }
 
-// Execute returns all recipes from the repository.
-func (uc *getAllRecipesUseCase) Execute(ingredientConstraint string) ([]domain.Recipe, error) {
-	recipes, err := uc.repo.ListAll()
+// Execute returns all recipes from the repository that the viewer may see.
+func (uc *getAllRecipesUseCase) Execute(ctx context.Context, viewer domain.Viewer, ingredientConstraint string) (_ []domain.Recipe, err error) {
+	ctx, span := tracer.Start(ctx, "GetAllRecipes", trace.WithAttributes(attribute.String("constraint.ingredient", ingredientConstraint)))
+	defer func() { tracing.End(span, err) }()
+
+	recipes, err := uc.repo.ListAll(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if recipes, err = visibleRecipes(ctx, uc.accounts, viewer, recipes); err != nil {
+		return nil, err
+	}
 	if ingredientConstraint != "" {
 		newRecipes := make([]domain.Recipe, 0)
 		for _, recipe := range recipes {
-			for _, ingredient := range recipe.Ingredients {
-				if ingredient.Name == ingredientConstraint {
-					newRecipes = append(newRecipes, recipe)
-				}
+			if usesIngredient(recipe, ingredientConstraint) {
+				newRecipes = append(newRecipes, recipe)
 			}
 		}
-		return newRecipes, nil
+		recipes = newRecipes
 	}
-	return uc.repo.ListAll()
+	span.SetAttributes(attribute.Int("recipe.count", len(recipes)))
+	return recipes, nil
 }
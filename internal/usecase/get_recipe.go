@@ -1,12 +1,18 @@
 package usecase
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/fromenjn/recipe-manager/internal/domain"
 	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
 )
 
 type GetRecipeUseCase interface {
-	Execute(recipeID, ingredientConstraint string, quantityConstraint float64) (*domain.Recipe, error)
+	Execute(ctx context.Context, recipeID, ingredientConstraint string, quantityConstraint float64) (*domain.Recipe, error)
 }
 
 type getRecipeUseCase struct {
@@ -26,15 +32,24 @@ func NewGetRecipeUseCase(
 
 // Execute fetches a recipe by ID and computes any ingredient ratios if requested.
 func (uc *getRecipeUseCase) Execute(
-	recipeID, ingredientConstraint string, quantityConstraint float64,
-) (*domain.Recipe, error) {
-	recipe, err := uc.repo.FindByID(recipeID)
+	ctx context.Context, recipeID, ingredientConstraint string, quantityConstraint float64,
+) (_ *domain.Recipe, err error) {
+	ctx, span := tracer.Start(ctx, "GetRecipe", trace.WithAttributes(attribute.String("recipe.id", recipeID)))
+	defer func() { tracing.End(span, err) }()
+
+	recipe, err := uc.repo.FindByID(ctx, recipeID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Apply ratio logic if constraints are provided
-	if err := uc.service.ComputeRatios(recipe, ingredientConstraint, quantityConstraint); err != nil {
+	_, scaleSpan := tracer.Start(ctx, "ComputeRatios", trace.WithAttributes(
+		attribute.String("constraint.ingredient", ingredientConstraint),
+		attribute.Float64("constraint.quantity", quantityConstraint),
+	))
+	err = uc.service.ComputeRatios(recipe, ingredientConstraint, quantityConstraint)
+	tracing.End(scaleSpan, err)
+	if err != nil {
 		return nil, err
 	}
 
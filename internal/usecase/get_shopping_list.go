@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+// ShoppingListEntry selects a recipe for a shopping list. Factor scales its
+// quantities; zero means the recipe as written.
+type ShoppingListEntry struct {
+	RecipeID string
+	Factor   float64
+}
+
+type GetShoppingListUseCase interface {
+	Execute(ctx context.Context, entries []ShoppingListEntry) ([]domain.ShoppingListItem, error)
+}
+
+type getShoppingListUseCase struct {
+	repo    repository.RecipeRepository
+	service domain.RecipeService
+}
+
+func NewGetShoppingListUseCase(repo repository.RecipeRepository, service domain.RecipeService) GetShoppingListUseCase {
+	return &getShoppingListUseCase{
+		repo:    repo,
+		service: service,
+	}
+}
+
+// Execute merges the scaled ingredients of the selected recipes.
+func (uc *getShoppingListUseCase) Execute(ctx context.Context, entries []ShoppingListEntry) (_ []domain.ShoppingListItem, err error) {
+	ctx, span := tracer.Start(ctx, "GetShoppingList", trace.WithAttributes(attribute.Int("recipe.count", len(entries))))
+	defer func() { tracing.End(span, err) }()
+
+	recipes := make([]domain.Recipe, 0, len(entries))
+	for _, entry := range entries {
+		recipe, err := uc.repo.FindByID(ctx, entry.RecipeID)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Factor != 0 && entry.Factor != 1 {
+			if err := uc.service.Scale(recipe, entry.Factor); err != nil {
+				return nil, err
+			}
+		}
+		recipes = append(recipes, *recipe)
+	}
+	items := domain.BuildShoppingList(recipes)
+	span.SetAttributes(attribute.Int("item.count", len(items)))
+	return items, nil
+}
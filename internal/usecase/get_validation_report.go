@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+type GetValidationReportUseCase interface {
+	Execute(ctx context.Context) (repository.ValidationReport, error)
+}
+
+type getValidationReportUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewGetValidationReportUseCase(repo repository.RecipeRepository) GetValidationReportUseCase {
+	return &getValidationReportUseCase{
+		repo: repo,
+	}
+}
+
+// Execute returns the problems found in the recipe files when they were loaded.
+func (uc *getValidationReportUseCase) Execute(ctx context.Context) (_ repository.ValidationReport, err error) {
+	ctx, span := tracer.Start(ctx, "GetValidationReport")
+	defer func() { tracing.End(span, err) }()
+
+	return uc.repo.ValidationReport(ctx)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+type GetRecipeVersionUseCase interface {
+	Execute(ctx context.Context, recipeID string) (repository.Version, error)
+}
+
+type getRecipeVersionUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewGetRecipeVersionUseCase(repo repository.RecipeRepository) GetRecipeVersionUseCase {
+	return &getRecipeVersionUseCase{
+		repo: repo,
+	}
+}
+
+// Execute returns the current version of a single recipe.
+func (uc *getRecipeVersionUseCase) Execute(ctx context.Context, recipeID string) (_ repository.Version, err error) {
+	ctx, span := tracer.Start(ctx, "GetRecipeVersion", trace.WithAttributes(attribute.String("recipe.id", recipeID)))
+	defer func() { tracing.End(span, err) }()
+
+	return uc.repo.RecipeVersion(ctx, recipeID)
+}
+
+type GetCollectionVersionUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer) (repository.Version, error)
+}
+
+type getCollectionVersionUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewGetCollectionVersionUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) GetCollectionVersionUseCase {
+	return &getCollectionVersionUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute returns the current version of the recipes the viewer may see.
+func (uc *getCollectionVersionUseCase) Execute(ctx context.Context, viewer domain.Viewer) (_ repository.Version, err error) {
+	ctx, span := tracer.Start(ctx, "GetCollectionVersion")
+	defer func() { tracing.End(span, err) }()
+
+	version, err := uc.repo.CollectionVersion(ctx)
+	if err != nil {
+		return repository.Version{}, err
+	}
+	return viewerVersion(ctx, uc.accounts, viewer, version)
+}
@@ -0,0 +1,350 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/archive"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/imaging"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+// ImageURLPrefix is the URL path under which stored images are served.
+const ImageURLPrefix = "/images/"
+
+// ThumbnailSize is the maximum width and height of generated thumbnails.
+const ThumbnailSize = 400
+
+var ErrStepNotFound = errors.New("step not found")
+
+// imageDir returns the path under which the illustrations of a recipe are
+// stored, e.g. /images/chocolate_5fcake/ for the recipe chocolate_cake. Bytes
+// of the ID other than letters, digits and hyphens are written as _ and two
+// hex digits, so that every recipe gets its own directory.
+func imageDir(recipeID string) string {
+	var dir strings.Builder
+	for i := 0; i < len(recipeID); i++ {
+		c := recipeID[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' {
+			dir.WriteByte(c)
+		} else {
+			fmt.Fprintf(&dir, "_%02x", c)
+		}
+	}
+	return ImageURLPrefix + dir.String() + "/"
+}
+
+// imageDirRecipeID returns the ID of the recipe whose illustrations are
+// stored in dir, reversing imageDir.
+func imageDirRecipeID(dir string) (string, bool) {
+	var id strings.Builder
+	for i := 0; i < len(dir); i++ {
+		if dir[i] != '_' {
+			id.WriteByte(dir[i])
+			continue
+		}
+		if i+2 >= len(dir) {
+			return "", false
+		}
+		c, err := strconv.ParseUint(dir[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", false
+		}
+		id.WriteByte(byte(c))
+		i += 2
+	}
+	return id.String(), true
+}
+
+type UploadIllustrationUseCase interface {
+	Execute(ctx context.Context, recipeID, stepID, description string, data []byte, author string) (*domain.RecipeIllustration, error)
+}
+
+type uploadIllustrationUseCase struct {
+	repo   repository.RecipeRepository
+	images repository.ImageStore
+	// locks serializes the uploads to a recipe, which would otherwise pick
+	// the same illustration ID and lose each other's revision.
+	locks *recipeLocks
+}
+
+func NewUploadIllustrationUseCase(repo repository.RecipeRepository, images repository.ImageStore) UploadIllustrationUseCase {
+	return &uploadIllustrationUseCase{
+		repo:   repo,
+		images: images,
+		locks:  newRecipeLocks(),
+	}
+}
+
+// Execute validates an uploaded picture, stores it with a thumbnail and WebP
+// variants, and attaches it to a step of the recipe as a new revision. The
+// stored files are removed again if the revision cannot be saved.
+func (uc *uploadIllustrationUseCase) Execute(
+	ctx context.Context, recipeID, stepID, description string, data []byte, author string,
+) (_ *domain.RecipeIllustration, err error) {
+	ctx, span := tracer.Start(ctx, "UploadIllustration", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.String("step.id", stepID),
+		attribute.Int("image.size", len(data)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	unlock := uc.locks.lock(recipeID)
+	defer unlock()
+
+	recipe, err := uc.repo.FindByID(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	step := findStep(recipe, stepID)
+	if step == nil {
+		return nil, ErrStepNotFound
+	}
+
+	contentType, extension, err := imaging.Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	illustration := domain.RecipeIllustration{
+		ID:          nextIllustrationID(recipe),
+		Description: description,
+		Variants:    make(map[string]string),
+	}
+	base := strings.TrimPrefix(imageDir(recipe.ID), ImageURLPrefix) + illustration.ID
+
+	// GIF thumbnails would only keep the first frame, so store them as PNG.
+	thumbnailType, thumbnailExtension := contentType, extension
+	if contentType == "image/gif" {
+		thumbnailType, thumbnailExtension = "image/png", ".png"
+	}
+	thumbnail := imaging.Thumbnail(img, ThumbnailSize)
+
+	files := []struct {
+		variant string
+		name    string
+		encode  func() ([]byte, error)
+	}{
+		{"", base + extension, func() ([]byte, error) { return data, nil }},
+		{domain.VariantThumbnail, base + "_thumb" + thumbnailExtension, func() ([]byte, error) {
+			return imaging.Encode(thumbnail, thumbnailType)
+		}},
+		{domain.VariantWebP, base + ".webp", func() ([]byte, error) {
+			return imaging.Encode(img, "image/webp")
+		}},
+		{domain.VariantThumbnailWebP, base + "_thumb.webp", func() ([]byte, error) {
+			return imaging.Encode(thumbnail, "image/webp")
+		}},
+	}
+	var stored []string
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, uc.deleteImages(ctx, stored))
+		}
+	}()
+	for _, file := range files {
+		if file.variant == domain.VariantWebP && contentType == "image/webp" {
+			// The original already is the WebP variant.
+			illustration.Variants[file.variant] = ImageURLPrefix + base + extension
+			continue
+		}
+		encoded, err := file.encode()
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.images.Save(ctx, file.name, encoded); err != nil {
+			return nil, err
+		}
+		stored = append(stored, file.name)
+		if file.variant == "" {
+			illustration.Filepath = ImageURLPrefix + file.name
+		} else {
+			illustration.Variants[file.variant] = ImageURLPrefix + file.name
+		}
+	}
+
+	step.RecipeIllustration = append(step.RecipeIllustration, illustration)
+	if _, err := uc.repo.Save(ctx, *recipe, author); err != nil {
+		return nil, err
+	}
+	return &illustration, nil
+}
+
+// deleteImages removes the files of an upload that failed, even once the
+// request is cancelled.
+func (uc *uploadIllustrationUseCase) deleteImages(ctx context.Context, names []string) error {
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for _, name := range names {
+		if err := uc.images.Delete(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recipeLocks hands out a mutex per recipe ID, kept only while in use.
+type recipeLocks struct {
+	mu    sync.Mutex
+	locks map[string]*recipeLock
+}
+
+type recipeLock struct {
+	sync.Mutex
+	waiters int
+}
+
+func newRecipeLocks() *recipeLocks {
+	return &recipeLocks{locks: make(map[string]*recipeLock)}
+}
+
+// lock waits for the lock of a recipe and returns the function releasing it.
+func (l *recipeLocks) lock(recipeID string) (unlock func()) {
+	l.mu.Lock()
+	lock, ok := l.locks[recipeID]
+	if !ok {
+		lock = &recipeLock{}
+		l.locks[recipeID] = lock
+	}
+	lock.waiters++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mu.Lock()
+		if lock.waiters--; lock.waiters == 0 {
+			delete(l.locks, recipeID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func findStep(recipe *domain.Recipe, stepID string) *domain.RecipeStep {
+	for i := range recipe.Steps {
+		if recipe.Steps[i].ID == stepID {
+			return &recipe.Steps[i]
+		}
+	}
+	return nil
+}
+
+// nextIllustrationID returns an illustration ID not used anywhere in the recipe,
+// following the "illustrationN" convention of the existing data files.
+func nextIllustrationID(recipe *domain.Recipe) string {
+	used := make(map[string]bool)
+	for _, step := range recipe.Steps {
+		for _, illustration := range step.RecipeIllustration {
+			used[illustration.ID] = true
+		}
+	}
+	for n := len(used) + 1; ; n++ {
+		id := fmt.Sprintf("illustration%d", n)
+		if !used[id] {
+			return id
+		}
+	}
+}
+
+type GetImageUseCase interface {
+	Execute(ctx context.Context, name string) (*repository.ImageFile, error)
+}
+
+type getImageUseCase struct {
+	images repository.ImageStore
+}
+
+func NewGetImageUseCase(images repository.ImageStore) GetImageUseCase {
+	return &getImageUseCase{
+		images: images,
+	}
+}
+
+// Execute opens a stored image by its name relative to ImageURLPrefix.
+func (uc *getImageUseCase) Execute(ctx context.Context, name string) (_ *repository.ImageFile, err error) {
+	ctx, span := tracer.Start(ctx, "GetImage", trace.WithAttributes(attribute.String("image.name", name)))
+	defer func() { tracing.End(span, err) }()
+
+	return uc.images.Open(ctx, name)
+}
+
+type FindImageRecipesUseCase interface {
+	Execute(ctx context.Context, name string) ([]string, error)
+}
+
+type findImageRecipesUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewFindImageRecipesUseCase(repo repository.RecipeRepository) FindImageRecipesUseCase {
+	return &findImageRecipesUseCase{
+		repo: repo,
+	}
+}
+
+// Execute returns the IDs of the recipes illustrated by a stored image, by
+// its name relative to ImageURLPrefix, so that it is only served to those
+// who may see one of them. Uploaded images live in the directory named after
+// their recipe, which is looked up first; other recipes are searched after.
+// It returns repository.ErrImageNotFound for images no recipe uses.
+func (uc *findImageRecipesUseCase) Execute(ctx context.Context, name string) (_ []string, err error) {
+	ctx, span := tracer.Start(ctx, "FindImageRecipes", trace.WithAttributes(attribute.String("image.name", name)))
+	defer func() { tracing.End(span, err) }()
+
+	path := ImageURLPrefix + name
+	dir, _, _ := strings.Cut(name, "/")
+	if id, ok := imageDirRecipeID(dir); ok {
+		recipe, err := uc.repo.FindByID(ctx, id)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if recipe != nil && slices.Contains(archive.Illustrations(*recipe), path) {
+			return []string{recipe.ID}, nil
+		}
+	}
+
+	recipes, err := uc.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, recipe := range recipes {
+		if slices.Contains(archive.Illustrations(recipe), path) {
+			ids = append(ids, recipe.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, repository.ErrImageNotFound
+	}
+	return ids, nil
+}
+
+// ReadIllustration reads an illustration by its path in recipes, e.g.
+// /images/chocolate_cake/step1.jpg, for documents embedding it.
+func ReadIllustration(ctx context.Context, getImage GetImageUseCase, path string) ([]byte, error) {
+	name, ok := strings.CutPrefix(path, ImageURLPrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", repository.ErrInvalidName, path)
+	}
+	file, err := getImage.Execute(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
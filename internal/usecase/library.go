@@ -0,0 +1,427 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/archive"
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+type ExportLibraryUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, w io.Writer) (archive.Stats, error)
+}
+
+type exportLibraryUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+	images   repository.ImageStore
+}
+
+func NewExportLibraryUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository, images repository.ImageStore) ExportLibraryUseCase {
+	return &exportLibraryUseCase{
+		repo:     repo,
+		accounts: accounts,
+		images:   images,
+	}
+}
+
+// Execute writes the recipes the viewer may see, sorted by ID, with their
+// illustrations and access, as a library archive.
+func (uc *exportLibraryUseCase) Execute(ctx context.Context, viewer domain.Viewer, w io.Writer) (_ archive.Stats, err error) {
+	ctx, span := tracer.Start(ctx, "ExportLibrary")
+	defer func() { tracing.End(span, err) }()
+
+	recipes, err := uc.repo.ListAll(ctx)
+	if err != nil {
+		return archive.Stats{}, err
+	}
+	if recipes, err = visibleRecipes(ctx, uc.accounts, viewer, recipes); err != nil {
+		return archive.Stats{}, err
+	}
+	slices.SortFunc(recipes, func(a, b domain.Recipe) int { return strings.Compare(a.ID, b.ID) })
+	access, err := uc.accounts.ListRecipeAccess(ctx)
+	if err != nil {
+		return archive.Stats{}, err
+	}
+
+	stats, err := archive.Write(w, archive.Library{
+		Recipes: recipes,
+		Access:  access,
+		Images:  func(path string) ([]byte, error) { return readImage(ctx, uc.images, path) },
+	}, time.Now())
+	if err != nil {
+		return stats, err
+	}
+	span.SetAttributes(attribute.Int("recipe.count", stats.Recipes), attribute.Int("image.count", stats.Images))
+	return stats, nil
+}
+
+// readImage reads a stored illustration by its path in recipes.
+func readImage(ctx context.Context, images repository.ImageStore, path string) ([]byte, error) {
+	name, ok := strings.CutPrefix(path, ImageURLPrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", repository.ErrInvalidName, path)
+	}
+	file, err := images.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// ConflictPolicy says what importing does with recipes whose ID is taken.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing recipe and leaves the imported one out.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing recipe, as a new revision.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename imports the recipe under a new ID, e.g. 2-2, and its
+	// illustrations under a new directory.
+	ConflictRename ConflictPolicy = "rename"
+)
+
+// ErrInvalidImport is wrapped by errors about malformed import options.
+var ErrInvalidImport = errors.New("invalid import")
+
+// ParseConflictPolicy checks that s names a conflict policy.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+		return p, nil
+	}
+	return "", fmt.Errorf("%w: unknown conflict policy %q, expected %s, %s or %s", ErrInvalidImport, s, ConflictSkip, ConflictOverwrite, ConflictRename)
+}
+
+// What importing did with a recipe.
+const (
+	ImportCreated     = "created"
+	ImportOverwritten = "overwritten"
+	ImportRenamed     = "renamed"
+	ImportSkipped     = "skipped"
+	ImportFailed      = "failed"
+)
+
+// ImportOptions configure an import.
+type ImportOptions struct {
+	Conflict ConflictPolicy
+	// DryRun reports what the import would do without changing anything.
+	DryRun bool
+	// Author is recorded as the author of the new revisions.
+	Author string
+}
+
+// ImportedRecipe reports what importing did with a recipe of the archive.
+type ImportedRecipe struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// NewID is the ID a renamed recipe was imported under.
+	NewID  string `json:"new_id,omitempty"`
+	Action string `json:"action"`
+	// Images counts the illustration files written.
+	Images int `json:"images"`
+	// Errors lists why an invalid recipe failed; Error why another failed.
+	Errors   []domain.FieldError `json:"errors,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+// ImportReport tells what an import changed, or would change for a dry run.
+type ImportReport struct {
+	DryRun        bool             `json:"dry_run"`
+	Conflict      ConflictPolicy   `json:"conflict"`
+	FormatVersion int              `json:"format_version"`
+	Created       int              `json:"created"`
+	Overwritten   int              `json:"overwritten"`
+	Renamed       int              `json:"renamed"`
+	Skipped       int              `json:"skipped"`
+	Failed        int              `json:"failed"`
+	Images        int              `json:"images"`
+	Recipes       []ImportedRecipe `json:"recipes"`
+}
+
+type ImportLibraryUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, data []byte, opts ImportOptions) (*ImportReport, error)
+}
+
+type importLibraryUseCase struct {
+	repo      repository.RecipeRepository
+	accounts  repository.AccountRepository
+	images    repository.ImageStore
+	validator domain.RecipeValidator
+}
+
+func NewImportLibraryUseCase(
+	repo repository.RecipeRepository, accounts repository.AccountRepository,
+	images repository.ImageStore, validator domain.RecipeValidator,
+) ImportLibraryUseCase {
+	return &importLibraryUseCase{
+		repo:      repo,
+		accounts:  accounts,
+		images:    images,
+		validator: validator,
+	}
+}
+
+// Execute imports the recipes of a library archive with their illustrations.
+// Recipes whose ID is taken follow opts.Conflict; invalid recipes and
+// recipes the viewer may not change fail without stopping the import. The
+// access recorded in the archive is restored for admins; otherwise new
+// recipes belong to the viewer, as when saving them.
+func (uc *importLibraryUseCase) Execute(ctx context.Context, viewer domain.Viewer, data []byte, opts ImportOptions) (_ *ImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "ImportLibrary", trace.WithAttributes(
+		attribute.String("conflict", string(opts.Conflict)),
+		attribute.Bool("dry_run", opts.DryRun),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictSkip
+	}
+	if _, err := ParseConflictPolicy(string(opts.Conflict)); err != nil {
+		return nil, err
+	}
+	manifest, library, err := archive.Read(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Conflict: opts.Conflict, FormatVersion: manifest.Version, Recipes: []ImportedRecipe{}}
+	// taken holds the IDs of the archive and those given to renamed recipes.
+	taken := make(map[string]bool, len(library.Recipes))
+	for _, recipe := range library.Recipes {
+		taken[recipe.ID] = true
+	}
+	for _, recipe := range library.Recipes {
+		result, err := uc.importRecipe(ctx, viewer, library, recipe, opts, taken)
+		if err != nil {
+			return nil, err
+		}
+		switch result.Action {
+		case ImportCreated:
+			report.Created++
+		case ImportOverwritten:
+			report.Overwritten++
+		case ImportRenamed:
+			report.Renamed++
+		case ImportSkipped:
+			report.Skipped++
+		case ImportFailed:
+			report.Failed++
+		}
+		report.Images += result.Images
+		report.Recipes = append(report.Recipes, result)
+	}
+	span.SetAttributes(attribute.Int("recipe.count", len(report.Recipes)))
+	return report, nil
+}
+
+// importRecipe imports one recipe. It only returns errors that stop the
+// import, such as failing storage; others are reported on the recipe.
+func (uc *importLibraryUseCase) importRecipe(
+	ctx context.Context, viewer domain.Viewer, library archive.Library, recipe domain.Recipe, opts ImportOptions, taken map[string]bool,
+) (ImportedRecipe, error) {
+	result := ImportedRecipe{ID: recipe.ID, Name: recipe.Name, Action: ImportCreated}
+	fail := func(err error) (ImportedRecipe, error) {
+		result.Action, result.Images = ImportFailed, 0
+		var validationErrs domain.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			result.Errors = validationErrs
+		} else {
+			result.Error = err.Error()
+		}
+		return result, nil
+	}
+	if uc.validator != nil {
+		if errs := uc.validator.Validate(recipe); len(errs) > 0 {
+			return fail(errs)
+		}
+	}
+
+	exists, err := uc.exists(ctx, recipe.ID)
+	if err != nil {
+		return result, err
+	}
+	if exists {
+		switch opts.Conflict {
+		case ConflictSkip:
+			result.Action = ImportSkipped
+			return result, nil
+		case ConflictOverwrite:
+			result.Action = ImportOverwritten
+		case ConflictRename:
+			if recipe.ID, err = uc.freeID(ctx, recipe.ID, taken); err != nil {
+				return result, err
+			}
+			result.Action, result.NewID = ImportRenamed, recipe.ID
+		}
+	}
+	access, err := recipeAccess(ctx, uc.accounts, recipe.ID)
+	if err != nil {
+		return result, err
+	}
+	if err := authorize(viewer, access, true); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			err = ErrForbidden
+		}
+		return fail(err)
+	}
+
+	// Illustrations are only written to the directory of the recipe, so that
+	// an archive cannot replace those of other recipes. The others move
+	// there, as do those of renamed recipes, which would otherwise replace
+	// those of the existing recipe.
+	dir := imageDir(recipe.ID)
+	paths := archive.Illustrations(recipe)
+	files := make(map[string][]byte)
+	moved := make(map[string]string)
+	for _, imagePath := range paths {
+		content, err := library.Images(imagePath)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("illustration %s is not in the archive", imagePath))
+			continue
+		}
+		if !strings.HasPrefix(imagePath, dir) {
+			moved[imagePath] = freeImagePath(dir+path.Base(imagePath), paths, files)
+			if !strings.HasPrefix(imagePath, imageDir(result.ID)) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("illustration %s is outside the directory of the recipe, imported as %s", imagePath, moved[imagePath]))
+			}
+			imagePath = moved[imagePath]
+		}
+		files[imagePath] = content
+	}
+	recipe = relocateIllustrations(recipe, moved)
+	result.Images = len(files)
+
+	restore, ok := library.Access[result.ID]
+	if ok && !viewer.Admin {
+		result.Warnings = append(result.Warnings, "owner and visibility are only restored for admins")
+	}
+	if ok && viewer.Admin {
+		if _, err := uc.accounts.FindUser(ctx, restore.OwnerID); errors.Is(err, repository.ErrUserNotFound) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("owner %s has no account here", restore.OwnerID))
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for _, imagePath := range sortedKeys(files) {
+		if err := uc.images.Save(ctx, strings.TrimPrefix(imagePath, ImageURLPrefix), files[imagePath]); err != nil {
+			return result, err
+		}
+	}
+	if _, err := uc.repo.Save(ctx, recipe, opts.Author); err != nil {
+		var validationErrs domain.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			return fail(err)
+		}
+		return result, err
+	}
+
+	switch {
+	case ok && viewer.Admin:
+		restore.RecipeID, restore.UpdatedAt = recipe.ID, time.Now().UTC()
+		err = uc.accounts.SaveRecipeAccess(ctx, restore)
+	case result.Action != ImportOverwritten && access.OwnerID == "" && viewer.UserID != "":
+		err = uc.accounts.SaveRecipeAccess(ctx, domain.RecipeAccess{
+			RecipeID:   recipe.ID,
+			OwnerID:    viewer.UserID,
+			Visibility: domain.VisibilityPrivate,
+			UpdatedAt:  time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to record access of recipe %s: %w", recipe.ID, err)
+	}
+	return result, nil
+}
+
+func (uc *importLibraryUseCase) exists(ctx context.Context, id string) (bool, error) {
+	_, err := uc.repo.RecipeVersion(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// freeID returns the first ID made of id and a number, e.g. 2-2, that is
+// neither stored nor in the archive, and takes it.
+func (uc *importLibraryUseCase) freeID(ctx context.Context, id string, taken map[string]bool) (string, error) {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", id, n)
+		if taken[candidate] {
+			continue
+		}
+		exists, err := uc.exists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			taken[candidate] = true
+			return candidate, nil
+		}
+	}
+}
+
+// freeImagePath returns imagePath, or the same path with a number before
+// its extension, e.g. step1-2.jpg, whichever is neither an illustration of
+// the recipe nor already written.
+func freeImagePath(imagePath string, paths []string, files map[string][]byte) string {
+	ext := path.Ext(imagePath)
+	candidate := imagePath
+	for n := 2; ; n++ {
+		if _, written := files[candidate]; !written && !slices.Contains(paths, candidate) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(imagePath, ext), n, ext)
+	}
+}
+
+// relocateIllustrations returns a copy of recipe whose illustration paths
+// are replaced as given by moved.
+func relocateIllustrations(recipe domain.Recipe, moved map[string]string) domain.Recipe {
+	if len(moved) == 0 {
+		return recipe
+	}
+	recipe = recipe.Clone()
+	relocate := func(p string) string {
+		if to, ok := moved[p]; ok {
+			return to
+		}
+		return p
+	}
+	for i := range recipe.Steps {
+		for j := range recipe.Steps[i].RecipeIllustration {
+			illustration := &recipe.Steps[i].RecipeIllustration[j]
+			illustration.Filepath = relocate(illustration.Filepath)
+			for variant, p := range illustration.Variants {
+				illustration.Variants[variant] = relocate(p)
+			}
+		}
+	}
+	return recipe
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+// recipeAccess returns the access of a recipe; recipes nobody owns get the
+// zero RecipeAccess, which everyone may see and edit.
+func recipeAccess(ctx context.Context, accounts repository.AccountRepository, recipeID string) (domain.RecipeAccess, error) {
+	access, err := accounts.RecipeAccess(ctx, recipeID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.RecipeAccess{RecipeID: recipeID}, nil
+	}
+	return access, err
+}
+
+// authorize returns repository.ErrNotFound if the viewer may not see the
+// recipe, so that private recipes cannot be told from missing ones, and
+// ErrForbidden if edit is set and the viewer may see but not change it.
+func authorize(viewer domain.Viewer, access domain.RecipeAccess, edit bool) error {
+	if !viewer.CanSee(access) {
+		return repository.ErrNotFound
+	}
+	if edit && !viewer.CanEdit(access) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// visibleRecipes keeps the recipes the viewer may see.
+func visibleRecipes(ctx context.Context, accounts repository.AccountRepository, viewer domain.Viewer, recipes []domain.Recipe) ([]domain.Recipe, error) {
+	if viewer.Admin {
+		return recipes, nil
+	}
+	accesses, err := accounts.ListRecipeAccess(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible := recipes[:0]
+	for _, recipe := range recipes {
+		if access, ok := accesses[recipe.ID]; !ok || viewer.CanSee(access) {
+			visible = append(visible, recipe)
+		}
+	}
+	return visible, nil
+}
+
+type AuthorizeRecipeUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, recipeID string, edit bool) error
+}
+
+type authorizeRecipeUseCase struct {
+	accounts repository.AccountRepository
+}
+
+func NewAuthorizeRecipeUseCase(accounts repository.AccountRepository) AuthorizeRecipeUseCase {
+	return &authorizeRecipeUseCase{
+		accounts: accounts,
+	}
+}
+
+// Execute checks that the viewer may see a recipe, and change it if edit is
+// set. It returns repository.ErrNotFound for recipes hidden from the viewer
+// and ErrForbidden for recipes they may only see.
+func (uc *authorizeRecipeUseCase) Execute(ctx context.Context, viewer domain.Viewer, recipeID string, edit bool) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthorizeRecipe", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.Bool("edit", edit),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	access, err := recipeAccess(ctx, uc.accounts, recipeID)
+	if err != nil {
+		return err
+	}
+	return authorize(viewer, access, edit)
+}
+
+type SetRecipeVisibilityUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, recipeID string, visibility domain.Visibility, ownerID string) (*domain.RecipeAccess, error)
+}
+
+type setRecipeVisibilityUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewSetRecipeVisibilityUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) SetRecipeVisibilityUseCase {
+	return &setRecipeVisibilityUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute changes who may see a recipe. Only its owner and admins may. A
+// non-empty ownerID also hands the recipe to that user, which only admins
+// may do; recipes nobody owns are visible to everyone until they get one.
+func (uc *setRecipeVisibilityUseCase) Execute(ctx context.Context, viewer domain.Viewer, recipeID string, visibility domain.Visibility, ownerID string) (_ *domain.RecipeAccess, err error) {
+	ctx, span := tracer.Start(ctx, "SetRecipeVisibility", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.String("visibility", string(visibility)),
+		attribute.String("owner.id", ownerID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := uc.repo.RecipeVersion(ctx, recipeID); err != nil {
+		return nil, err
+	}
+	access, err := recipeAccess(ctx, uc.accounts, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(viewer, access, true); err != nil {
+		return nil, err
+	}
+	switch {
+	case ownerID != "":
+		if !viewer.Admin {
+			return nil, fmt.Errorf("%w: only admins may change the owner of a recipe", ErrForbidden)
+		}
+		owner, err := uc.accounts.FindUser(ctx, ownerID)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: unknown owner %q", ErrInvalidAccount, ownerID)
+		} else if err != nil {
+			return nil, err
+		}
+		access.OwnerID, access.HouseholdID = owner.ID, owner.HouseholdID
+	case access.OwnerID == "" && viewer.Admin:
+		return nil, fmt.Errorf("%w: recipe %s has no owner; give it one to change its visibility", ErrInvalidAccount, recipeID)
+	case access.OwnerID == "" || (access.OwnerID != viewer.UserID && !viewer.Admin):
+		return nil, fmt.Errorf("%w: only the owner of a recipe may change its visibility", ErrForbidden)
+	}
+	access.Visibility = visibility
+	access.UpdatedAt = time.Now().UTC()
+	if err := uc.accounts.SaveRecipeAccess(ctx, access); err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+// viewerVersion derives the version of the recipes a viewer may see from
+// the version of the whole collection: the entity tag also covers who the
+// viewer is and the access of every owned recipe, which together decide
+// which recipes are visible, and the stats of the reviews listed with them.
+func viewerVersion(ctx context.Context, accounts repository.AccountRepository, viewer domain.Viewer, version repository.Version) (repository.Version, error) {
+	accesses, err := accounts.ListRecipeAccess(ctx)
+	if err != nil {
+		return repository.Version{}, err
+	}
+	stats, err := accounts.ReviewStats(ctx)
+	if err != nil {
+		return repository.Version{}, err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s:%s:%t\n", version.ETag, viewer.UserID, viewer.HouseholdID, viewer.Admin)
+	for _, id := range slices.Sorted(maps.Keys(accesses)) {
+		access := accesses[id]
+		fmt.Fprintf(hash, "%s:%s:%s:%s\n", id, access.OwnerID, access.HouseholdID, access.Visibility)
+		if access.UpdatedAt.After(version.LastModified) {
+			version.LastModified = access.UpdatedAt
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(stats)) {
+		s := stats[id]
+		fmt.Fprintf(hash, "%s:%g:%d:%d:%s\n", id, s.AverageRating, s.RatingCount, s.TimesCooked, s.LastCooked)
+		if s.UpdatedAt.After(version.LastModified) {
+			version.LastModified = s.UpdatedAt
+		}
+	}
+	version.ETag = hex.EncodeToString(hash.Sum(nil)[:16])
+	return version, nil
+}
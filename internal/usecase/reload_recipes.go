@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+type ReloadRecipesUseCase interface {
+	Execute(ctx context.Context) (repository.ValidationReport, error)
+}
+
+type reloadRecipesUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewReloadRecipesUseCase(repo repository.RecipeRepository) ReloadRecipesUseCase {
+	return &reloadRecipesUseCase{
+		repo: repo,
+	}
+}
+
+// Execute reads the recipe files again and returns the new validation report.
+func (uc *reloadRecipesUseCase) Execute(ctx context.Context) (_ repository.ValidationReport, err error) {
+	ctx, span := tracer.Start(ctx, "ReloadRecipes")
+	defer func() { tracing.End(span, err) }()
+
+	if err := uc.repo.Reload(ctx); err != nil {
+		return repository.ValidationReport{}, err
+	}
+	return uc.repo.ValidationReport(ctx)
+}
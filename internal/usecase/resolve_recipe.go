@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+// RecipeRef locates a recipe asked for by ID, slug or alias.
+type RecipeRef struct {
+	ID string
+	// Redirect is the current slug of a recipe asked for by an alias left
+	// by renaming it, which clients should use instead.
+	Redirect string
+}
+
+type ResolveRecipeUseCase interface {
+	Execute(ctx context.Context, key string) (RecipeRef, error)
+}
+
+type resolveRecipeUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewResolveRecipeUseCase(repo repository.RecipeRepository) ResolveRecipeUseCase {
+	return &resolveRecipeUseCase{
+		repo: repo,
+	}
+}
+
+// Execute finds the recipe whose ID, slug or alias is key, in that order.
+func (uc *resolveRecipeUseCase) Execute(ctx context.Context, key string) (_ RecipeRef, err error) {
+	ctx, span := tracer.Start(ctx, "ResolveRecipe", trace.WithAttributes(attribute.String("recipe.key", key)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := uc.repo.RecipeVersion(ctx, key); !errors.Is(err, repository.ErrNotFound) {
+		return RecipeRef{ID: key}, err
+	}
+	id, alias, err := uc.repo.ResolveSlug(ctx, key)
+	if err != nil {
+		return RecipeRef{}, err
+	}
+	ref := RecipeRef{ID: id}
+	if alias {
+		recipe, err := uc.repo.FindByID(ctx, id)
+		if err != nil {
+			return RecipeRef{}, err
+		}
+		ref.Redirect = recipe.Slug
+	}
+	span.SetAttributes(attribute.String("recipe.id", id))
+	return ref, nil
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+// ErrInvalidSort is wrapped by errors about unknown recipe orders.
+var ErrInvalidSort = errors.New("invalid sort order")
+
+// RecipeSort orders listed recipes.
+type RecipeSort string
+
+const (
+	// SortByDefault keeps the order of the repository.
+	SortByDefault RecipeSort = ""
+	SortByName    RecipeSort = "name"
+	// SortByRating lists the best rated recipes first, unrated ones last.
+	SortByRating RecipeSort = "rating"
+	// SortByLastCooked lists the recipes cooked most recently first, those
+	// never cooked last.
+	SortByLastCooked RecipeSort = "last_cooked"
+)
+
+// ParseRecipeSort checks that s names a recipe order.
+func ParseRecipeSort(s string) (RecipeSort, error) {
+	switch sortBy := RecipeSort(s); sortBy {
+	case SortByDefault, SortByName, SortByRating, SortByLastCooked:
+		return sortBy, nil
+	}
+	return "", fmt.Errorf("%w %q, expected %s, %s or %s", ErrInvalidSort, s, SortByName, SortByRating, SortByLastCooked)
+}
+
+// sortSummaries orders summaries in place; ties are broken by name.
+func sortSummaries(summaries []domain.RecipeSummary, sortBy RecipeSort) {
+	less := func(a, b domain.RecipeSummary) bool { return a.Name < b.Name }
+	switch sortBy {
+	case SortByDefault:
+		return
+	case SortByRating:
+		less = func(a, b domain.RecipeSummary) bool {
+			if a.AverageRating != b.AverageRating {
+				return a.AverageRating > b.AverageRating
+			}
+			if a.RatingCount != b.RatingCount {
+				return a.RatingCount > b.RatingCount
+			}
+			return a.Name < b.Name
+		}
+	case SortByLastCooked:
+		less = func(a, b domain.RecipeSummary) bool {
+			if a.LastCooked != b.LastCooked {
+				return a.LastCooked > b.LastCooked
+			}
+			return a.Name < b.Name
+		}
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return less(summaries[i], summaries[j]) })
+}
+
+type ListRecipeSummariesUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, ingredientConstraint string, sortBy RecipeSort) ([]domain.RecipeSummary, error)
+}
+
+type listRecipeSummariesUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewListRecipeSummariesUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) ListRecipeSummariesUseCase {
+	return &listRecipeSummariesUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute lists the recipes the viewer may see with the stats of their
+// reviews, optionally only those using an ingredient, in the given order.
+func (uc *listRecipeSummariesUseCase) Execute(ctx context.Context, viewer domain.Viewer, ingredientConstraint string, sortBy RecipeSort) (_ []domain.RecipeSummary, err error) {
+	ctx, span := tracer.Start(ctx, "ListRecipeSummaries", trace.WithAttributes(
+		attribute.String("constraint.ingredient", ingredientConstraint),
+		attribute.String("sort", string(sortBy)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	recipes, err := uc.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if recipes, err = visibleRecipes(ctx, uc.accounts, viewer, recipes); err != nil {
+		return nil, err
+	}
+	stats, err := uc.accounts.ReviewStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]domain.RecipeSummary, 0, len(recipes))
+	for _, recipe := range recipes {
+		if ingredientConstraint != "" && !usesIngredient(recipe, ingredientConstraint) {
+			continue
+		}
+		summaries = append(summaries, domain.RecipeSummary{Recipe: recipe, RecipeStats: stats[recipe.ID]})
+	}
+	sortSummaries(summaries, sortBy)
+	span.SetAttributes(attribute.Int("recipe.count", len(summaries)))
+	return summaries, nil
+}
+
+func usesIngredient(recipe domain.Recipe, name string) bool {
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+type ListReviewsUseCase interface {
+	Execute(ctx context.Context, recipeID string) ([]domain.Review, error)
+}
+
+type listReviewsUseCase struct {
+	repo    repository.RecipeRepository
+	reviews repository.ReviewRepository
+}
+
+func NewListReviewsUseCase(repo repository.RecipeRepository, reviews repository.ReviewRepository) ListReviewsUseCase {
+	return &listReviewsUseCase{
+		repo:    repo,
+		reviews: reviews,
+	}
+}
+
+// Execute returns the reviews of a recipe, newest first.
+func (uc *listReviewsUseCase) Execute(ctx context.Context, recipeID string) (_ []domain.Review, err error) {
+	ctx, span := tracer.Start(ctx, "ListReviews", trace.WithAttributes(attribute.String("recipe.id", recipeID)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := uc.repo.RecipeVersion(ctx, recipeID); err != nil {
+		return nil, err
+	}
+	return uc.reviews.ListReviews(ctx, recipeID)
+}
+
+type GetReviewUseCase interface {
+	Execute(ctx context.Context, recipeID, reviewID string) (*domain.Review, error)
+}
+
+type getReviewUseCase struct {
+	reviews repository.ReviewRepository
+}
+
+func NewGetReviewUseCase(reviews repository.ReviewRepository) GetReviewUseCase {
+	return &getReviewUseCase{
+		reviews: reviews,
+	}
+}
+
+// Execute returns a review of a recipe.
+func (uc *getReviewUseCase) Execute(ctx context.Context, recipeID, reviewID string) (_ *domain.Review, err error) {
+	ctx, span := tracer.Start(ctx, "GetReview", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.String("review.id", reviewID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return uc.reviews.FindReview(ctx, recipeID, reviewID)
+}
+
+type AddReviewUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, recipeID string, review domain.Review, author string) (*domain.Review, error)
+}
+
+type addReviewUseCase struct {
+	repo    repository.RecipeRepository
+	reviews repository.ReviewRepository
+}
+
+func NewAddReviewUseCase(repo repository.RecipeRepository, reviews repository.ReviewRepository) AddReviewUseCase {
+	return &addReviewUseCase{
+		repo:    repo,
+		reviews: reviews,
+	}
+}
+
+// Execute records a review of a recipe written by author on behalf of the
+// viewer. The rating, notes, date, servings and adjustments are taken from
+// review; the rest is set here.
+func (uc *addReviewUseCase) Execute(ctx context.Context, viewer domain.Viewer, recipeID string, review domain.Review, author string) (_ *domain.Review, err error) {
+	ctx, span := tracer.Start(ctx, "AddReview", trace.WithAttributes(attribute.String("recipe.id", recipeID)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := uc.repo.RecipeVersion(ctx, recipeID); err != nil {
+		return nil, err
+	}
+	review = normalizeReview(review)
+	if errs := review.Validate(); errs != nil {
+		return nil, errs
+	}
+	if review.ID, err = newID(); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	review.RecipeID = recipeID
+	review.UserID = viewer.UserID
+	review.Author = author
+	review.CreatedAt, review.UpdatedAt = now, now
+	if err := uc.reviews.SaveReview(ctx, review); err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("review.id", review.ID))
+	return &review, nil
+}
+
+type UpdateReviewUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, recipeID, reviewID string, review domain.Review) (*domain.Review, error)
+}
+
+type updateReviewUseCase struct {
+	reviews repository.ReviewRepository
+}
+
+func NewUpdateReviewUseCase(reviews repository.ReviewRepository) UpdateReviewUseCase {
+	return &updateReviewUseCase{
+		reviews: reviews,
+	}
+}
+
+// Execute replaces the rating, notes, date, servings and adjustments of a
+// review the viewer may change.
+func (uc *updateReviewUseCase) Execute(ctx context.Context, viewer domain.Viewer, recipeID, reviewID string, review domain.Review) (_ *domain.Review, err error) {
+	ctx, span := tracer.Start(ctx, "UpdateReview", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.String("review.id", reviewID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	existing, err := uc.reviews.FindReview(ctx, recipeID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if !viewer.CanChangeReview(*existing) {
+		return nil, fmt.Errorf("%w: only the author of a review may change it", ErrForbidden)
+	}
+	review = normalizeReview(review)
+	if errs := review.Validate(); errs != nil {
+		return nil, errs
+	}
+	existing.Rating = review.Rating
+	existing.Notes = review.Notes
+	existing.CookedOn = review.CookedOn
+	existing.Servings = review.Servings
+	existing.Adjustments = review.Adjustments
+	existing.UpdatedAt = time.Now().UTC()
+	if err := uc.reviews.SaveReview(ctx, *existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+type DeleteReviewUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, recipeID, reviewID string) error
+}
+
+type deleteReviewUseCase struct {
+	reviews repository.ReviewRepository
+}
+
+func NewDeleteReviewUseCase(reviews repository.ReviewRepository) DeleteReviewUseCase {
+	return &deleteReviewUseCase{
+		reviews: reviews,
+	}
+}
+
+// Execute deletes a review the viewer may change.
+func (uc *deleteReviewUseCase) Execute(ctx context.Context, viewer domain.Viewer, recipeID, reviewID string) (err error) {
+	ctx, span := tracer.Start(ctx, "DeleteReview", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.String("review.id", reviewID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	existing, err := uc.reviews.FindReview(ctx, recipeID, reviewID)
+	if err != nil {
+		return err
+	}
+	if !viewer.CanChangeReview(*existing) {
+		return fmt.Errorf("%w: only the author of a review may delete it", ErrForbidden)
+	}
+	return uc.reviews.DeleteReview(ctx, recipeID, reviewID)
+}
+
+// normalizeReview trims the free text of a review.
+func normalizeReview(review domain.Review) domain.Review {
+	review.Notes = strings.TrimSpace(review.Notes)
+	review.CookedOn = strings.TrimSpace(review.CookedOn)
+	return review
+}
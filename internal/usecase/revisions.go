@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+type ListRevisionsUseCase interface {
+	Execute(ctx context.Context, recipeID string) ([]domain.Revision, error)
+}
+
+type listRevisionsUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewListRevisionsUseCase(repo repository.RecipeRepository) ListRevisionsUseCase {
+	return &listRevisionsUseCase{
+		repo: repo,
+	}
+}
+
+// Execute returns the revision history of a recipe, oldest first.
+func (uc *listRevisionsUseCase) Execute(ctx context.Context, recipeID string) (_ []domain.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "ListRevisions", trace.WithAttributes(attribute.String("recipe.id", recipeID)))
+	defer func() { tracing.End(span, err) }()
+
+	return uc.repo.ListRevisions(ctx, recipeID)
+}
+
+type GetRevisionUseCase interface {
+	Execute(ctx context.Context, recipeID string, number int) (*domain.Revision, error)
+}
+
+type getRevisionUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewGetRevisionUseCase(repo repository.RecipeRepository) GetRevisionUseCase {
+	return &getRevisionUseCase{
+		repo: repo,
+	}
+}
+
+// Execute returns a single revision of a recipe.
+func (uc *getRevisionUseCase) Execute(ctx context.Context, recipeID string, number int) (_ *domain.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "GetRevision", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.Int("revision.number", number),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return uc.repo.FindRevision(ctx, recipeID, number)
+}
+
+type DiffRevisionsUseCase interface {
+	Execute(ctx context.Context, recipeID string, from, to int) (*domain.RecipeDiff, error)
+}
+
+type diffRevisionsUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewDiffRevisionsUseCase(repo repository.RecipeRepository) DiffRevisionsUseCase {
+	return &diffRevisionsUseCase{
+		repo: repo,
+	}
+}
+
+// Execute compares two revisions of a recipe.
+func (uc *diffRevisionsUseCase) Execute(ctx context.Context, recipeID string, from, to int) (_ *domain.RecipeDiff, err error) {
+	ctx, span := tracer.Start(ctx, "DiffRevisions", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.Int("revision.from", from),
+		attribute.Int("revision.to", to),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	fromRevision, err := uc.repo.FindRevision(ctx, recipeID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := uc.repo.FindRevision(ctx, recipeID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := domain.DiffRecipes(fromRevision.Recipe, toRevision.Recipe)
+	diff.FromRevision = from
+	diff.ToRevision = to
+	return &diff, nil
+}
+
+type RevertRecipeUseCase interface {
+	Execute(ctx context.Context, recipeID string, number int, author string) (*domain.Revision, error)
+}
+
+type revertRecipeUseCase struct {
+	repo repository.RecipeRepository
+}
+
+func NewRevertRecipeUseCase(repo repository.RecipeRepository) RevertRecipeUseCase {
+	return &revertRecipeUseCase{
+		repo: repo,
+	}
+}
+
+// Execute restores the content of an earlier revision. History is append-only,
+// so the revert is itself recorded as a new revision.
+func (uc *revertRecipeUseCase) Execute(ctx context.Context, recipeID string, number int, author string) (_ *domain.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "RevertRecipe", trace.WithAttributes(
+		attribute.String("recipe.id", recipeID),
+		attribute.Int("revision.number", number),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	revision, err := uc.repo.FindRevision(ctx, recipeID, number)
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.Save(ctx, revision.Recipe, author)
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/tracing"
+)
+
+type SaveRecipeUseCase interface {
+	Execute(ctx context.Context, viewer domain.Viewer, recipe domain.Recipe, author string) (*domain.Revision, error)
+}
+
+type saveRecipeUseCase struct {
+	repo     repository.RecipeRepository
+	accounts repository.AccountRepository
+}
+
+func NewSaveRecipeUseCase(repo repository.RecipeRepository, accounts repository.AccountRepository) SaveRecipeUseCase {
+	return &saveRecipeUseCase{
+		repo:     repo,
+		accounts: accounts,
+	}
+}
+
+// Execute creates or replaces a recipe, recording a new revision on behalf of
+// author. A recipe without ID is created with a new one. Existing recipes
+// must be editable by the viewer; new recipes created by a user are owned by
+// them and private.
+func (uc *saveRecipeUseCase) Execute(ctx context.Context, viewer domain.Viewer, recipe domain.Recipe, author string) (_ *domain.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "SaveRecipe")
+	defer func() { tracing.End(span, err) }()
+
+	if recipe.ID == "" {
+		if recipe.ID, err = domain.NewRecipeID(); err != nil {
+			return nil, err
+		}
+	}
+	span.SetAttributes(attribute.String("recipe.id", recipe.ID))
+
+	_, err = uc.repo.RecipeVersion(ctx, recipe.ID)
+	exists := err == nil
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	access, err := recipeAccess(ctx, uc.accounts, recipe.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(viewer, access, true); err != nil {
+		return nil, err
+	}
+
+	revision, err := uc.repo.Save(ctx, recipe, author)
+	if err != nil {
+		return nil, err
+	}
+	if !exists && access.OwnerID == "" && viewer.UserID != "" {
+		access = domain.RecipeAccess{
+			RecipeID:   recipe.ID,
+			OwnerID:    viewer.UserID,
+			Visibility: domain.VisibilityPrivate,
+			UpdatedAt:  time.Now().UTC(),
+		}
+		if err := uc.accounts.SaveRecipeAccess(ctx, access); err != nil {
+			return nil, fmt.Errorf("failed to record owner of recipe %s: %w", recipe.ID, err)
+		}
+	}
+	return revision, nil
+}
@@ -0,0 +1,8 @@
+package usecase
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+// tracer records a span for every use case execution.
+var tracer = otel.Tracer("github.com/fromenjn/recipe-manager/internal/usecase")
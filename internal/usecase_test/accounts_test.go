@@ -0,0 +1,210 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func newAccounts(t *testing.T) repository.AccountRepository {
+	t.Helper()
+	accounts, err := repository.NewJSONAccountRepository(filepath.Join(t.TempDir(), "accounts.json"))
+	if err != nil {
+		t.Fatalf("failed to create accounts repository: %v", err)
+	}
+	return accounts
+}
+
+func recipeIDs(recipes []domain.Recipe) []string {
+	ids := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestLoginUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	accounts := newAccounts(t)
+	if _, err := usecase.NewRegisterUserUseCase(accounts).Execute(ctx, "Alice", "correct horse"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := usecase.NewRegisterUserUseCase(accounts).Execute(ctx, "bob", "short"); !errors.Is(err, usecase.ErrInvalidAccount) {
+		t.Errorf("expected ErrInvalidAccount for a short password, got %v", err)
+	}
+
+	login := usecase.NewLoginUseCase(accounts, time.Hour)
+	if _, err := login.Execute(ctx, "alice", "wrong password"); !errors.Is(err, usecase.ErrInvalidLogin) {
+		t.Errorf("expected ErrInvalidLogin for a wrong password, got %v", err)
+	}
+	if _, err := login.Execute(ctx, "nobody", "correct horse"); !errors.Is(err, usecase.ErrInvalidLogin) {
+		t.Errorf("expected ErrInvalidLogin for an unknown user, got %v", err)
+	}
+	result, err := login.Execute(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	authenticate := usecase.NewAuthenticateSessionUseCase(accounts)
+	user, err := authenticate.Execute(ctx, result.Token)
+	if err != nil || user.Username != "alice" {
+		t.Errorf("expected the session of alice, got %+v, %v", user, err)
+	}
+	if err := usecase.NewLogoutUseCase(accounts).Execute(ctx, result.Token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := authenticate.Execute(ctx, result.Token); !errors.Is(err, repository.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound after logging out, got %v", err)
+	}
+}
+
+func TestRecipeVisibility(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{recipes: map[string]domain.Recipe{"legacy": {ID: "legacy", Name: "Bread"}}}
+	accounts := newAccounts(t)
+	alice := domain.Viewer{UserID: "alice", HouseholdID: "home"}
+	bob := domain.Viewer{UserID: "bob", HouseholdID: "home"}
+	carol := domain.Viewer{UserID: "carol"}
+	for _, viewer := range []domain.Viewer{alice, bob, carol} {
+		if err := accounts.CreateUser(ctx, domain.User{ID: viewer.UserID, Username: viewer.UserID, HouseholdID: viewer.HouseholdID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	save := usecase.NewSaveRecipeUseCase(repo, accounts)
+	if _, err := save.Execute(ctx, alice, domain.Recipe{ID: "soup", Name: "Soup"}, "alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list := usecase.NewGetAllRecipesUseCase(repo, accounts)
+	check := func(viewer domain.Viewer, want ...string) {
+		t.Helper()
+		recipes, err := list.Execute(ctx, viewer, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := recipeIDs(recipes); !slices.Equal(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+
+	// New recipes are private to their owner.
+	check(alice, "legacy", "soup")
+	check(bob, "legacy")
+	check(domain.Viewer{}, "legacy")
+	check(domain.Viewer{Admin: true}, "legacy", "soup")
+	if _, err := save.Execute(ctx, bob, domain.Recipe{ID: "soup", Name: "Stolen soup"}, "bob"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected private recipes to be hidden from others, got %v", err)
+	}
+
+	visibility := usecase.NewSetRecipeVisibilityUseCase(repo, accounts)
+	if _, err := visibility.Execute(ctx, alice, "soup", domain.VisibilityHousehold, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(bob, "legacy", "soup")
+	check(carol, "legacy")
+	if _, err := visibility.Execute(ctx, bob, "soup", domain.VisibilityPublic, ""); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected only the owner to change visibility, got %v", err)
+	}
+
+	// Household recipes follow their owner to a new household.
+	if err := accounts.UpdateUser(ctx, domain.User{ID: "alice", Username: "alice", HouseholdID: "work"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(bob, "legacy")
+	check(domain.Viewer{UserID: "dave", HouseholdID: "work"}, "legacy", "soup")
+	alice.HouseholdID = "work"
+
+	if _, err := visibility.Execute(ctx, alice, "soup", domain.VisibilityPublic, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(carol, "legacy", "soup")
+	if _, err := save.Execute(ctx, carol, domain.Recipe{ID: "soup", Name: "Soup"}, "carol"); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected public recipes to be read-only for strangers, got %v", err)
+	}
+
+	// Recipes nobody owns stay visible to everyone until an admin hands
+	// them to a user.
+	if _, err := visibility.Execute(ctx, carol, "legacy", domain.VisibilityPrivate, ""); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected users not to claim recipes nobody owns, got %v", err)
+	}
+	if _, err := visibility.Execute(ctx, carol, "legacy", domain.VisibilityPrivate, "carol"); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected only admins to assign owners, got %v", err)
+	}
+	admin := domain.Viewer{Admin: true}
+	if _, err := visibility.Execute(ctx, admin, "legacy", domain.VisibilityPrivate, ""); !errors.Is(err, usecase.ErrInvalidAccount) {
+		t.Errorf("expected recipes nobody owns to need an owner, got %v", err)
+	}
+	if _, err := visibility.Execute(ctx, admin, "legacy", domain.VisibilityPrivate, "dave"); !errors.Is(err, usecase.ErrInvalidAccount) {
+		t.Errorf("expected unknown owners to be refused, got %v", err)
+	}
+	check(carol, "legacy", "soup")
+	access, err := visibility.Execute(ctx, admin, "legacy", domain.VisibilityPrivate, "carol")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if access.OwnerID != "carol" {
+		t.Errorf("expected carol to own the recipe, got %+v", access)
+	}
+	check(carol, "legacy", "soup")
+	check(bob, "soup")
+
+	// The version of the listing changes with the visibility of its recipes.
+	version := usecase.NewGetCollectionVersionUseCase(repo, accounts)
+	before, err := version.Execute(ctx, carol)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := visibility.Execute(ctx, alice, "soup", domain.VisibilityPrivate, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	after, _ := version.Execute(ctx, carol)
+	if before.ETag == after.ETag {
+		t.Error("expected the ETag to change with the visibility of a recipe")
+	}
+}
+
+func TestSaveCollectionUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{recipes: map[string]domain.Recipe{"1": {ID: "1", Name: "Pancakes"}}}
+	accounts := newAccounts(t)
+	alice := domain.Viewer{UserID: "alice"}
+	save := usecase.NewSaveCollectionUseCase(repo, accounts)
+
+	if _, err := save.Execute(ctx, domain.Viewer{}, domain.Collection{Name: "Weeknight"}); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected collections to need a user, got %v", err)
+	}
+	if _, err := save.Execute(ctx, alice, domain.Collection{Name: " "}); !errors.Is(err, usecase.ErrInvalidAccount) {
+		t.Errorf("expected a name to be required, got %v", err)
+	}
+	if _, err := save.Execute(ctx, alice, domain.Collection{Name: "Weeknight", RecipeIDs: []string{"2"}}); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected unknown recipes to be refused, got %v", err)
+	}
+
+	created, err := save.Execute(ctx, alice, domain.Collection{Name: "Weeknight", RecipeIDs: []string{"1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID == "" || created.UserID != "alice" {
+		t.Errorf("expected a new collection of alice, got %+v", created)
+	}
+	if _, err := save.Execute(ctx, domain.Viewer{UserID: "bob"}, domain.Collection{ID: created.ID, Name: "Mine"}); !errors.Is(err, repository.ErrCollectionNotFound) {
+		t.Errorf("expected collections of others to be hidden, got %v", err)
+	}
+
+	if err := usecase.NewSetFavoriteUseCase(repo, accounts).Execute(ctx, alice, "1", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	favorites, err := usecase.NewListFavoritesUseCase(repo, accounts).Execute(ctx, alice)
+	if err != nil || len(favorites) != 1 || favorites[0].ID != "1" {
+		t.Errorf("expected Pancakes as favorite, got %+v, %v", favorites, err)
+	}
+}
@@ -0,0 +1,59 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func TestGetCookbookUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{recipes: map[string]domain.Recipe{
+		"1": {ID: "1", Name: "pancakes"},
+		"2": {ID: "2", Name: "Omelette"},
+		"3": {ID: "3", Name: "Bread"},
+	}}
+	accounts := newAccounts(t)
+	alice := domain.Viewer{UserID: "alice"}
+	if _, err := usecase.NewSaveRecipeUseCase(repo, accounts).Execute(ctx, alice, domain.Recipe{ID: "4", Name: "Secret soup"}, "alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	collection, err := usecase.NewSaveCollectionUseCase(repo, accounts).Execute(ctx, alice, domain.Collection{Name: "Brunch", RecipeIDs: []string{"2", "4", "1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	get := usecase.NewGetCookbookUseCase(repo, accounts)
+	check := func(viewer domain.Viewer, selection usecase.CookbookSelection, wantTitle string, want ...string) {
+		t.Helper()
+		cookbook, err := get.Execute(ctx, viewer, selection)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []string
+		for _, recipe := range cookbook.Recipes {
+			ids = append(ids, recipe.ID)
+		}
+		if cookbook.Title != wantTitle || !slices.Equal(ids, want) {
+			t.Errorf("expected %q with %v, got %q with %v", wantTitle, want, cookbook.Title, ids)
+		}
+	}
+
+	// Every visible recipe, by name; the given ones in order; or a collection.
+	check(domain.Viewer{}, usecase.CookbookSelection{}, usecase.DefaultCookbookTitle, "3", "2", "1")
+	check(alice, usecase.CookbookSelection{}, usecase.DefaultCookbookTitle, "3", "2", "1", "4")
+	check(domain.Viewer{}, usecase.CookbookSelection{RecipeIDs: []string{"2", "3", "2"}}, usecase.DefaultCookbookTitle, "2", "3")
+	check(alice, usecase.CookbookSelection{CollectionID: collection.ID}, "Brunch", "2", "4", "1")
+
+	if _, err := get.Execute(ctx, domain.Viewer{}, usecase.CookbookSelection{RecipeIDs: []string{"1", "4"}}); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected hidden recipes to be refused, got %v", err)
+	}
+	if _, err := get.Execute(ctx, domain.Viewer{}, usecase.CookbookSelection{CollectionID: collection.ID}); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected collections to need a user, got %v", err)
+	}
+}
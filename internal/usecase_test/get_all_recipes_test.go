@@ -1,6 +1,7 @@
 package usecase_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -22,7 +23,7 @@ func TestMockRepo_ListAll_Success(t *testing.T) {
 	}
 
 	// Call ListAll
-	recipes, err := repo.ListAll()
+	recipes, err := repo.ListAll(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -57,7 +58,7 @@ func TestMockRepo_ListAll_Error(t *testing.T) {
 		err:     errors.New("some error"),
 	}
 
-	_, err := repo.ListAll()
+	_, err := repo.ListAll(context.Background())
 	if err == nil {
 		t.Error("expected error, got none")
 	}
@@ -74,7 +75,7 @@ func TestMockRepo_FindByID_Success(t *testing.T) {
 		},
 	}
 
-	recipe, err := repo.FindByID("1")
+	recipe, err := repo.FindByID(context.Background(), "1")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -94,7 +95,7 @@ func TestMockRepo_FindByID_NotFound(t *testing.T) {
 		},
 	}
 
-	_, err := repo.FindByID("999")
+	_, err := repo.FindByID(context.Background(), "999")
 	if err == nil {
 		t.Error("expected an error for missing recipe, got none")
 	}
@@ -1,32 +1,37 @@
 package usecase_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
 	"github.com/fromenjn/recipe-manager/internal/usecase"
 )
 
 // mockRepo is a mock implementation of the repository.RecipeRepository.
 type mockRepo struct {
-	recipes map[string]domain.Recipe
-	err     error
+	recipes   map[string]domain.Recipe
+	revisions map[string][]domain.Revision
+	err       error
 }
 
-func (m *mockRepo) FindByID(id string) (*domain.Recipe, error) {
+func (m *mockRepo) FindByID(ctx context.Context, id string) (*domain.Recipe, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 	r, ok := m.recipes[id]
 	if !ok {
-		return nil, errors.New("recipe not found")
+		return nil, repository.ErrNotFound
 	}
 	return &r, nil
 }
 
 // ListAll returns all recipes in the map as a slice, or an error if err != nil.
-func (m *mockRepo) ListAll() ([]domain.Recipe, error) {
+func (m *mockRepo) ListAll(ctx context.Context) ([]domain.Recipe, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -39,6 +44,98 @@ func (m *mockRepo) ListAll() ([]domain.Recipe, error) {
 	return results, nil
 }
 
+// ResolveSlug finds the recipe with a slug, then with an alias.
+func (m *mockRepo) ResolveSlug(ctx context.Context, slug string) (string, bool, error) {
+	if m.err != nil {
+		return "", false, m.err
+	}
+	for id, recipe := range m.recipes {
+		if recipe.Slug == slug {
+			return id, false, nil
+		}
+	}
+	for id, recipe := range m.recipes {
+		if slices.Contains(recipe.Aliases, slug) {
+			return id, true, nil
+		}
+	}
+	return "", false, repository.ErrNotFound
+}
+
+// RecipeVersion returns a version derived from the recipe name, or an error if err != nil.
+func (m *mockRepo) RecipeVersion(ctx context.Context, id string) (repository.Version, error) {
+	if m.err != nil {
+		return repository.Version{}, m.err
+	}
+	r, ok := m.recipes[id]
+	if !ok {
+		return repository.Version{}, repository.ErrNotFound
+	}
+	return repository.Version{ETag: r.ID + "-" + r.Name}, nil
+}
+
+// CollectionVersion returns a version derived from the number of recipes.
+func (m *mockRepo) CollectionVersion(ctx context.Context) (repository.Version, error) {
+	if m.err != nil {
+		return repository.Version{}, m.err
+	}
+	return repository.Version{ETag: fmt.Sprintf("%d", len(m.recipes))}, nil
+}
+
+// Save stores the recipe and appends a revision to its history.
+func (m *mockRepo) Save(ctx context.Context, recipe domain.Recipe, author string) (*domain.Revision, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.recipes == nil {
+		m.recipes = make(map[string]domain.Recipe)
+	}
+	if m.revisions == nil {
+		m.revisions = make(map[string][]domain.Revision)
+	}
+	m.recipes[recipe.ID] = recipe
+	revision := domain.Revision{
+		Number: len(m.revisions[recipe.ID]) + 1,
+		Author: author,
+		Recipe: recipe,
+	}
+	m.revisions[recipe.ID] = append(m.revisions[recipe.ID], revision)
+	return &revision, nil
+}
+
+// ListRevisions returns the recorded history of a recipe.
+func (m *mockRepo) ListRevisions(ctx context.Context, id string) ([]domain.Revision, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	history, ok := m.revisions[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return history, nil
+}
+
+// FindRevision returns a single revision by number.
+func (m *mockRepo) FindRevision(ctx context.Context, id string, number int) (*domain.Revision, error) {
+	history, err := m.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if number < 1 || number > len(history) {
+		return nil, repository.ErrRevisionNotFound
+	}
+	return &history[number-1], nil
+}
+
+// ValidationReport returns an empty report.
+func (m *mockRepo) ValidationReport(ctx context.Context) (repository.ValidationReport, error) {
+	return repository.ValidationReport{Loaded: len(m.recipes)}, nil
+}
+
+func (m *mockRepo) Reload(ctx context.Context) error {
+	return m.err
+}
+
 // mockService is a mock implementation of the domain.RecipeService.
 type mockService struct {
 	computeErr error
@@ -57,6 +154,16 @@ func (m *mockService) ComputeRatios(recipe *domain.Recipe, constraintName string
 	return m.computeErr
 }
 
+func (m *mockService) Scale(recipe *domain.Recipe, factor float64) error {
+	if m.computeErr != nil {
+		return m.computeErr
+	}
+	for i := range recipe.Ingredients {
+		recipe.Ingredients[i].Quantity *= factor
+	}
+	return nil
+}
+
 func TestGetRecipeUseCase_Execute_NoScaling(t *testing.T) {
 	// Setup
 	repo := &mockRepo{
@@ -72,7 +179,7 @@ func TestGetRecipeUseCase_Execute_NoScaling(t *testing.T) {
 	uc := usecase.NewGetRecipeUseCase(repo, service)
 
 	// Execute use case without constraints
-	result, err := uc.Execute("1", "", 0)
+	result, err := uc.Execute(context.Background(), "1", "", 0)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -109,7 +216,7 @@ func TestGetRecipeUseCase_Execute_WithScaling(t *testing.T) {
 	uc := usecase.NewGetRecipeUseCase(repo, service)
 
 	// Execute with constraints
-	result, err := uc.Execute("1", "Flour", 500)
+	result, err := uc.Execute(context.Background(), "1", "Flour", 500)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -135,7 +242,7 @@ func TestGetRecipeUseCase_Execute_RecipeNotFound(t *testing.T) {
 	uc := usecase.NewGetRecipeUseCase(repo, service)
 
 	// Execute with non-existent ID
-	_, err := uc.Execute("999", "", 0)
+	_, err := uc.Execute(context.Background(), "999", "", 0)
 	if err == nil {
 		t.Error("expected error for missing recipe, got none")
 	}
@@ -156,7 +263,7 @@ func TestGetRecipeUseCase_Execute_ServiceError(t *testing.T) {
 	}
 	uc := usecase.NewGetRecipeUseCase(repo, service)
 
-	_, err := uc.Execute("1", "Flour", 500)
+	_, err := uc.Execute(context.Background(), "1", "Flour", 500)
 	if err == nil {
 		t.Error("expected error from service, got none")
 	}
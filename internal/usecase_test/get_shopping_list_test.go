@@ -0,0 +1,31 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func TestGetShoppingListUseCase_Execute(t *testing.T) {
+	repo := &mockRepo{
+		recipes: map[string]domain.Recipe{
+			"1": {ID: "1", Name: "Pancakes", Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 200, Unit: "g"}}},
+			"2": {ID: "2", Name: "Cake", Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 100, Unit: "g"}}},
+		},
+	}
+	uc := usecase.NewGetShoppingListUseCase(repo, &mockService{})
+
+	items, err := uc.Execute(context.Background(), []usecase.ShoppingListEntry{{RecipeID: "1"}, {RecipeID: "2", Factor: 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].Quantity != 400 {
+		t.Errorf("expected 400 g of Flour, got %+v", items)
+	}
+
+	if _, err := uc.Execute(context.Background(), []usecase.ShoppingListEntry{{RecipeID: "999"}}); err == nil {
+		t.Error("expected error for missing recipe, got none")
+	}
+}
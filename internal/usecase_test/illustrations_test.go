@@ -0,0 +1,261 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/imaging"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+// mockImageStore is an in-memory implementation of repository.ImageStore.
+type mockImageStore struct {
+	files map[string][]byte
+}
+
+func (m *mockImageStore) Save(ctx context.Context, name string, data []byte) error {
+	if m.files == nil {
+		m.files = make(map[string][]byte)
+	}
+	m.files[name] = data
+	return nil
+}
+
+func (m *mockImageStore) Open(ctx context.Context, name string) (*repository.ImageFile, error) {
+	data, ok := m.files[name]
+	if !ok {
+		return nil, repository.ErrImageNotFound
+	}
+	return &repository.ImageFile{ReadSeekCloser: nopCloser{bytes.NewReader(data)}, Size: int64(len(data))}, nil
+}
+
+func (m *mockImageStore) Delete(ctx context.Context, name string) error {
+	delete(m.files, name)
+	return nil
+}
+
+type nopCloser struct{ io.ReadSeeker }
+
+func (nopCloser) Close() error { return nil }
+
+func pngUpload(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 600, 300))); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadIllustrationUseCase_Execute(t *testing.T) {
+	repo := &mockRepo{
+		recipes: map[string]domain.Recipe{
+			"1": {ID: "1", Name: "Pancakes", Steps: []domain.RecipeStep{{
+				ID:                 "step1",
+				RecipeIllustration: []domain.RecipeIllustration{{ID: "illustration1"}},
+			}}},
+		},
+	}
+	images := &mockImageStore{}
+	uc := usecase.NewUploadIllustrationUseCase(repo, images)
+
+	illustration, err := uc.Execute(context.Background(), "1", "step1", "Batter", pngUpload(t), "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if illustration.ID != "illustration2" {
+		t.Errorf("expected ID 'illustration2', got '%s'", illustration.ID)
+	}
+	if illustration.Filepath != "/images/1/illustration2.png" {
+		t.Errorf("unexpected filepath %s", illustration.Filepath)
+	}
+	for _, variant := range []string{domain.VariantThumbnail, domain.VariantWebP, domain.VariantThumbnailWebP} {
+		path, ok := illustration.Variants[variant]
+		if !ok {
+			t.Errorf("expected variant %s", variant)
+			continue
+		}
+		if _, ok := images.files[strings.TrimPrefix(path, usecase.ImageURLPrefix)]; !ok {
+			t.Errorf("expected variant %s to be stored at %s", variant, path)
+		}
+	}
+	if len(images.files) != 4 {
+		t.Errorf("expected 4 stored files, got %d", len(images.files))
+	}
+
+	thumbnail, err := imaging.Decode(images.files["1/illustration2_thumb.png"])
+	if err != nil {
+		t.Fatalf("failed to decode thumbnail: %v", err)
+	}
+	if thumbnail.Bounds().Dx() != usecase.ThumbnailSize {
+		t.Errorf("expected thumbnail width %d, got %d", usecase.ThumbnailSize, thumbnail.Bounds().Dx())
+	}
+
+	saved := repo.recipes["1"]
+	if len(saved.Steps[0].RecipeIllustration) != 2 {
+		t.Fatalf("expected the illustration to be added to the step, got %+v", saved.Steps[0])
+	}
+	if repo.revisions["1"][0].Author != "alice" {
+		t.Errorf("expected revision by alice, got %s", repo.revisions["1"][0].Author)
+	}
+}
+
+func TestUploadIllustrationUseCase_Execute_Errors(t *testing.T) {
+	repo := &mockRepo{
+		recipes: map[string]domain.Recipe{
+			"1": {ID: "1", Steps: []domain.RecipeStep{{ID: "step1"}}},
+		},
+	}
+	uc := usecase.NewUploadIllustrationUseCase(repo, &mockImageStore{})
+
+	if _, err := uc.Execute(context.Background(), "1", "step9", "", pngUpload(t), "alice"); !errors.Is(err, usecase.ErrStepNotFound) {
+		t.Errorf("expected ErrStepNotFound, got %v", err)
+	}
+	if _, err := uc.Execute(context.Background(), "1", "step1", "", []byte("plain text"), "alice"); !errors.Is(err, imaging.ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+	if _, err := uc.Execute(context.Background(), "999", "step1", "", pngUpload(t), "alice"); err == nil {
+		t.Error("expected error for missing recipe, got none")
+	}
+}
+
+func TestUploadIllustrationUseCase_Execute_Concurrent(t *testing.T) {
+	repo := &mockRepo{
+		recipes: map[string]domain.Recipe{
+			"1": {ID: "1", Steps: []domain.RecipeStep{{ID: "step1"}}},
+		},
+	}
+	uc := usecase.NewUploadIllustrationUseCase(repo, &mockImageStore{})
+	upload := pngUpload(t)
+
+	const uploads = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, uploads)
+	for i := 0; i < uploads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := uc.Execute(context.Background(), "1", "step1", "", upload, "alice")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	ids := make(map[string]bool)
+	for _, illustration := range repo.recipes["1"].Steps[0].RecipeIllustration {
+		ids[illustration.ID] = true
+	}
+	if len(ids) != uploads {
+		t.Errorf("expected %d distinct illustrations, got %v", uploads, ids)
+	}
+}
+
+func TestUploadIllustrationUseCase_Execute_SimilarIDs(t *testing.T) {
+	// These IDs would share a directory if their unsafe characters were
+	// simply replaced.
+	ids := []string{"a.b", "a_b", "desserts/cake", "desserts_cake"}
+	repo := &mockRepo{recipes: map[string]domain.Recipe{}}
+	for _, id := range ids {
+		repo.recipes[id] = domain.Recipe{ID: id, Steps: []domain.RecipeStep{{ID: "step1"}}}
+	}
+	images := &mockImageStore{}
+	uc := usecase.NewUploadIllustrationUseCase(repo, images)
+	find := usecase.NewFindImageRecipesUseCase(repo)
+
+	paths := make(map[string]string)
+	for _, id := range ids {
+		illustration, err := uc.Execute(context.Background(), id, "step1", "", pngUpload(t), "alice")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if other, ok := paths[illustration.Filepath]; ok {
+			t.Errorf("expected recipes %s and %s to get different files, both got %s", other, id, illustration.Filepath)
+		}
+		paths[illustration.Filepath] = id
+
+		found, err := find.Execute(context.Background(), strings.TrimPrefix(illustration.Filepath, usecase.ImageURLPrefix))
+		if err != nil || len(found) != 1 || found[0] != id {
+			t.Errorf("expected %s to illustrate recipe %s, got %v, %v", illustration.Filepath, id, found, err)
+		}
+	}
+	if len(images.files) != 4*len(ids) {
+		t.Errorf("expected every upload to keep its files, got %d files", len(images.files))
+	}
+}
+
+// failingSaveRepo fails to save recipes.
+type failingSaveRepo struct {
+	*mockRepo
+}
+
+func (failingSaveRepo) Save(ctx context.Context, recipe domain.Recipe, author string) (*domain.Revision, error) {
+	return nil, errors.New("disk full")
+}
+
+func TestUploadIllustrationUseCase_Execute_SaveFails(t *testing.T) {
+	repo := failingSaveRepo{&mockRepo{
+		recipes: map[string]domain.Recipe{
+			"1": {ID: "1", Steps: []domain.RecipeStep{{ID: "step1"}}},
+		},
+	}}
+	images := &mockImageStore{}
+	uc := usecase.NewUploadIllustrationUseCase(repo, images)
+
+	if _, err := uc.Execute(context.Background(), "1", "step1", "", pngUpload(t), "alice"); err == nil {
+		t.Fatal("expected the failed save to fail the upload")
+	}
+	if len(images.files) != 0 {
+		t.Errorf("expected the stored files to be removed, got %d", len(images.files))
+	}
+}
+
+func TestFindImageRecipesUseCase_Execute(t *testing.T) {
+	illustrated := func(id, path string) domain.Recipe {
+		return domain.Recipe{ID: id, Steps: []domain.RecipeStep{{
+			ID: "step1",
+			RecipeIllustration: []domain.RecipeIllustration{{
+				Filepath: path,
+				Variants: map[string]string{domain.VariantThumbnail: strings.Replace(path, ".jpg", "_thumb.jpg", 1)},
+			}},
+		}}}
+	}
+	repo := &mockRepo{
+		recipes: map[string]domain.Recipe{
+			"1": illustrated("1", "/images/1/illustration1.jpg"),
+			"2": illustrated("2", "/images/chocolate_cake/step1.jpg"),
+		},
+	}
+	uc := usecase.NewFindImageRecipesUseCase(repo)
+	ctx := context.Background()
+
+	for name, want := range map[string]string{
+		"1/illustration1.jpg":            "1",
+		"1/illustration1_thumb.jpg":      "1",
+		"chocolate_cake/step1.jpg":       "2",
+		"chocolate_cake/step1_thumb.jpg": "2",
+	} {
+		ids, err := uc.Execute(ctx, name)
+		if err != nil || len(ids) != 1 || ids[0] != want {
+			t.Errorf("expected %s to illustrate recipe %s, got %v, %v", name, want, ids, err)
+		}
+	}
+	if _, err := uc.Execute(ctx, "1/orphan.jpg"); !errors.Is(err, repository.ErrImageNotFound) {
+		t.Errorf("expected ErrImageNotFound for an image no recipe uses, got %v", err)
+	}
+}
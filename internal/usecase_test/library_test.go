@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func TestLibraryUseCases(t *testing.T) {
+	ctx := context.Background()
+	admin := domain.Viewer{UserID: "admin", Admin: true}
+	cake := domain.Recipe{ID: "2", Name: "Chocolate Cake", Ingredients: []domain.Ingredient{}, Steps: []domain.RecipeStep{{
+		ID: "step1", RecipeIllustration: []domain.RecipeIllustration{{ID: "i1", Filepath: "/images/2/step1.jpg"}},
+	}}}
+	source := &mockRepo{recipes: map[string]domain.Recipe{
+		"1": {ID: "1", Name: "Pancakes", Ingredients: []domain.Ingredient{}, Steps: []domain.RecipeStep{}},
+		"2": cake,
+		// Recipe 3 points at the illustration of recipe 2.
+		"3": {ID: "3", Name: "Brownies", Ingredients: []domain.Ingredient{}, Steps: []domain.RecipeStep{{
+			ID: "step1", RecipeIllustration: []domain.RecipeIllustration{{ID: "i1", Filepath: "/images/2/step1.jpg"}},
+		}}},
+	}}
+	sourceAccounts := newAccounts(t)
+	if err := sourceAccounts.SaveRecipeAccess(ctx, domain.RecipeAccess{RecipeID: "2", OwnerID: "alice", Visibility: domain.VisibilityPrivate}); err != nil {
+		t.Fatal(err)
+	}
+	sourceImages := &mockImageStore{files: map[string][]byte{"2/step1.jpg": []byte("jpeg")}}
+
+	var archive bytes.Buffer
+	stats, err := usecase.NewExportLibraryUseCase(source, sourceAccounts, sourceImages).Execute(ctx, admin, &archive)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Recipes != 3 || stats.Images != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// The target already has a different recipe 2.
+	target := &mockRepo{recipes: map[string]domain.Recipe{"2": {ID: "2", Name: "Carrot Cake"}}}
+	accounts := newAccounts(t)
+	images := &mockImageStore{}
+	importUC := usecase.NewImportLibraryUseCase(target, accounts, images, domain.NewRecipeValidator())
+	run := func(policy usecase.ConflictPolicy, dryRun bool) *usecase.ImportReport {
+		t.Helper()
+		report, err := importUC.Execute(ctx, admin, archive.Bytes(), usecase.ImportOptions{Conflict: policy, DryRun: dryRun, Author: "admin"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return report
+	}
+
+	report := run(usecase.ConflictRename, true)
+	if report.Created != 2 || report.Renamed != 1 || report.Recipes[1].NewID != "2-2" || len(target.recipes) != 1 || len(images.files) != 0 {
+		t.Errorf("expected a dry run to change nothing, got %+v", report)
+	}
+
+	report = run(usecase.ConflictSkip, false)
+	if report.Created != 2 || report.Skipped != 1 || target.recipes["2"].Name != "Carrot Cake" || target.recipes["1"].Name != "Pancakes" {
+		t.Errorf("expected recipe 2 to be skipped, got %+v", report)
+	}
+	// The illustration of recipe 3 is written to its own directory, leaving
+	// those of the skipped recipe 2 alone.
+	brownies := target.recipes["3"]
+	if brownies.Steps[0].RecipeIllustration[0].Filepath != "/images/3/step1.jpg" || len(report.Recipes[2].Warnings) != 1 {
+		t.Errorf("expected the illustration of recipe 3 to move to its directory, got %+v", report.Recipes[2])
+	}
+	if string(images.files["3/step1.jpg"]) != "jpeg" || images.files["2/step1.jpg"] != nil {
+		t.Errorf("expected only the directory of recipe 3 to be written, got %v", images.files)
+	}
+
+	// Recipe 1 was imported above, so it is renamed too.
+	report = run(usecase.ConflictRename, false)
+	renamed := target.recipes["2-2"]
+	if report.Renamed != 3 || target.recipes["1-2"].Name != "Pancakes" || renamed.Name != "Chocolate Cake" || renamed.Steps[0].RecipeIllustration[0].Filepath != "/images/2-2/step1.jpg" {
+		t.Errorf("expected recipe 2 to be imported as 2-2, got %+v, %+v", report, renamed)
+	}
+	if string(images.files["2-2/step1.jpg"]) != "jpeg" || images.files["2/step1.jpg"] != nil {
+		t.Errorf("expected the illustration to move with the renamed recipe, got %v", images.files)
+	}
+	if access, err := accounts.RecipeAccess(ctx, "2-2"); err != nil || access.OwnerID != "alice" || access.Visibility != domain.VisibilityPrivate {
+		t.Errorf("expected the access to be restored, got %+v, %v", access, err)
+	}
+
+	report = run(usecase.ConflictOverwrite, false)
+	if report.Overwritten != 3 || target.recipes["2"].Name != "Chocolate Cake" || len(target.revisions["2"]) != 1 {
+		t.Errorf("expected recipe 2 to be overwritten, got %+v", report)
+	}
+
+	if _, err := importUC.Execute(ctx, admin, []byte("not a zip"), usecase.ImportOptions{}); !errors.Is(err, usecase.ErrInvalidImport) {
+		t.Errorf("expected ErrInvalidImport, got %v", err)
+	}
+	if _, err := usecase.ParseConflictPolicy("merge"); !errors.Is(err, usecase.ErrInvalidImport) {
+		t.Errorf("expected unknown policies to be refused, got %v", err)
+	}
+}
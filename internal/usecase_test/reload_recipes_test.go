@@ -0,0 +1,28 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func TestReloadRecipes(t *testing.T) {
+	repo := &mockRepo{recipes: map[string]domain.Recipe{"1": {ID: "1"}}}
+	uc := usecase.NewReloadRecipesUseCase(repo)
+
+	report, err := uc.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Loaded != 1 {
+		t.Errorf("expected 1 loaded recipe in the report, got %d", report.Loaded)
+	}
+
+	repo.err = errors.New("broken file")
+	if _, err := uc.Execute(context.Background()); err == nil {
+		t.Error("expected the reload error to be returned")
+	}
+}
@@ -0,0 +1,48 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func TestResolveRecipeUseCase(t *testing.T) {
+	repo := &mockRepo{recipes: map[string]domain.Recipe{
+		"1": {ID: "1", Name: "Crêpes", Slug: "crepes", Aliases: []string{"pancakes"}},
+		"2": {ID: "2", Name: "Chocolate Cake", Slug: "1"},
+	}}
+	uc := usecase.NewResolveRecipeUseCase(repo)
+	ctx := context.Background()
+
+	tests := []struct {
+		key  string
+		want usecase.RecipeRef
+	}{
+		{"1", usecase.RecipeRef{ID: "1"}},
+		{"crepes", usecase.RecipeRef{ID: "1"}},
+		{"pancakes", usecase.RecipeRef{ID: "1", Redirect: "crepes"}},
+	}
+	for _, tt := range tests {
+		if ref, err := uc.Execute(ctx, tt.key); err != nil || ref != tt.want {
+			t.Errorf("Execute(%q) = %+v, %v, want %+v", tt.key, ref, err, tt.want)
+		}
+	}
+	if _, err := uc.Execute(ctx, "waffles"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSaveRecipeUseCase_NewID(t *testing.T) {
+	repo := &mockRepo{}
+	revision, err := usecase.NewSaveRecipeUseCase(repo, newAccounts(t)).Execute(context.Background(), domain.Viewer{}, domain.Recipe{Name: "Soup"}, "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id := revision.Recipe.ID; len(id) != 26 || repo.recipes[id].Name != "Soup" {
+		t.Errorf("expected the recipe to be stored under a new ULID, got %q", id)
+	}
+}
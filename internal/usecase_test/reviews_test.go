@@ -0,0 +1,114 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func TestReviewUseCases(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{recipes: map[string]domain.Recipe{"1": {ID: "1", Name: "Pancakes"}}}
+	accounts := newAccounts(t)
+	alice := domain.Viewer{UserID: "alice"}
+	bob := domain.Viewer{UserID: "bob"}
+
+	add := usecase.NewAddReviewUseCase(repo, accounts)
+	if _, err := add.Execute(ctx, alice, "2", domain.Review{Rating: 4}, "alice"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown recipe, got %v", err)
+	}
+	var validationErrs domain.ValidationErrors
+	if _, err := add.Execute(ctx, alice, "1", domain.Review{Rating: 9}, "alice"); !errors.As(err, &validationErrs) {
+		t.Errorf("expected validation errors, got %v", err)
+	}
+	review, err := add.Execute(ctx, alice, "1", domain.Review{Notes: " needed 5 more minutes ", CookedOn: "2024-05-07"}, "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if review.ID == "" || review.RecipeID != "1" || review.UserID != "alice" || review.Notes != "needed 5 more minutes" {
+		t.Errorf("unexpected review %+v", review)
+	}
+
+	update := usecase.NewUpdateReviewUseCase(accounts)
+	if _, err := update.Execute(ctx, bob, "1", review.ID, domain.Review{Rating: 1}); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected only the author to change a review, got %v", err)
+	}
+	updated, err := update.Execute(ctx, alice, "1", review.ID, domain.Review{Rating: 5, CookedOn: "2024-05-07"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Rating != 5 || updated.Author != "alice" || !updated.CreatedAt.Equal(review.CreatedAt) {
+		t.Errorf("unexpected review %+v", updated)
+	}
+
+	if err := usecase.NewDeleteReviewUseCase(accounts).Execute(ctx, bob, "1", review.ID); !errors.Is(err, usecase.ErrForbidden) {
+		t.Errorf("expected only the author to delete a review, got %v", err)
+	}
+	if err := usecase.NewDeleteReviewUseCase(accounts).Execute(ctx, domain.Viewer{Admin: true}, "1", review.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reviews, err := usecase.NewListReviewsUseCase(repo, accounts).Execute(ctx, "1")
+	if err != nil || len(reviews) != 0 {
+		t.Errorf("expected no reviews left, got %+v, %v", reviews, err)
+	}
+}
+
+func TestListRecipeSummariesUseCase_Execute(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepo{recipes: map[string]domain.Recipe{
+		"1": {ID: "1", Name: "Pancakes"},
+		"2": {ID: "2", Name: "Omelette"},
+		"3": {ID: "3", Name: "Bread"},
+	}}
+	accounts := newAccounts(t)
+	add := usecase.NewAddReviewUseCase(repo, accounts)
+	for _, r := range []struct {
+		recipeID string
+		review   domain.Review
+	}{
+		{"1", domain.Review{Rating: 3, CookedOn: "2024-06-01"}},
+		{"1", domain.Review{Rating: 4, CookedOn: "2024-05-01"}},
+		{"2", domain.Review{Rating: 5, CookedOn: "2024-04-01"}},
+	} {
+		if _, err := add.Execute(ctx, domain.Viewer{}, r.recipeID, r.review, "alice"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	uc := usecase.NewListRecipeSummariesUseCase(repo, accounts)
+	tests := []struct {
+		sortBy usecase.RecipeSort
+		want   []string
+	}{
+		{usecase.SortByName, []string{"3", "2", "1"}},
+		{usecase.SortByRating, []string{"2", "1", "3"}},
+		{usecase.SortByLastCooked, []string{"1", "2", "3"}},
+	}
+	for _, tt := range tests {
+		summaries, err := uc.Execute(ctx, domain.Viewer{}, "", tt.sortBy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := make([]string, len(summaries))
+		for i, summary := range summaries {
+			got[i] = summary.ID
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sort %q: expected %v, got %v", tt.sortBy, tt.want, got)
+		}
+		for _, summary := range summaries {
+			if summary.ID == "1" && (summary.AverageRating != 3.5 || summary.TimesCooked != 2 || summary.LastCooked != "2024-06-01") {
+				t.Errorf("unexpected stats of Pancakes: %+v", summary.RecipeStats)
+			}
+		}
+	}
+
+	if _, err := usecase.ParseRecipeSort("popularity"); !errors.Is(err, usecase.ErrInvalidSort) {
+		t.Errorf("expected ErrInvalidSort, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fromenjn/recipe-manager/internal/domain"
+	"github.com/fromenjn/recipe-manager/internal/repository"
+	"github.com/fromenjn/recipe-manager/internal/usecase"
+)
+
+func newRepoWithHistory(t *testing.T) *mockRepo {
+	t.Helper()
+	repo := &mockRepo{}
+	versions := []domain.Recipe{
+		{ID: "1", Name: "Pancakes", Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 200, Unit: "g"}}},
+		{ID: "1", Name: "Pancakes", Ingredients: []domain.Ingredient{{Name: "Flour", Quantity: 250, Unit: "g"}}},
+	}
+	for _, recipe := range versions {
+		if _, err := repo.Save(context.Background(), recipe, "alice"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return repo
+}
+
+func TestDiffRevisionsUseCase_Execute(t *testing.T) {
+	repo := newRepoWithHistory(t)
+	uc := usecase.NewDiffRevisionsUseCase(repo)
+
+	diff, err := uc.Execute(context.Background(), "1", 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff.FromRevision != 1 || diff.ToRevision != 2 {
+		t.Errorf("expected diff from 1 to 2, got %d to %d", diff.FromRevision, diff.ToRevision)
+	}
+	if len(diff.IngredientsChanged) != 1 || diff.IngredientsChanged[0].To.Quantity != 250 {
+		t.Errorf("expected Flour quantity change, got %+v", diff.IngredientsChanged)
+	}
+
+	if _, err := uc.Execute(context.Background(), "1", 1, 5); !errors.Is(err, repository.ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound, got %v", err)
+	}
+}
+
+func TestRevertRecipeUseCase_Execute(t *testing.T) {
+	repo := newRepoWithHistory(t)
+	uc := usecase.NewRevertRecipeUseCase(repo)
+
+	revision, err := uc.Execute(context.Background(), "1", 1, "bob")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Reverting appends a new revision instead of rewriting history.
+	if revision.Number != 3 {
+		t.Errorf("expected revision 3, got %d", revision.Number)
+	}
+	if revision.Author != "bob" {
+		t.Errorf("expected author 'bob', got '%s'", revision.Author)
+	}
+	if repo.recipes["1"].Ingredients[0].Quantity != 200 {
+		t.Errorf("expected Flour quantity 200 after revert, got %v", repo.recipes["1"].Ingredients[0].Quantity)
+	}
+}
+
+func TestRevertRecipeUseCase_Execute_NotFound(t *testing.T) {
+	uc := usecase.NewRevertRecipeUseCase(&mockRepo{})
+
+	if _, err := uc.Execute(context.Background(), "999", 1, "bob"); err == nil {
+		t.Error("expected error for missing recipe, got none")
+	}
+}
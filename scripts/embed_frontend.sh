@@ -0,0 +1,29 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Copies a built frontend (default ./dist) to internal/frontend/dist, where
+# `go build -tags embedfrontend` compiles it into the binary, along with
+# precompressed gzip and brotli variants of its text files.
+src="${1:-dist}"
+dest="internal/frontend/dist"
+
+if [ ! -f "$src/index.html" ]; then
+  echo "no built frontend in $src, run npm run build first" >&2
+  exit 1
+fi
+
+echo "==> Embedding frontend from $src..."
+rm -rf "$dest"
+cp -r "$src" "$dest"
+
+find "$dest" -type f \( -name '*.html' -o -name '*.js' -o -name '*.css' -o -name '*.svg' -o -name '*.json' -o -name '*.txt' \) |
+  while read -r file; do
+    gzip -9 -k -f "$file"
+    if command -v brotli >/dev/null; then
+      brotli -q 11 -k -f "$file"
+    fi
+  done
+
+if ! command -v brotli >/dev/null; then
+  echo "brotli not found, only gzip variants were written" >&2
+fi
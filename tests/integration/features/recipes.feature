@@ -18,4 +18,37 @@ Feature: List recipes
 
     When I send a GET request to "/ingredients"
     Then the response code should be 200
-    And the response should contain "Flour"
\ No newline at end of file
+    And the response should contain "Flour"
+
+  Scenario: Revalidating a cached recipe list
+    Given the server is running
+    When I send a GET request to "/recipes"
+    Then the response code should be 200
+    And the response header "ETag" should be set
+    And the response header "Last-Modified" should be set
+
+    When I send a conditional GET request to "/recipes" with the last ETag
+    Then the response code should be 304
+
+    When I send a GET request to "/recipe/2?ingredient=Flour&quantity=400"
+    Then the response code should be 200
+    And the response header "ETag" should be set
+
+    When I send a conditional GET request to "/recipe/2?ingredient=Flour&quantity=200" with the last ETag
+    Then the response code should be 200
+
+  Scenario: Probing health and readiness
+    Given the server is running
+    When I send a GET request to "/healthz"
+    Then the response code should be 200
+    When I send a GET request to "/readyz"
+    Then the response code should be 200
+    And the response should contain "ready"
+
+  Scenario: Scraping metrics
+    Given the server is running
+    When I send a GET request to "/recipes"
+    And I send a GET request to "/metrics"
+    Then the response code should be 200
+    And the response should contain "recipe_manager_http_requests_total"
+    And the response should contain "recipe_manager_recipes_loaded 2"
@@ -3,4 +3,4 @@ set -euo pipefail
 
 cd ../../ && \
 go build -o bin/recipe-manager ./cmd/recipe-manager && \
-./bin/recipe-manager
\ No newline at end of file
+exec ./bin/recipe-manager
\ No newline at end of file
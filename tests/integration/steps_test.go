@@ -51,6 +51,8 @@ func FeatureContext(s *godog.ScenarioContext) {
 	s.Step(`^the response code should be (\d+)$`, theResponseCodeShouldBe)
 	s.Step(`^the response should contain "([^"]*)"$`, theResponseShouldContain)
 	s.Step(`^the response should not contain "([^"]*)"$`, theResponseShouldNotContain)
+	s.Step(`^the response header "([^"]*)" should be set$`, theResponseHeaderShouldBeSet)
+	s.Step(`^I send a conditional GET request to "([^"]*)" with the last ETag$`, iSendAConditionalGETRequestTo)
 	s.Step(`^the server is running$`, theServerIsRunning)
 	s.Step(`^the server is running on port (\d+)$`, theServerIsRunningOnPort)
 }
@@ -87,6 +89,27 @@ func iSendAGETRequestTo(path string) error {
 	if err != nil {
 		return err
 	}
+	return recordResponse(resp)
+}
+
+func iSendAConditionalGETRequestTo(path string) error {
+	if lastResponse == nil {
+		return fmt.Errorf("no response recorded")
+	}
+	url := fmt.Sprintf("http://localhost:%d%s", port, path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("If-None-Match", lastResponse.Header.Get("ETag"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return recordResponse(resp)
+}
+
+func recordResponse(resp *http.Response) error {
 	lastResponse = resp
 
 	body, err := io.ReadAll(resp.Body)
@@ -108,6 +131,16 @@ func theResponseCodeShouldBe(code int) error {
 	return nil
 }
 
+func theResponseHeaderShouldBeSet(name string) error {
+	if lastResponse == nil {
+		return fmt.Errorf("no response recorded")
+	}
+	if lastResponse.Header.Get(name) == "" {
+		return fmt.Errorf("expected response header %q to be set", name)
+	}
+	return nil
+}
+
 func theResponseShouldContain(substring string) error {
 	if !bytes.Contains(lastResponseBody, []byte(substring)) {
 		return fmt.Errorf("expected body to contain %q but it did not.\nBody: %s",